@@ -0,0 +1,327 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Octavian-Anghel/Capstone-Project/adminapi"
+)
+
+// AdminConfig configures the local admin API used by the TUI.
+type AdminConfig struct {
+	// Socket is the Unix socket the API is served on. Anyone who can write
+	// to it can pause the daemon, so keep it in a directory only operators
+	// can reach. The API is disabled when empty.
+	Socket string `yaml:"socket"`
+}
+
+// recentFilesSize is the number of files the admin API reports.
+const recentFilesSize = 100
+
+// gate holds the workers while the daemon is paused.
+type gate struct {
+	mu   sync.Mutex
+	open chan struct{} // closed while the daemon runs
+}
+
+func newGate() *gate {
+	open := make(chan struct{})
+	close(open)
+	return &gate{open: open}
+}
+
+func (g *gate) Pause() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	select {
+	case <-g.open:
+		g.open = make(chan struct{})
+	default:
+	}
+}
+
+func (g *gate) Resume() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	select {
+	case <-g.open:
+	default:
+		close(g.open)
+	}
+}
+
+func (g *gate) Paused() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	select {
+	case <-g.open:
+		return false
+	default:
+		return true
+	}
+}
+
+// Wait blocks while the daemon is paused.
+func (g *gate) Wait() {
+	g.mu.Lock()
+	open := g.open
+	g.mu.Unlock()
+	<-open
+}
+
+// recentFiles keeps what became of the last files the daemon picked up,
+// one entry per path, most recently updated first.
+type recentFiles struct {
+	mu    sync.Mutex
+	size  int
+	files []adminapi.File
+}
+
+func newRecentFiles(size int) *recentFiles {
+	return &recentFiles{size: size}
+}
+
+// record updates the entry for f.Path, keeping its asset ID, transaction
+// and correlation ID when f does not set them.
+func (r *recentFiles) record(f adminapi.File) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if f.Time.IsZero() {
+		f.Time = time.Now().UTC()
+	}
+	for i, old := range r.files {
+		if old.Path != f.Path {
+			continue
+		}
+		if f.McapID == "" {
+			f.McapID = old.McapID
+		}
+		if f.TxID == "" {
+			f.TxID = old.TxID
+		}
+		if f.CID == "" {
+			f.CID = old.CID
+		}
+		r.files = append(r.files[:i], r.files[i+1:]...)
+		break
+	}
+	r.files = append([]adminapi.File{f}, r.files...)
+	if len(r.files) > r.size {
+		r.files = r.files[:r.size]
+	}
+}
+
+// list returns the recent files that keep reports true.
+func (r *recentFiles) list(keep func(adminapi.File) bool) []adminapi.File {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	files := make([]adminapi.File, 0, len(r.files))
+	for _, f := range r.files {
+		if keep(f) {
+			files = append(files, f)
+		}
+	}
+	return files
+}
+
+// listenAdmin opens the admin socket, replacing one left behind by a
+// daemon that did not shut down cleanly.
+func listenAdmin(cfg AdminConfig) (net.Listener, error) {
+	socket := filepath.Clean(cfg.Socket)
+	if err := os.MkdirAll(filepath.Dir(socket), 0o750); err != nil {
+		return nil, err
+	}
+	if err := os.Remove(socket); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	l, err := net.Listen("unix", socket)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(socket, 0o660); err != nil {
+		l.Close()
+		return nil, err
+	}
+	return l, nil
+}
+
+// newAdminServer returns a server for the endpoints described in package
+// adminapi.
+func newAdminServer(d *daemon) *http.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /status", func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, http.StatusOK, d.status())
+	})
+	mux.HandleFunc("GET /files", func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, http.StatusOK, d.recent.list(func(adminapi.File) bool { return true }))
+	})
+	mux.HandleFunc("GET /failures", func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, http.StatusOK, d.recent.list(adminapi.File.Failed))
+	})
+	mux.HandleFunc("POST /pause", func(w http.ResponseWriter, _ *http.Request) {
+		d.gate.Pause()
+		d.health.paused.Store(true)
+		slog.Info("paused by admin API")
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("POST /resume", func(w http.ResponseWriter, _ *http.Request) {
+		d.gate.Resume()
+		d.health.paused.Store(false)
+		slog.Info("resumed by admin API")
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("POST /reanchor", func(w http.ResponseWriter, r *http.Request) {
+		var req adminapi.ReanchorRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSON(w, http.StatusBadRequest, adminapi.ErrorResponse{Error: "invalid request: " + err.Error()})
+			return
+		}
+		if err := d.reanchor(req.Path); err != nil {
+			writeJSON(w, http.StatusBadRequest, adminapi.ErrorResponse{Error: err.Error()})
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	})
+	mux.HandleFunc("POST /reload", func(w http.ResponseWriter, _ *http.Request) {
+		result, err := d.reload()
+		if err != nil {
+			writeJSON(w, http.StatusUnprocessableEntity, adminapi.ErrorResponse{Error: err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, result)
+	})
+
+	return &http.Server{Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+}
+
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func (d *daemon) status() adminapi.Status {
+	cfg, _, _ := d.settings()
+	d.mu.RLock()
+	reloaded := d.reloaded
+	d.mu.RUnlock()
+
+	status := adminapi.Status{
+		Paused:     d.gate.Paused(),
+		Workers:    cfg.Workers,
+		Busy:       int(d.busy.Load()),
+		Queued:     len(d.queue),
+		QueueSize:  cap(d.queue),
+		Backlog:    d.backlog.Len(),
+		Journal:    d.journal.Len(),
+		WatchDir:   cfg.WatchDir,
+		ConfigPath: d.configPath,
+		Started:    d.started,
+		Reloaded:   reloaded,
+	}
+	for _, p := range d.health.peers() {
+		status.Peers = append(status.Peers, adminapi.Peer{Name: p.Name, State: p.State.String(), Active: p.Active})
+	}
+	return status
+}
+
+// reanchor queues a file in the watch directory again. Files still in the
+// journal are anchored from their capture rather than hashed again.
+func (d *daemon) reanchor(filePath string) error {
+	cfg, _, _ := d.settings()
+	filePath = filepath.Clean(filePath)
+	rel, err := filepath.Rel(cfg.WatchDir, filePath)
+	if err != nil || !filepath.IsAbs(filePath) || rel == ".." || strings.HasPrefix(rel, "../") {
+		return fmt.Errorf("%s is not in the watch directory %s", filePath, cfg.WatchDir)
+	}
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return err
+	}
+	if !info.Mode().IsRegular() {
+		return fmt.Errorf("%s is not a regular file", filePath)
+	}
+
+	cid := newCorrelationID()
+	logger := slog.With("cid", cid, "file", filePath)
+	logger.Info("re-anchoring recording requested by admin API")
+	// The queue may be full, so do not hold up the request.
+	go d.enqueue(logger, backlogEntry{Path: filePath, CID: cid, Detected: time.Now()})
+	return nil
+}
+
+// reload re-reads the config file. The hash settings, asset ID scheme,
+// identifier sources, sensor readings, receipts and log level are applied
+// at once; the other settings are only reported as changed, as they need a
+// restart.
+func (d *daemon) reload() (*adminapi.ReloadResult, error) {
+	if d.configPath == "" {
+		return nil, fmt.Errorf("the daemon was started without a config file")
+	}
+	cfg, err := loadConfig(d.configPath)
+	if err != nil {
+		return nil, err
+	}
+	ids, err := newAssetIDScheme(cfg.AssetID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid asset ID config: %w", err)
+	}
+	identifiers, err := newIdentifierResolver(cfg.Identifiers)
+	if err != nil {
+		return nil, fmt.Errorf("invalid identifiers config: %w", err)
+	}
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(cfg.Log.Level)); err != nil {
+		return nil, fmt.Errorf("invalid log level %q", cfg.Log.Level)
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	old := d.cfg
+	result := &adminapi.ReloadResult{Applied: []string{"hash", "assetId", "identifiers", "sensors", "receipts", "log.level"}}
+	for _, s := range []struct {
+		name     string
+		old, new any
+	}{
+		{"gateway", old.Gateway, cfg.Gateway},
+		{"watchDir", old.WatchDir, cfg.WatchDir},
+		{"log.format", old.Log.Format, cfg.Log.Format},
+		{"workers", old.Workers, cfg.Workers},
+		{"queueSize", old.QueueSize, cfg.QueueSize},
+		{"http", old.HTTP, cfg.HTTP},
+		{"health", old.Health, cfg.Health},
+		{"journal", old.Journal, cfg.Journal},
+		{"admin", old.Admin, cfg.Admin},
+		{"stateDir", old.StateDir, cfg.StateDir},
+	} {
+		if !reflect.DeepEqual(s.old, s.new) {
+			result.RestartRequired = append(result.RestartRequired, s.name)
+		}
+	}
+
+	d.cfg.Hash = cfg.Hash
+	d.cfg.AssetID = cfg.AssetID
+	d.cfg.Identifiers = cfg.Identifiers
+	d.cfg.Sensors = cfg.Sensors
+	d.cfg.Receipts = cfg.Receipts
+	d.cfg.Log.Level = cfg.Log.Level
+	d.ids = ids
+	d.identifiers = identifiers
+	d.reloaded = time.Now()
+	logLevel.Set(level)
+
+	slog.Info("reloaded config", "path", d.configPath, "restartRequired", result.RestartRequired)
+	return result, nil
+}
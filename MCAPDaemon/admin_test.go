@@ -0,0 +1,168 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Octavian-Anghel/Capstone-Project/adminapi"
+)
+
+// newTestAdmin serves the admin API of a daemon watching a temporary
+// directory and returns a client for it.
+func newTestAdmin(t *testing.T, configPath string) (*daemon, *adminapi.Client) {
+	t.Helper()
+	cfg := defaultConfig()
+	cfg.WatchDir = t.TempDir()
+	cfg.QueueSize = 4
+	cfg.Admin.Socket = filepath.Join(t.TempDir(), "admin.sock")
+
+	d := &daemon{
+		configPath:  configPath,
+		started:     time.Now(),
+		cfg:         cfg,
+		journal:     openTestJournal(t, filepath.Join(t.TempDir(), "journal.jsonl"), newTestSigner(t)),
+		metrics:     newMetrics(),
+		gate:        newGate(),
+		recent:      newRecentFiles(recentFilesSize),
+		identifiers: &identifierResolver{},
+	}
+	d.health = newTestHealth(t, cfg.Health)
+	d.backlog, d.queue = d.health.backlog, d.health.queue
+
+	l, err := listenAdmin(cfg.Admin)
+	if err != nil {
+		t.Skipf("Unix sockets unavailable: %v", err)
+	}
+	server := newAdminServer(d)
+	go server.Serve(l)
+	t.Cleanup(func() { server.Close() })
+	return d, adminapi.NewClient(cfg.Admin.Socket)
+}
+
+// Test status, pausing and resuming through the admin API
+func TestAdmin_Pause(t *testing.T) {
+	d, client := newTestAdmin(t, "")
+	ctx := context.Background()
+
+	if err := client.Pause(ctx); err != nil {
+		t.Fatal(err)
+	}
+	status, err := client.Status(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !status.Paused || status.QueueSize != 4 || len(status.Peers) != 2 || status.WatchDir != d.cfg.WatchDir {
+		t.Errorf("Unexpected status %+v", status)
+	}
+	report, _ := d.health.liveness()
+	if report.Checks["workers"] != "paused" {
+		t.Errorf("Expected paused workers to be reported, got %q", report.Checks["workers"])
+	}
+
+	released := make(chan struct{})
+	go func() {
+		d.gate.Wait()
+		close(released)
+	}()
+	select {
+	case <-released:
+		t.Fatal("Expected the gate to hold workers while paused")
+	case <-time.After(20 * time.Millisecond):
+	}
+	if err := client.Resume(ctx); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-released:
+	case <-time.After(time.Second):
+		t.Fatal("Expected resume to release workers")
+	}
+}
+
+// Test queueing a file again and the files it reports
+func TestAdmin_Reanchor(t *testing.T) {
+	d, client := newTestAdmin(t, "")
+	ctx := context.Background()
+
+	if err := client.Reanchor(ctx, "/etc/passwd"); err == nil || !strings.Contains(err.Error(), "not in the watch directory") {
+		t.Errorf("Expected files outside the watch directory to be refused, got %v", err)
+	}
+
+	filePath := filepath.Join(d.cfg.WatchDir, "run1.mcap")
+	if err := os.WriteFile(filePath, []byte("MCAP0\r\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Reanchor(ctx, filePath); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case j := <-d.queue:
+		if j.entry.Path != filePath {
+			t.Errorf("Expected %s to be queued, got %s", filePath, j.entry.Path)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected the file to be queued")
+	}
+
+	d.recent.record(adminapi.File{Path: filePath, Status: adminapi.FilePending, McapID: "abc", Error: "gateway unreachable"})
+	d.recent.record(adminapi.File{Path: "/shared/run2.mcap", Status: adminapi.FileAnchored, TxID: "tx2"})
+	files, err := client.Files(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 2 || files[0].Path != "/shared/run2.mcap" || files[1].CID == "" || files[1].McapID != "abc" {
+		t.Errorf("Unexpected files %+v", files)
+	}
+	failures, err := client.Failures(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(failures) != 1 || failures[0].Path != filePath {
+		t.Errorf("Expected only the pending file to be a failure, got %+v", failures)
+	}
+}
+
+// Test which settings a reload applies
+func TestAdmin_Reload(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "mcapdaemon.yaml")
+	d, client := newTestAdmin(t, configPath)
+	ctx := context.Background()
+
+	base := fmt.Sprintf("watchDir: %s\nqueueSize: 4\nadmin:\n  socket: %s\n", d.cfg.WatchDir, d.cfg.Admin.Socket)
+	config := base + "log:\n  level: debug\nassetId:\n  scheme: uuidv7\n"
+	if err := os.WriteFile(configPath, []byte(config), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := client.Reload(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.RestartRequired) != 0 || !slices.Contains(result.Applied, "assetId") {
+		t.Errorf("Unexpected reload result %+v", result)
+	}
+	if cfg, _, _ := d.settings(); cfg.AssetID.Scheme != schemeUUIDv7 || logLevel.Level() != slog.LevelDebug {
+		t.Errorf("Expected the asset ID scheme and log level to change, got %q %s", cfg.AssetID.Scheme, logLevel.Level())
+	}
+
+	if err := os.WriteFile(configPath, []byte(base+"workers: 8\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if result, err = client.Reload(ctx); err != nil || !slices.Equal(result.RestartRequired, []string{"workers"}) {
+		t.Errorf("Expected workers to need a restart, got %+v, %v", result, err)
+	}
+
+	if err := os.WriteFile(configPath, []byte(base+"workers: 0\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Reload(ctx); err == nil || !strings.Contains(err.Error(), "422") {
+		t.Errorf("Expected an invalid config to be refused, got %v", err)
+	}
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/Octavian-Anghel/Capstone-Project/ledger"
+)
+
+// Asset ID schemes understood by AssetIDConfig.Scheme.
+const (
+	schemeContent  = "content"
+	schemeHostPath = "hostpath"
+	schemeUUIDv7   = "uuidv7"
+	schemeTemplate = "template"
+)
+
+// recording describes a detected MCAP file as it moves through the daemon.
+type recording struct {
+	Path      string // path as reported by the watcher
+	RelPath   string // path relative to the watch directory, slash separated
+	Hash      string // digest anchored on the ledger
+	Operation string
+	Project   string
+
+	// Metadata holds the file's MCAP Metadata records by name.
+	Metadata map[string]map[string]string
+}
+
+// newRecording builds a recording for a file found under watchDir.
+func newRecording(watchDir string, filePath string) recording {
+	rel, err := filepath.Rel(watchDir, filePath)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		rel = filepath.Base(filePath)
+	}
+	return recording{Path: filePath, RelPath: filepath.ToSlash(rel)}
+}
+
+// assetIDData is the value passed to the template scheme.
+type assetIDData struct {
+	Host     string
+	Path     string
+	RelPath  string
+	Base     string // file name without the .mcap extension
+	Hash     string
+	Metadata map[string]map[string]string // MCAP Metadata records by name
+}
+
+// assetIDScheme derives the ledger key for a recording.
+type assetIDScheme struct {
+	cfg  AssetIDConfig
+	host string
+	tmpl *template.Template
+}
+
+func newAssetIDScheme(cfg AssetIDConfig) (*assetIDScheme, error) {
+	s := &assetIDScheme{cfg: cfg, host: cfg.Host}
+	if s.host == "" {
+		host, err := os.Hostname()
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve hostname: %w", err)
+		}
+		s.host = host
+	}
+
+	switch cfg.Scheme {
+	case schemeContent, schemeHostPath, schemeUUIDv7:
+	case schemeTemplate:
+		if cfg.Template == "" {
+			return nil, fmt.Errorf("asset ID scheme %q requires a template", cfg.Scheme)
+		}
+		tmpl, err := template.New("assetId").Option("missingkey=error").Parse(cfg.Template)
+		if err != nil {
+			return nil, fmt.Errorf("invalid asset ID template: %w", err)
+		}
+		s.tmpl = tmpl
+	default:
+		return nil, fmt.Errorf("unknown asset ID scheme %q", cfg.Scheme)
+	}
+	return s, nil
+}
+
+// AssetID returns the ledger key for rec. The content scheme requires
+// rec.Hash to be set.
+func (s *assetIDScheme) AssetID(rec recording) (string, error) {
+	var id string
+	switch s.cfg.Scheme {
+	case schemeContent:
+		if rec.Hash == "" {
+			return "", fmt.Errorf("content asset ID requires a hash for %s", rec.Path)
+		}
+		id = ledger.ContentID(rec.Hash)
+	case schemeHostPath:
+		id = s.host + ":" + rec.RelPath
+	case schemeUUIDv7:
+		uuid, err := newUUIDv7()
+		if err != nil {
+			return "", err
+		}
+		id = uuid
+	case schemeTemplate:
+		var sb strings.Builder
+		err := s.tmpl.Execute(&sb, assetIDData{
+			Host:     s.host,
+			Path:     rec.Path,
+			RelPath:  rec.RelPath,
+			Base:     strings.TrimSuffix(filepath.Base(rec.Path), ".mcap"),
+			Hash:     rec.Hash,
+			Metadata: rec.Metadata,
+		})
+		if err != nil {
+			return "", fmt.Errorf("failed to render asset ID for %s: %w", rec.Path, err)
+		}
+		id = strings.TrimSpace(sb.String())
+		if id == "" {
+			return "", fmt.Errorf("asset ID template rendered an empty ID for %s", rec.Path)
+		}
+	}
+	return s.cfg.Prefix + id, nil
+}
+
+// newUUIDv7 returns a time-ordered RFC 9562 version 7 UUID.
+func newUUIDv7() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[6:]); err != nil {
+		return "", fmt.Errorf("failed to generate UUID: %w", err)
+	}
+	var ms [8]byte
+	binary.BigEndian.PutUint64(ms[:], uint64(time.Now().UnixMilli()))
+	copy(b[:6], ms[2:])
+	b[6] = (b[6] & 0x0f) | 0x70
+	b[8] = (b[8] & 0x3f) | 0x80
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:]), nil
+}
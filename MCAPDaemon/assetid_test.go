@@ -0,0 +1,74 @@
+package main
+
+import (
+	"regexp"
+	"testing"
+)
+
+// Test that the content scheme is independent of where the file lives
+func TestAssetID_Content(t *testing.T) {
+	ids, err := newAssetIDScheme(AssetIDConfig{Scheme: schemeContent, Prefix: "mcap-"})
+	if err != nil {
+		t.Fatalf("Failed to create scheme: %v", err)
+	}
+
+	a := newRecording("/shared", "/shared/run1.mcap")
+	a.Hash = "abc"
+	b := newRecording("/mnt/archive", "/mnt/archive/2025/renamed.mcap")
+	b.Hash = "abc"
+
+	idA, err := ids.AssetID(a)
+	if err != nil {
+		t.Fatalf("Error deriving asset ID: %v", err)
+	}
+	idB, _ := ids.AssetID(b)
+	if idA != idB {
+		t.Errorf("Expected identical IDs for identical content, got %s and %s", idA, idB)
+	}
+	if want := "mcap-ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"; idA != want {
+		t.Errorf("Expected %s, got %s", want, idA)
+	}
+}
+
+// Test host plus relative path IDs
+func TestAssetID_HostPath(t *testing.T) {
+	ids, err := newAssetIDScheme(AssetIDConfig{Scheme: schemeHostPath, Host: "recorder-01"})
+	if err != nil {
+		t.Fatalf("Failed to create scheme: %v", err)
+	}
+
+	id, err := ids.AssetID(newRecording("/shared", "/shared/cell7/run1.mcap"))
+	if err != nil {
+		t.Fatalf("Error deriving asset ID: %v", err)
+	}
+	if id != "recorder-01:cell7/run1.mcap" {
+		t.Errorf("Unexpected asset ID %s", id)
+	}
+}
+
+// Test UUIDv7 formatting
+func TestAssetID_UUIDv7(t *testing.T) {
+	ids, err := newAssetIDScheme(AssetIDConfig{Scheme: schemeUUIDv7, Host: "h"})
+	if err != nil {
+		t.Fatalf("Failed to create scheme: %v", err)
+	}
+
+	id, err := ids.AssetID(newRecording("/shared", "/shared/run1.mcap"))
+	if err != nil {
+		t.Fatalf("Error deriving asset ID: %v", err)
+	}
+	pattern := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-7[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	if !pattern.MatchString(id) {
+		t.Errorf("Not a version 7 UUID: %s", id)
+	}
+}
+
+// Test rejection of unknown schemes and empty templates
+func TestAssetID_InvalidConfig(t *testing.T) {
+	if _, err := newAssetIDScheme(AssetIDConfig{Scheme: "path", Host: "h"}); err == nil {
+		t.Errorf("Expected error for unknown scheme")
+	}
+	if _, err := newAssetIDScheme(AssetIDConfig{Scheme: schemeTemplate, Host: "h"}); err == nil {
+		t.Errorf("Expected error for missing template")
+	}
+}
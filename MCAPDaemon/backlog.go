@@ -0,0 +1,115 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// backlogEntry is a recording that was detected but not yet anchored.
+type backlogEntry struct {
+	Path     string    `json:"path"`
+	CID      string    `json:"cid"`
+	Detected time.Time `json:"detected"`
+}
+
+// backlog is the durable set of recordings still waiting to be anchored.
+// It is rewritten on every change so a restarted daemon picks up where the
+// previous one stopped.
+type backlog struct {
+	mu      sync.Mutex
+	path    string
+	entries map[string]backlogEntry
+}
+
+// openBacklog loads the backlog stored at backlogPath, creating an empty one
+// if the file does not exist yet.
+func openBacklog(backlogPath string) (*backlog, error) {
+	b := &backlog{path: filepath.Clean(backlogPath), entries: make(map[string]backlogEntry)}
+
+	data, err := os.ReadFile(b.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return b, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read backlog: %w", err)
+	}
+
+	var entries []backlogEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("failed to parse backlog %s: %w", b.path, err)
+	}
+	for _, e := range entries {
+		b.entries[e.Path] = e
+	}
+	return b, nil
+}
+
+// Add records entry, replacing any earlier entry for the same path.
+func (b *backlog) Add(entry backlogEntry) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.entries[entry.Path] = entry
+	return b.save()
+}
+
+// Remove drops the entry for filePath once it no longer needs anchoring.
+func (b *backlog) Remove(filePath string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, ok := b.entries[filePath]; !ok {
+		return nil
+	}
+	delete(b.entries, filePath)
+	return b.save()
+}
+
+// Len returns the number of recordings waiting to be anchored.
+func (b *backlog) Len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.entries)
+}
+
+// Entries returns the waiting recordings, oldest first.
+func (b *backlog) Entries() []backlogEntry {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.sorted()
+}
+
+func (b *backlog) sorted() []backlogEntry {
+	entries := make([]backlogEntry, 0, len(b.entries))
+	for _, e := range b.entries {
+		entries = append(entries, e)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Detected.Before(entries[j].Detected)
+	})
+	return entries
+}
+
+// save writes the backlog to a temporary file and renames it into place so
+// a crash never leaves a truncated backlog behind. b.mu must be held.
+func (b *backlog) save() error {
+	data, err := json.MarshalIndent(b.sorted(), "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp := b.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write backlog: %w", err)
+	}
+	if err := os.Rename(tmp, b.path); err != nil {
+		return fmt.Errorf("failed to replace backlog: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// Test that the backlog survives being reopened
+func TestBacklog_Persistence(t *testing.T) {
+	backlogPath := filepath.Join(t.TempDir(), "backlog.json")
+
+	b, err := openBacklog(backlogPath)
+	if err != nil {
+		t.Fatalf("Failed to open backlog: %v", err)
+	}
+	now := time.Now()
+	if err := b.Add(backlogEntry{Path: "/shared/b.mcap", CID: "2", Detected: now}); err != nil {
+		t.Fatalf("Failed to add entry: %v", err)
+	}
+	if err := b.Add(backlogEntry{Path: "/shared/a.mcap", CID: "1", Detected: now.Add(-time.Minute)}); err != nil {
+		t.Fatalf("Failed to add entry: %v", err)
+	}
+	if err := b.Add(backlogEntry{Path: "/shared/c.mcap", CID: "3", Detected: now}); err != nil {
+		t.Fatalf("Failed to add entry: %v", err)
+	}
+	if err := b.Remove("/shared/c.mcap"); err != nil {
+		t.Fatalf("Failed to remove entry: %v", err)
+	}
+
+	reopened, err := openBacklog(backlogPath)
+	if err != nil {
+		t.Fatalf("Failed to reopen backlog: %v", err)
+	}
+	entries := reopened.Entries()
+	if len(entries) != 2 {
+		t.Fatalf("Expected 2 entries, got %d", len(entries))
+	}
+	if entries[0].Path != "/shared/a.mcap" || entries[0].CID != "1" {
+		t.Errorf("Expected oldest entry first, got %+v", entries[0])
+	}
+}
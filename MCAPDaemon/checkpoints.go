@@ -0,0 +1,298 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Octavian-Anghel/Capstone-Project/hashlib"
+)
+
+// checkpointHead is how much of the start of a recording its checkpoint
+// keeps a digest of, to tell it from another recording later written to
+// the same path.
+const checkpointHead = 4096
+
+// hashCheckpoint is the saved hash state of a recording still being
+// written.
+type hashCheckpoint struct {
+	Path string `json:"path"`
+	hashlib.Checkpoint
+	Saved time.Time `json:"saved"`
+
+	// Head is the SHA-256 digest of the first checkpointHead bytes hashed.
+	Head string `json:"head"`
+}
+
+// growingFile serialises the hashing of one recording.
+type growingFile struct {
+	mu   sync.Mutex
+	info os.FileInfo // last seen, guarded by mu
+
+	// Guarded by checkpoints.mu.
+	next      time.Time
+	holders   int
+	forgotten bool
+}
+
+// checkpoints hashes recordings while they are still being written and
+// keeps their hash state on disk, so finishing a recording only reads the
+// bytes written since its last checkpoint, even after a restart. MCAP
+// writers only ever append, which is what makes this safe.
+type checkpoints struct {
+	mu      sync.Mutex
+	path    string
+	entries map[string]hashCheckpoint
+	files   map[string]*growingFile
+}
+
+// openCheckpoints loads the checkpoints stored at checkpointsPath,
+// forgetting those of recordings that no longer exist.
+func openCheckpoints(checkpointsPath string) (*checkpoints, error) {
+	c := &checkpoints{
+		path:    filepath.Clean(checkpointsPath),
+		entries: make(map[string]hashCheckpoint),
+		files:   make(map[string]*growingFile),
+	}
+
+	data, err := os.ReadFile(c.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read checkpoints: %w", err)
+	}
+
+	var entries []hashCheckpoint
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("failed to parse checkpoints %s: %w", c.path, err)
+	}
+	for _, e := range entries {
+		if _, err := os.Stat(e.Path); err == nil {
+			c.entries[e.Path] = e
+		}
+	}
+	if len(c.entries) != len(entries) {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		if err := c.save(); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// schedule reports whether filePath is due to be hashed, which it is at
+// most once per interval.
+func (c *checkpoints) schedule(filePath string, interval time.Duration) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	g := c.file(filePath)
+	now := time.Now()
+	if now.Before(g.next) {
+		return false
+	}
+	g.next = now.Add(interval)
+	return true
+}
+
+// file returns the lock of filePath. c.mu must be held.
+func (c *checkpoints) file(filePath string) *growingFile {
+	g, ok := c.files[filePath]
+	if !ok {
+		g = &growingFile{}
+		c.files[filePath] = g
+	}
+	return g
+}
+
+// lock holds the lock of filePath until the returned func is called. The
+// lock outlives a forget until its last holder lets go, so a recording is
+// never hashed twice at once.
+func (c *checkpoints) lock(filePath string) (*growingFile, func()) {
+	c.mu.Lock()
+	g := c.file(filePath)
+	g.holders++
+	c.mu.Unlock()
+
+	g.mu.Lock()
+	return g, func() {
+		g.mu.Unlock()
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		g.holders--
+		if g.holders == 0 && g.forgotten {
+			delete(c.files, filePath)
+		}
+	}
+}
+
+// resume returns the incremental hash of the recording open as file under
+// algorithm h, continued from its checkpoint when there is a usable one.
+// Checkpoints are only usable for the same file they were saved for. g
+// must be locked and c.mu must not be held.
+func (c *checkpoints) resume(h hashlib.Hasher, g *growingFile, filePath string, file *os.File, info os.FileInfo) (*hashlib.Incremental, error) {
+	c.mu.Lock()
+	e, ok := c.entries[filePath]
+	c.mu.Unlock()
+
+	// A replaced recording differs from the file last seen in this run by
+	// its inode, and after a restart from the checkpoint by its head.
+	same := g.info == nil || os.SameFile(g.info, info)
+	g.info = info
+	if ok && same && e.Algorithm == h.ID() && e.Offset <= info.Size() {
+		if head, err := headDigest(file, e.Offset); err == nil && head == e.Head {
+			if inc, err := hashlib.Resume(e.Checkpoint); err == nil {
+				return inc, nil
+			}
+		}
+	}
+	return hashlib.NewIncremental(h)
+}
+
+// headDigest returns the SHA-256 digest of the first size bytes of r, up
+// to checkpointHead.
+func headDigest(r io.ReaderAt, size int64) (string, error) {
+	return hashlib.HashReader(context.Background(), io.NewSectionReader(r, 0, min(size, checkpointHead)), hashlib.Options{})
+}
+
+// advance hashes the bytes written to filePath since its last checkpoint
+// and saves a new one. Recordings that are already complete are left to
+// the workers. It returns the number of bytes hashed.
+func (c *checkpoints) advance(ctx context.Context, h hashlib.Hasher, filePath string) (int64, error) {
+	g, unlock := c.lock(filePath)
+	defer unlock()
+
+	if magic, err := getMagicBytes(filePath); err == nil && magic == "MCAP0\r\n" {
+		return 0, nil
+	}
+	file, err := os.Open(filepath.Clean(filePath))
+	if err != nil {
+		return 0, fmt.Errorf("failed to open recording: %w", err)
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return 0, fmt.Errorf("failed to stat recording: %w", err)
+	}
+
+	inc, err := c.resume(h, g, filePath, file, info)
+	if err != nil {
+		return 0, err
+	}
+	start := inc.Offset()
+	if err := inc.Update(ctx, file, info.Size(), nil); err != nil {
+		return inc.Offset() - start, err
+	}
+	checkpoint, err := inc.Checkpoint()
+	if err != nil {
+		return inc.Offset() - start, err
+	}
+	head, err := headDigest(file, checkpoint.Offset)
+	if err != nil {
+		return inc.Offset() - start, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if g.forgotten {
+		// The recording was anchored while this was hashing it.
+		return inc.Offset() - start, nil
+	}
+	c.entries[filePath] = hashCheckpoint{Path: filePath, Checkpoint: checkpoint, Saved: time.Now().UTC(), Head: head}
+	return inc.Offset() - start, c.save()
+}
+
+// finish returns the digest of the complete recording at filePath under
+// algorithm h, resuming from its checkpoint when there is a usable one,
+// and the number of bytes it had to read. The checkpoint is kept until
+// the caller forgets it.
+func (c *checkpoints) finish(ctx context.Context, h hashlib.Hasher, filePath string) (string, int64, error) {
+	g, unlock := c.lock(filePath)
+	defer unlock()
+
+	file, err := os.Open(filepath.Clean(filePath))
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to open recording: %w", err)
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to stat recording: %w", err)
+	}
+
+	// Checkpoints under another algorithm, or of a file that was since
+	// replaced, are ignored and the file is hashed from the start.
+	inc, err := c.resume(h, g, filePath, file, info)
+	if errors.Is(err, hashlib.ErrNotResumable) {
+		digest, err := hashlib.HashReader(ctx, file, hashlib.Options{Hasher: h})
+		return digest, info.Size(), err
+	}
+	if err != nil {
+		return "", 0, err
+	}
+	start := inc.Offset()
+	if err := inc.Update(ctx, file, info.Size(), nil); err != nil {
+		return "", inc.Offset() - start, err
+	}
+	return inc.Sum(), inc.Offset() - start, nil
+}
+
+// forget drops the checkpoint of filePath.
+func (c *checkpoints) forget(filePath string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if g, ok := c.files[filePath]; ok {
+		if g.holders == 0 {
+			delete(c.files, filePath)
+		} else {
+			g.forgotten = true
+		}
+	}
+	if _, ok := c.entries[filePath]; !ok {
+		return nil
+	}
+	delete(c.entries, filePath)
+	return c.save()
+}
+
+// Len returns the number of recordings with a checkpoint.
+func (c *checkpoints) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.entries)
+}
+
+// save writes the checkpoints to a temporary file and renames it into
+// place, like the backlog. c.mu must be held.
+func (c *checkpoints) save() error {
+	entries := make([]hashCheckpoint, 0, len(c.entries))
+	for _, e := range c.entries {
+		entries = append(entries, e)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Path < entries[j].Path
+	})
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp := c.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write checkpoints: %w", err)
+	}
+	if err := os.Rename(tmp, c.path); err != nil {
+		return fmt.Errorf("failed to replace checkpoints: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Octavian-Anghel/Capstone-Project/hashlib"
+	"github.com/Octavian-Anghel/Capstone-Project/mcap"
+)
+
+// Test hashing a recording as it grows, across a restart
+func TestCheckpoints_Growing(t *testing.T) {
+	dir := t.TempDir()
+	statePath := filepath.Join(dir, "checkpoints.json")
+	recording := filepath.Join(dir, "run1.mcap")
+	h, _ := hashlib.Lookup(hashlib.SHA256)
+
+	head := append(bytes.Clone(mcap.Magic), bytes.Repeat([]byte("record"), 1000)...)
+	if err := os.WriteFile(recording, head, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	c, err := openCheckpoints(statePath)
+	if err != nil {
+		t.Fatalf("Failed to open checkpoints: %v", err)
+	}
+	if hashed, err := c.advance(context.Background(), h, recording); err != nil || hashed != int64(len(head)) {
+		t.Fatalf("Expected %d bytes hashed, got %d, %v", len(head), hashed, err)
+	}
+
+	tail := append(bytes.Repeat([]byte("more"), 100), mcap.Magic...)
+	file, err := os.OpenFile(recording, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.Write(tail)
+	file.Close()
+
+	// A restarted daemon only reads what was written since the checkpoint
+	c, err = openCheckpoints(statePath)
+	if err != nil || c.Len() != 1 {
+		t.Fatalf("Expected the checkpoint to survive a restart, got %d, %v", c.Len(), err)
+	}
+	if hashed, err := c.advance(context.Background(), h, recording); err != nil || hashed != 0 {
+		t.Errorf("Expected a complete recording to be left to the workers, got %d, %v", hashed, err)
+	}
+	digest, hashed, err := c.finish(context.Background(), h, recording)
+	if err != nil {
+		t.Fatalf("Failed to finish: %v", err)
+	}
+	sum := sha256.Sum256(append(head, tail...))
+	if digest != hex.EncodeToString(sum[:]) {
+		t.Errorf("Expected digest %x, got %s", sum, digest)
+	}
+	if hashed != int64(len(tail)) {
+		t.Errorf("Expected only the %d new bytes to be read, got %d", len(tail), hashed)
+	}
+
+	if err := c.forget(recording); err != nil || c.Len() != 0 {
+		t.Errorf("Expected the checkpoint to be forgotten, got %d, %v", c.Len(), err)
+	}
+}
+
+// Test that checkpoints that cannot be resumed are ignored
+func TestCheckpoints_Fallback(t *testing.T) {
+	dir := t.TempDir()
+	statePath := filepath.Join(dir, "checkpoints.json")
+	recording := filepath.Join(dir, "run1.mcap")
+	sha, _ := hashlib.Lookup(hashlib.SHA256)
+	blake3, _ := hashlib.Lookup(hashlib.BLAKE3)
+
+	if err := os.WriteFile(recording, bytes.Repeat([]byte("record"), 1000), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	c, err := openCheckpoints(statePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.advance(context.Background(), sha, recording); err != nil {
+		t.Fatal(err)
+	}
+
+	// The algorithm changed since the checkpoint
+	content := []byte("abc")
+	if err := os.WriteFile(recording, content, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	digest, hashed, err := c.finish(context.Background(), blake3, recording)
+	if err != nil || hashed != 3 || digest != "6437b3ac38465133ffb63b75273a8db548c558465d79db03fd359c6cd5bd9d85" {
+		t.Errorf("Expected the file to be hashed whole with BLAKE3, got %s, %d, %v", digest, hashed, err)
+	}
+
+	// The file was replaced with a shorter one
+	digest, hashed, err = c.finish(context.Background(), sha, recording)
+	sum := sha256.Sum256(content)
+	if err != nil || hashed != 3 || digest != hex.EncodeToString(sum[:]) {
+		t.Errorf("Expected the file to be hashed whole with SHA-256, got %s, %d, %v", digest, hashed, err)
+	}
+
+	// Checkpoints of deleted recordings are dropped on open
+	os.Remove(recording)
+	if c, err = openCheckpoints(statePath); err != nil || c.Len() != 0 {
+		t.Errorf("Expected no checkpoints, got %d, %v", c.Len(), err)
+	}
+}
+
+// Test that checkpoints of a recording are not used for another one
+// written to the same path
+func TestCheckpoints_Replaced(t *testing.T) {
+	dir := t.TempDir()
+	statePath := filepath.Join(dir, "checkpoints.json")
+	recording := filepath.Join(dir, "run1.mcap")
+	h, _ := hashlib.Lookup(hashlib.SHA256)
+
+	head := bytes.Repeat([]byte("header"), 1000)
+	if err := os.WriteFile(recording, append(bytes.Clone(head), "first"...), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	c, err := openCheckpoints(statePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.advance(context.Background(), h, recording); err != nil {
+		t.Fatal(err)
+	}
+
+	// Same head, same size, different file
+	replace := func(content []byte) {
+		t.Helper()
+		tmp := recording + ".tmp"
+		if err := os.WriteFile(tmp, content, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Rename(tmp, recording); err != nil {
+			t.Fatal(err)
+		}
+	}
+	second := append(bytes.Clone(head), "other"...)
+	replace(second)
+	digest, hashed, err := c.finish(context.Background(), h, recording)
+	sum := sha256.Sum256(second)
+	if err != nil || hashed != int64(len(second)) || digest != hex.EncodeToString(sum[:]) {
+		t.Errorf("Expected the replaced file to be hashed whole, got %s, %d, %v", digest, hashed, err)
+	}
+
+	// After a restart only the head tells the files apart
+	if _, err := c.advance(context.Background(), h, recording); err != nil {
+		t.Fatal(err)
+	}
+	third := append([]byte("HEADER"), second[6:]...)
+	replace(third)
+	if c, err = openCheckpoints(statePath); err != nil {
+		t.Fatal(err)
+	}
+	digest, hashed, err = c.finish(context.Background(), h, recording)
+	sum = sha256.Sum256(third)
+	if err != nil || hashed != int64(len(third)) || digest != hex.EncodeToString(sum[:]) {
+		t.Errorf("Expected the file to be hashed whole after a restart, got %s, %d, %v", digest, hashed, err)
+	}
+}
+
+// Test that forgetting a recording while it is being hashed keeps its
+// lock until the hashing is done
+func TestCheckpoints_ForgetLocked(t *testing.T) {
+	c, err := openCheckpoints(filepath.Join(t.TempDir(), "checkpoints.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, unlock := c.lock("/shared/a.mcap")
+	if err := c.forget("/shared/a.mcap"); err != nil {
+		t.Fatal(err)
+	}
+
+	locked := make(chan *growingFile)
+	go func() {
+		g, unlock := c.lock("/shared/a.mcap")
+		unlock()
+		locked <- g
+	}()
+	select {
+	case <-locked:
+		t.Fatal("Expected the recording to stay locked after forget")
+	case <-time.After(50 * time.Millisecond):
+	}
+	unlock()
+	if other := <-locked; other != g {
+		t.Errorf("Expected both holders to share one lock")
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.files) != 0 {
+		t.Errorf("Expected the lock to be dropped once released, got %d", len(c.files))
+	}
+}
+
+// Test that growing recordings are hashed at most once per interval
+func TestCheckpoints_Schedule(t *testing.T) {
+	c, err := openCheckpoints(filepath.Join(t.TempDir(), "checkpoints.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !c.schedule("/shared/a.mcap", time.Hour) {
+		t.Errorf("Expected the first write to be hashed")
+	}
+	if c.schedule("/shared/a.mcap", time.Hour) {
+		t.Errorf("Expected a second write within the interval to wait")
+	}
+	if !c.schedule("/shared/b.mcap", time.Hour) {
+		t.Errorf("Expected other recordings to be hashed")
+	}
+}
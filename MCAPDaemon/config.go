@@ -0,0 +1,158 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/Octavian-Anghel/Capstone-Project/hashlib"
+	"github.com/Octavian-Anghel/Capstone-Project/ledger"
+	"gopkg.in/yaml.v3"
+)
+
+// Config holds the daemon settings that can be changed without a rebuild.
+type Config struct {
+	// Gateway describes the Fabric network recordings are anchored on.
+	Gateway ledger.Config `yaml:"gateway"`
+
+	// WatchDir is the directory watched for new recordings.
+	WatchDir string `yaml:"watchDir"`
+
+	// Hash selects the digest algorithm recordings are anchored with.
+	Hash HashConfig `yaml:"hash"`
+
+	// AssetID selects how ledger keys are derived for each recording.
+	AssetID AssetIDConfig `yaml:"assetId"`
+
+	// Identifiers resolves the operation ID and project of each recording.
+	Identifiers IdentifiersConfig `yaml:"identifiers"`
+
+	// Sensors selects the sensor readings summarised for each recording.
+	Sensors SensorsConfig `yaml:"sensors"`
+
+	Log LogConfig `yaml:"log"`
+
+	// Workers is the number of files hashed and anchored concurrently.
+	Workers int `yaml:"workers"`
+
+	// QueueSize bounds the number of validated files waiting for a worker.
+	QueueSize int `yaml:"queueSize"`
+
+	// HTTP serves /metrics, /healthz and /readyz when HTTP.Listen is set.
+	HTTP HTTPConfig `yaml:"http"`
+
+	Health HealthConfig `yaml:"health"`
+
+	// Receipts controls where a receipt is written for each anchored file.
+	Receipts ReceiptsConfig `yaml:"receipts"`
+
+	// Journal holds recordings captured while the gateway was unreachable.
+	Journal JournalConfig `yaml:"journal"`
+
+	// Admin serves the local admin API when Admin.Socket is set.
+	Admin AdminConfig `yaml:"admin"`
+
+	// StateDir holds files that must survive a restart, such as the backlog
+	// of recordings not yet anchored, the journal and hash checkpoints.
+	StateDir string `yaml:"stateDir"`
+}
+
+// AssetIDConfig configures the asset ID scheme.
+type AssetIDConfig struct {
+	// Scheme is one of "content", "hostpath", "uuidv7" or "template".
+	Scheme string `yaml:"scheme"`
+
+	// Prefix is prepended to every generated ID.
+	Prefix string `yaml:"prefix"`
+
+	// Host overrides the hostname used by the hostpath and template schemes.
+	Host string `yaml:"host"`
+
+	// Template is a text/template used by the template scheme. See assetIDData
+	// for the available fields.
+	Template string `yaml:"template"`
+}
+
+// HashConfig configures how recordings are hashed.
+type HashConfig struct {
+	// Algorithm is a hashlib algorithm identifier such as "sha256" or
+	// "blake3".
+	Algorithm string `yaml:"algorithm"`
+
+	// CheckpointInterval is how often a recording still being written is
+	// hashed up to its current end, with the hash state saved under
+	// StateDir. Zero hashes recordings only once they are complete. The
+	// algorithm must be able to save its state, which blake3 cannot.
+	CheckpointInterval time.Duration `yaml:"checkpointInterval"`
+}
+
+func (cfg HashConfig) validate() error {
+	h, err := hashlib.Lookup(cfg.Algorithm)
+	if err != nil {
+		return err
+	}
+	if cfg.CheckpointInterval < 0 {
+		return fmt.Errorf("checkpointInterval must not be negative, got %s", cfg.CheckpointInterval)
+	}
+	if cfg.CheckpointInterval > 0 {
+		if _, err := hashlib.NewIncremental(h); err != nil {
+			return fmt.Errorf("checkpointInterval needs an algorithm that can be resumed: %w", err)
+		}
+	}
+	return nil
+}
+
+func defaultConfig() Config {
+	return Config{
+		Gateway:  ledger.DefaultConfig(),
+		WatchDir: "/shared",
+		Hash:     HashConfig{Algorithm: hashlib.DefaultAlgorithm},
+		AssetID: AssetIDConfig{
+			Scheme: schemeContent,
+		},
+		Identifiers: defaultIdentifiersConfig(),
+		Sensors:     defaultSensorsConfig(),
+		Log:         LogConfig{Level: "info", Format: "text"},
+		Workers:     2,
+		QueueSize:   1024,
+		Health: HealthConfig{
+			MaxBacklog:   100,
+			StallTimeout: 10 * time.Minute,
+		},
+		Journal: JournalConfig{
+			ReconcileInterval: 30 * time.Second,
+		},
+		StateDir: "/var/lib/mcapdaemon",
+	}
+}
+
+// loadConfig reads a YAML config file over the defaults. An empty path
+// returns the defaults unchanged.
+func loadConfig(configPath string) (Config, error) {
+	cfg := defaultConfig()
+	if configPath == "" {
+		return cfg, nil
+	}
+
+	data, err := os.ReadFile(filepath.Clean(configPath))
+	if err != nil {
+		return cfg, fmt.Errorf("failed to read config file: %w", err)
+	}
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("failed to parse config file %s: %w", configPath, err)
+	}
+	if cfg.Workers < 1 {
+		return cfg, fmt.Errorf("workers must be at least 1, got %d", cfg.Workers)
+	}
+	if cfg.QueueSize < 0 {
+		return cfg, fmt.Errorf("queueSize must not be negative, got %d", cfg.QueueSize)
+	}
+	if err := cfg.Hash.validate(); err != nil {
+		return cfg, fmt.Errorf("invalid hash config: %w", err)
+	}
+	if err := cfg.Sensors.validate(); err != nil {
+		return cfg, fmt.Errorf("invalid sensors config: %w", err)
+	}
+	return cfg, nil
+}
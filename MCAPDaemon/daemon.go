@@ -1,157 +1,47 @@
 package main
 
 import (
-	"bytes"
-	//"context"
-	"crypto/x509"
-	"encoding/json"
-	"path"
+	"context"
 	"errors"
+	"flag"
 	"fmt"
+	"log/slog"
 	"math"
 	"os"
+	"path/filepath"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
-	"github.com/fsnotify/fsnotify"
-	"github.com/hyperledger/fabric-gateway/pkg/client"                                 
-	"github.com/hyperledger/fabric-gateway/pkg/hash"                                   
-	"github.com/hyperledger/fabric-gateway/pkg/identity"                               
-	//"github.com/hyperledger/fabric-protos-go-apiv2/gateway"                            
-	"google.golang.org/grpc"                                                           
-	"google.golang.org/grpc/credentials"                                               
-	//"google.golang.org/grpc/status"
 
+	"github.com/Octavian-Anghel/Capstone-Project/adminapi"
+	"github.com/Octavian-Anghel/Capstone-Project/hashlib"
+	"github.com/Octavian-Anghel/Capstone-Project/ledger"
+	"github.com/Octavian-Anghel/Capstone-Project/mcap"
+	"github.com/fsnotify/fsnotify"
 )
 
-const (                                                                                    
-    mspID        = "Org1MSP"                                                               
-    cryptoPath   = "/home/oz/fabric-samples/test-network/organizations/peerOrganizations/org1.example.com"   
-    certPath     = cryptoPath + "/users/User1@org1.example.com/msp/signcerts"              
-    keyPath      = cryptoPath + "/users/User1@org1.example.com/msp/keystore"               
-    tlsCertPath  = cryptoPath + "/peers/peer0.org1.example.com/tls/ca.crt"                 
-    peerEndpoint = "dns:///localhost:7051"                                                 
-    gatewayPeer  = "peer0.org1.example.com"                                                
-)   
-
-var now = time.Now()                                                                       
-var assetId = fmt.Sprintf("asset%d", now.Unix()*1e3+int64(now.Nanosecond())/1e6)
-
-// newGrpcConnection creates a gRPC connection to the Gateway server.                      
-func newGrpcConnection() *grpc.ClientConn {                                                
-    certificatePEM, err := os.ReadFile(tlsCertPath)                                        
-    if err != nil {                                                                        
-        panic(fmt.Errorf("failed to read TLS certifcate file: %w", err))                   
-    }                                                                                      
-                                                                       
-    certificate, err := identity.CertificateFromPEM(certificatePEM)                        
-    if err != nil {                                                                        
-        panic(err)                                                                         
-    }                                                                                      
-                                                                                           
-    certPool := x509.NewCertPool()                                                         
-    certPool.AddCert(certificate)                                                          
-    transportCredentials := credentials.NewClientTLSFromCert(certPool, gatewayPeer)        
-                                                                                           
-    connection, err := grpc.NewClient(peerEndpoint, grpc.WithTransportCredentials(transportCredentials))
-    if err != nil {                                                                        
-        panic(fmt.Errorf("failed to create gRPC connection: %w", err))                     
-    }                                                                                      
-                                                                                           
-    return connection                                                                      
-}                                                                                          
-                                                                                           
-// newIdentity creates a client identity for this Gateway connection using an X.509 certifi
-func newIdentity() *identity.X509Identity {                                                
-    certificatePEM, err := readFirstFile(certPath)                                         
-    if err != nil {                                                                        
-        panic(fmt.Errorf("failed to read certificate file: %w", err))                      
-    }                                                                                      
-                                                                                           
-    certificate, err := identity.CertificateFromPEM(certificatePEM)                        
-    if err != nil {                                                                        
-        panic(err)                                                                         
-    }                                                                                      
-                                                                                           
-    id, err := identity.NewX509Identity(mspID, certificate)                                
-    if err != nil {                                                                        
-        panic(err)                                                                         
-    }                                                                                      
-                                                                                           
-    return id                                                                              
-}
-
-// newSign creates a function that generates a digital signature from a message digest usin
-func newSign() identity.Sign {                                                             
-    privateKeyPEM, err := readFirstFile(keyPath)                                           
-    if err != nil {                                                                        
-        panic(fmt.Errorf("failed to read private key file: %w", err))                      
-    }                                                                                      
-                                                                                           
-    privateKey, err := identity.PrivateKeyFromPEM(privateKeyPEM)                           
-    if err != nil {                                                                        
-        panic(err)                                                                         
-    }                                                                                      
-                                                                                           
-    sign, err := identity.NewPrivateKeySign(privateKey)                                    
-    if err != nil {                                                                        
-        panic(err)                                                                         
-    }                                                                                      
-                                                                                           
-    return sign                                                                            
-}                                                                                          
-                                                                                           
-func readFirstFile(dirPath string) ([]byte, error) {                                       
-    dir, err := os.Open(dirPath)                                                           
-    if err != nil {                                                                        
-            return nil, err                                                                    
-        }                                                                                      
-                                                                                               
-        fileNames, err := dir.Readdirnames(1)                                                  
-        if err != nil {                                                                        
-            return nil, err                                                                    
-        }                                                                                      
-                                                                                               
-        return os.ReadFile(path.Join(dirPath, fileNames[0]))                                   
-    }
-
-// Submit a transaction synchronously, blocking until it has been committed to the ledger.                                                                        
-func CreateAsset(contract *client.Contract, hash string, mcapID string, operationID string) {                                                                                                                     
-    fmt.Printf("\n--> Submit Transaction: CreateAsset, creates new hash asset on the ledger\n")                            
-
-    _, err := contract.SubmitTransaction("CreateAsset", string(time.Now().Format(time.RFC3339)), hash, mcapID, operationID)                                                                    
-    if err != nil {                                                                                                                                               
-        fmt.Printf("failed to submit transaction: %w", err)                                                                                               
-    }                                                                                                                                                             
-                                                                                                                                                                          
-    fmt.Printf("*** Transaction committed successfully\n")                                                                                                        
-}
-
-func printTime(format string, a ...interface{}) {
-		fmt.Printf("[%s] ", time.Now().Format(time.RFC3339))
-	fmt.Printf(format+"\n", a...)
-}
-
-func exit(format string, a ...interface{}) {
-		printTime(format, a...)
+// exit logs a fatal startup error and terminates the process.
+func exit(msg string, err error) {
+	slog.Error(msg, "err", err)
 	os.Exit(1)
 }
 
 func getMagicBytes(filePath string) (string, error) {
-		file, err := os.Open(filePath)
+	file, err := os.Open(filePath)
 	if err != nil {
-			return "", err
+		return "", err
 	}
 	defer file.Close()
 
 	fileInfo, err := file.Stat()
 	if err != nil {
-			return "", err
+		return "", err
 	}
 	fileSize := fileInfo.Size()
 
 	if fileSize < 5 {
-			return "", errors.New("filesize too small, likely bad file")
+		return "", errors.New("filesize too small, likely bad file")
 	}
 
 	start := fileSize - int64(7)
@@ -159,56 +49,470 @@ func getMagicBytes(filePath string) (string, error) {
 
 	_, err = file.ReadAt(buff, start)
 	if err != nil {
-			return "", err
+		return "", err
 	}
 
 	return string(buff), nil
 }
 
-func dedupLoop(w *fsnotify.Watcher, contract *client.Contract) {
-		var (
+// daemon ties the watcher to the ledger for a single watch directory.
+type daemon struct {
+	configPath string
+	started    time.Time
+
+	// mu guards the settings a config reload replaces.
+	mu          sync.RWMutex
+	cfg         Config
+	ids         *assetIDScheme
+	identifiers *identifierResolver
+	reloaded    time.Time
+
+	ledger  *ledger.Client
+	metrics *metrics
+	backlog *backlog
+	journal *journal
+	hashes  *checkpoints
+	health  *health
+	queue   chan job
+
+	// Admin API state
+	gate   *gate
+	busy   atomic.Int32
+	recent *recentFiles
+}
+
+// settings returns the config and the parts of it a reload may replace.
+func (d *daemon) settings() (Config, *assetIDScheme, *identifierResolver) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.cfg, d.ids, d.identifiers
+}
+
+// job is a validated MCAP file waiting to be hashed and anchored.
+type job struct {
+	logger *slog.Logger
+	entry  backlogEntry
+}
+
+// errRejected marks recordings that will never be anchored, so retrying
+// them is pointless.
+var errRejected = errors.New("recording rejected")
+
+// errInFlight marks recordings whose journal capture is already being
+// anchored elsewhere, by the reconciler or another worker.
+var errInFlight = errors.New("recording already in flight")
+
+// enqueue records a file in the durable backlog and hands it to the
+// workers, blocking while the queue is full.
+func (d *daemon) enqueue(logger *slog.Logger, entry backlogEntry) {
+	if err := d.backlog.Add(entry); err != nil {
+		logger.Error("failed to persist backlog", "err", err)
+	}
+	d.recent.record(adminapi.File{Path: entry.Path, CID: entry.CID, Status: adminapi.FileQueued})
+	d.queue <- job{logger: logger, entry: entry}
+	d.metrics.queueDepth.WithLabelValues("process").Set(float64(len(d.queue)))
+}
+
+// runWorkers processes queued files on n goroutines. While the daemon is
+// paused, each worker holds the file it took off the queue.
+func (d *daemon) runWorkers(n int) {
+	for i := 0; i < n; i++ {
+		go func() {
+			for j := range d.queue {
+				d.metrics.queueDepth.WithLabelValues("process").Set(float64(len(d.queue)))
+				d.gate.Wait()
+				d.busy.Add(1)
+				d.work(j)
+				d.busy.Add(-1)
+				d.health.progress()
+			}
+		}()
+	}
+}
+
+// work processes a single job and drops it from the backlog, along with
+// its hash checkpoint, unless it failed in a way that is worth retrying
+// later. Recordings that were captured but could not be anchored are
+// retried from the journal.
+func (d *daemon) work(j job) {
+	file := adminapi.File{Path: j.entry.Path, CID: j.entry.CID, Status: adminapi.FileProcessing}
+	d.recent.record(file)
+
+	err := d.processFile(j.logger, j.entry.Path)
+	switch {
+	case err == nil:
+		file.Status = adminapi.FileAnchored
+	case errors.Is(err, errInFlight):
+		// Whoever holds the capture records its outcome, so the backlog
+		// entry is left for them.
+		j.logger.Info("recording is already being anchored")
+		file.Status = adminapi.FilePending
+		file.Error = err.Error()
+		d.recent.record(file)
+		return
+	case errors.Is(err, errRejected):
+		file.Status = adminapi.FileRejected
+		j.logger.Error("rejecting recording", "err", err)
+	case errors.Is(err, ledger.ErrAssetExists):
+		file.Status = adminapi.FileDuplicate
+		j.logger.Warn("recording is already anchored, marking it done", "err", err)
+	case ledger.IsTransient(err):
+		file.Status = adminapi.FilePending
+		j.logger.Warn("failed to anchor recording, keeping it in the journal until the gateway is reachable", "err", err)
+	default:
+		file.Status = adminapi.FileFailed
+		j.logger.Error("permanent failure anchoring recording, dropping it from the backlog", "err", err)
+	}
+	if err != nil {
+		file.Error = err.Error()
+	}
+	d.recent.record(file)
+
+	if err := d.backlog.Remove(j.entry.Path); err != nil {
+		j.logger.Error("failed to persist backlog", "err", err)
+	}
+	if err := d.hashes.forget(j.entry.Path); err != nil {
+		j.logger.Error("failed to persist hash checkpoints", "err", err)
+	}
+}
+
+// processFile hashes a validated recording, captures it in the journal and
+// anchors it on the ledger.
+func (d *daemon) processFile(logger *slog.Logger, filePath string) error {
+	if r, ok := d.journal.PendingFor(filePath); ok {
+		// Captured before a restart but never anchored, unless the file
+		// was replaced since.
+		if !d.journal.Claim(r.Seq) {
+			return errInFlight
+		}
+		replaced, err := d.replaced(*r.Capture)
+		if err != nil {
+			d.journal.Release(r.Seq)
+			return err
+		}
+		if !replaced {
+			logger.Info("recording already captured", "seq", r.Seq)
+			return d.anchor(logger.With("mcapId", r.Capture.McapID), r)
+		}
+		logger.Warn("recording was replaced since it was captured, abandoning the capture", "seq", r.Seq)
+		if err := d.journal.Abandon(r.Seq, "recording replaced"); err != nil {
+			return fmt.Errorf("failed to write journal: %w", err)
+		}
+		d.metrics.queueDepth.WithLabelValues("journal").Set(float64(d.journal.Len()))
+	}
+
+	cfg, ids, identifiers := d.settings()
+	rec := newRecording(cfg.WatchDir, filePath)
+
+	metadata, err := mcap.ReadMetadataFile(filePath)
+	if err != nil {
+		d.metrics.filesRejected.WithLabelValues("metadata").Inc()
+		return fmt.Errorf("%w: failed to read MCAP metadata: %v", errRejected, err)
+	}
+	rec.Metadata = metadata
+
+	if err := identifiers.Resolve(&rec); err != nil {
+		d.metrics.filesRejected.WithLabelValues("identifiers").Inc()
+		return fmt.Errorf("%w: %v", errRejected, err)
+	}
+	d.metrics.filesValidated.Inc()
+	logger = logger.With("operation", rec.Operation, "project", rec.Project)
+
+	// Sensor readings are context for the recording, not part of its
+	// integrity, so a bad summary is logged and the file anchored without.
+	sensors, err := summarizeSensors(cfg.Sensors, cfg.Identifiers.Sidecar.Extensions, filePath)
+	if err != nil {
+		logger.Warn("failed to summarise sensor readings", "err", err)
+	}
+
+	fileInfo, err := os.Stat(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to stat recording: %w", err)
+	}
+
+	hasher, err := hashlib.Lookup(cfg.Hash.Algorithm)
+	if err != nil {
+		return err
+	}
+	start := time.Now()
+	var hashed int64
+	rec.Hash, hashed, err = d.hashes.finish(context.Background(), hasher, filePath)
+	if err != nil {
+		return fmt.Errorf("failed to hash recording: %w", err)
+	}
+	elapsed := time.Since(start)
+	d.metrics.observeHash(hashed, elapsed)
+	logger.Info("hashed recording", "algorithm", hasher.ID(), "duration", elapsed, "size", fileInfo.Size(), "read", hashed)
+
+	// The chunk tree lets a time slice be proven later without the whole
+	// file. Recordings without a chunk index are anchored without it.
+	var chunkRoot string
+	if tree, err := hashlib.HashChunks(context.Background(), filePath, nil); err == nil {
+		chunkRoot = tree.Root
+	} else if !errors.Is(err, mcap.ErrNoSummary) && !errors.Is(err, hashlib.ErrNoChunks) {
+		logger.Warn("failed to hash chunks", "err", err)
+	}
+
+	mcapID, anchored := "", false
+	if cfg.AssetID.Scheme == schemeUUIDv7 {
+		// Random IDs are minted once per recording, so a recording picked
+		// up again is found under the asset it was anchored as.
+		mcapID, anchored = d.journal.AnchoredAs(rec.Path, rec.Hash)
+	}
+	if !anchored {
+		if mcapID, err = ids.AssetID(rec); err != nil {
+			return fmt.Errorf("%w: failed to derive asset ID: %v", errRejected, err)
+		}
+	}
+	logger = logger.With("mcapId", mcapID)
+
+	r, err := d.journal.Capture(capture{
+		McapID:    mcapID,
+		Path:      rec.Path,
+		Hash:      rec.Hash,
+		Operation: rec.Operation,
+		Project:   rec.Project,
+		Size:      fileInfo.Size(),
+		Algorithm: hasher.ID(),
+		ChunkRoot: chunkRoot,
+		Sensors:   sensors,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to capture recording: %w", err)
+	}
+	d.metrics.queueDepth.WithLabelValues("journal").Set(float64(d.journal.Len()))
+	logger.Info("captured recording in journal", "seq", r.Seq)
+
+	return d.anchor(logger, r)
+}
+
+// replaced reports whether the recording at the path of c no longer has
+// the size and hash it was captured with.
+func (d *daemon) replaced(c capture) (bool, error) {
+	fileInfo, err := os.Stat(c.Path)
+	if err != nil {
+		return false, fmt.Errorf("failed to stat recording: %w", err)
+	}
+	if fileInfo.Size() != c.Size {
+		return true, nil
+	}
+	hasher, err := hashlib.Lookup(c.algorithm())
+	if err != nil {
+		return false, err
+	}
+	digest, _, err := d.hashes.finish(context.Background(), hasher, c.Path)
+	if err != nil {
+		return false, fmt.Errorf("failed to hash recording: %w", err)
+	}
+	return digest != c.Hash, nil
+}
+
+// anchor submits a captured recording to the ledger and records the
+// outcome in the journal. The caller must hold the journal claim on r.
+func (d *daemon) anchor(logger *slog.Logger, r journalRecord) error {
+	c := r.Capture
+	cfg, _, _ := d.settings()
+	logger.Info("submitting CreateAsset transaction")
+	start := time.Now()
+	txStatus, err := d.ledger.CreateAsset(context.Background(), ledger.NewAsset{
+		Algorithm:   c.Algorithm,
+		CaptureTime: r.Time,
+		ChunkRoot:   c.ChunkRoot,
+		Hash:        c.Hash,
+		McapID:      c.McapID,
+		Operation:   c.Operation,
+		Project:     c.Project,
+		Path:        c.Path,
+		Sensors:     c.Sensors,
+	})
+	d.metrics.submitDuration.Observe(time.Since(start).Seconds())
+	defer func() {
+		d.metrics.queueDepth.WithLabelValues("journal").Set(float64(d.journal.Len()))
+	}()
+
+	if errors.Is(err, ledger.ErrAssetExists) {
+		// A retry whose first attempt committed after all finds the asset
+		// already there. It is this recording's anchor if the hash matches.
+		if original, herr := d.ledger.CreatedWith(context.Background(), c.McapID, c.Hash); herr != nil {
+			logger.Warn("failed to read asset history", "err", herr)
+		} else if original != nil {
+			logger.Info("recording was already anchored by an earlier attempt")
+			txStatus, err = original, nil
+		}
+	}
+
+	switch {
+	case err == nil:
+	case ledger.IsTransient(err):
+		d.metrics.observeTxFailure(err)
+		d.journal.Release(r.Seq)
+		d.recent.record(adminapi.File{Path: c.Path, McapID: c.McapID, Status: adminapi.FilePending, Error: err.Error()})
+		return err
+	default:
+		d.metrics.observeTxFailure(err)
+		if jerr := d.journal.Abandon(r.Seq, err.Error()); jerr != nil {
+			logger.Error("failed to write journal", "err", jerr)
+		}
+		status := adminapi.FileFailed
+		if errors.Is(err, ledger.ErrAssetExists) {
+			status = adminapi.FileDuplicate
+		}
+		d.recent.record(adminapi.File{Path: c.Path, McapID: c.McapID, Status: status, Error: err.Error()})
+		return err
+	}
+
+	d.metrics.filesAnchored.Inc()
+	d.recent.record(adminapi.File{Path: c.Path, McapID: c.McapID, Status: adminapi.FileAnchored, TxID: txStatus.TransactionID})
+	logger = logger.With("txId", txStatus.TransactionID, "block", txStatus.BlockNumber)
+	logger.Info("anchored recording", "captured", r.Time)
+	if err := d.journal.Anchored(r.Seq, txStatus.TransactionID, txStatus.BlockNumber); err != nil {
+		logger.Error("failed to write journal", "err", err)
+	}
+
+	receiptPath, err := cfg.Receipts.writeReceipt(receipt{
+		McapID:         c.McapID,
+		Path:           c.Path,
+		Hash:           c.Hash,
+		Algorithm:      c.algorithm(),
+		ChunkRoot:      c.ChunkRoot,
+		TxID:           txStatus.TransactionID,
+		BlockNumber:    txStatus.BlockNumber,
+		ValidationCode: txStatus.Code.String(),
+		Peer:           txStatus.Peer,
+		Channel:        cfg.Gateway.Channel,
+		Chaincode:      cfg.Gateway.Chaincode,
+		JournalSeq:     r.Seq,
+		Captured:       r.Time,
+		Committed:      time.Now().UTC(),
+	})
+	if err != nil {
+		// The asset is on chain, so a missing receipt must not trigger a retry.
+		logger.Error("failed to write receipt", "err", err)
+		return nil
+	}
+	logger.Debug("wrote receipt", "receipt", receiptPath)
+	return nil
+}
+
+// reconcileLoop periodically anchors captures left in the journal while
+// the gateway was unreachable.
+func (d *daemon) reconcileLoop(interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		d.reconcile()
+	}
+}
+
+// reconcile anchors pending captures oldest first, stopping at the first
+// transient failure since the gateway is evidently still unreachable.
+func (d *daemon) reconcile() {
+	if d.gate.Paused() {
+		return
+	}
+	for _, r := range d.journal.Pending() {
+		if !d.journal.Claim(r.Seq) {
+			continue
+		}
+		logger := slog.With("seq", r.Seq, "file", r.Capture.Path, "mcapId", r.Capture.McapID)
+		err := d.anchor(logger, r)
+		switch {
+		case err == nil:
+		case errors.Is(err, ledger.ErrAssetExists):
+			logger.Warn("recording is already anchored, marking it done", "err", err)
+		case ledger.IsTransient(err):
+			logger.Warn("gateway still unreachable", "pending", d.journal.Len(), "err", err)
+			return
+		default:
+			logger.Error("permanent failure anchoring journalled recording, abandoning it", "err", err)
+		}
+	}
+}
+
+// hashGrowing hashes what has been written of a recording so far, at most
+// once per hash.checkpointInterval, so that only the rest is left to read
+// once it completes.
+func (d *daemon) hashGrowing(filePath string) {
+	cfg, _, _ := d.settings()
+	if cfg.Hash.CheckpointInterval <= 0 || d.gate.Paused() || !d.hashes.schedule(filePath, cfg.Hash.CheckpointInterval) {
+		return
+	}
+	hasher, err := hashlib.Lookup(cfg.Hash.Algorithm)
+	if err != nil {
+		return
+	}
+
+	go func() {
+		logger := slog.With("file", filePath)
+		start := time.Now()
+		hashed, err := d.hashes.advance(context.Background(), hasher, filePath)
+		if err != nil {
+			logger.Warn("failed to checkpoint growing recording", "err", err)
+			return
+		}
+		if hashed > 0 {
+			d.metrics.observeHash(hashed, time.Since(start))
+			logger.Debug("checkpointed growing recording", "read", hashed, "duration", time.Since(start))
+		}
+	}()
+}
+
+func dedupLoop(w *fsnotify.Watcher, d *daemon) {
+	d.health.watcherAlive.Store(true)
+	defer d.health.watcherAlive.Store(false)
+
+	var (
 		waitFor    = 100 * time.Millisecond
 		mu         sync.Mutex
 		timers     = make(map[string]*time.Timer)
 		printEvent = func(e fsnotify.Event) {
-				printTime("Detected event: %s", e)
+			cid := newCorrelationID()
+			logger := slog.With("cid", cid, "file", e.Name)
+			logger.Debug("detected event", "op", e.Op.String())
 
 			if strings.HasSuffix(e.Name, ".mcap") {
-					magic, err := getMagicBytes(e.Name)
+				d.metrics.filesDetected.Inc()
+				magic, err := getMagicBytes(e.Name)
 				if err != nil {
-						fmt.Printf("Failed to read magic bytes from %s: %v\n", e.Name, err)
+					d.metrics.filesRejected.WithLabelValues("magic").Inc()
+					logger.Warn("failed to read magic bytes", "err", err)
+				} else if magic == "MCAP0\r\n" {
+					logger.Info("valid MCAP file detected")
+					d.enqueue(logger, backlogEntry{Path: e.Name, CID: cid, Detected: time.Now()})
 				} else {
-						fmt.Printf("Magic bytes from %s: %s\n", e.Name, magic)
-					if magic == "MCAP0\r\n" {
-							fmt.Println("Valid MCAP file detected! pushing over to the hash and upload daemon\n")
-						// Additional processing here
-							hashString := HashNUpload(e.Name)
-							CreateAsset(contract, hashString, e.Name, "Dummy Operation ID")
-					}
+					d.metrics.filesRejected.WithLabelValues("magic").Inc()
+					logger.Debug("ignoring file without MCAP magic", "magic", fmt.Sprintf("%q", magic))
 				}
 			}
 
 			mu.Lock()
 			delete(timers, e.Name)
+			d.metrics.queueDepth.WithLabelValues("debounce").Set(float64(len(timers)))
 			mu.Unlock()
 		}
 	)
 
 	for {
-			select {
-			case err, ok := <-w.Errors:
+		select {
+		case err, ok := <-w.Errors:
 			if !ok {
-					return
+				return
 			}
-			printTime("ERROR: %s", err)
+			slog.Error("watcher error", "err", err)
 
 		case e, ok := <-w.Events:
 			if !ok {
-					return
+				return
 			}
 
 			if !e.Has(fsnotify.Create) && !e.Has(fsnotify.Write) {
-					continue
+				continue
+			}
+			if e.Has(fsnotify.Write) && strings.HasSuffix(e.Name, ".mcap") {
+				d.hashGrowing(e.Name)
 			}
 
 			mu.Lock()
@@ -216,10 +520,11 @@ func dedupLoop(w *fsnotify.Watcher, contract *client.Contract) {
 			mu.Unlock()
 
 			if !ok {
-					t = time.AfterFunc(math.MaxInt64, func() { printEvent(e) })
+				t = time.AfterFunc(math.MaxInt64, func() { printEvent(e) })
 				t.Stop()
 				mu.Lock()
 				timers[e.Name] = t
+				d.metrics.queueDepth.WithLabelValues("debounce").Set(float64(len(timers)))
 				mu.Unlock()
 			}
 
@@ -228,59 +533,136 @@ func dedupLoop(w *fsnotify.Watcher, contract *client.Contract) {
 	}
 }
 
-// Format JSON data                                                                        
-func formatJSON(data []byte) string {                                                      
-    var prettyJSON bytes.Buffer                                                            
-    if err := json.Indent(&prettyJSON, data, "", "  "); err != nil {                       
-        panic(fmt.Errorf("failed to parse JSON: %w", err))                                 
-    }                                                                                      
-    return prettyJSON.String()                                                             
-}            
-
 func main() {
+	configPath := flag.String("config", "", "path to the daemon YAML config file")
+	flag.Parse()
+
+	cfg, err := loadConfig(*configPath)
+	if err != nil {
+		exit("failed to load config", err)
+	}
+
+	logger, err := newLogger(cfg.Log, os.Stdout)
+	if err != nil {
+		exit("failed to create logger", err)
+	}
+	slog.SetDefault(logger)
+
+	ids, err := newAssetIDScheme(cfg.AssetID)
+	if err != nil {
+		exit("invalid asset ID config", err)
+	}
+
+	identifiers, err := newIdentifierResolver(cfg.Identifiers)
+	if err != nil {
+		exit("invalid identifiers config", err)
+	}
+
+	ledgerClient, err := ledger.Connect(context.Background(), cfg.Gateway)
+	if err != nil {
+		exit("failed to connect to gateway", err)
+	}
+	defer ledgerClient.Close()
 
-	clientConnection := newGrpcConnection()                                                
-	defer clientConnection.Close()                                                         
-	                                                                                           
-	id := newIdentity()                                                                    
-	sign := newSign()                                                                      
-	                                                                                           
-    // Create a Gateway connection for a specific client identity                          
-    gw, err := client.Connect(                                                             
-	   id,                                                                                
-	   client.WithSign(sign),                                                             
-	   client.WithHash(hash.SHA256),                                                      
-	   client.WithClientConnection(clientConnection),                                     
-	   // Default timeouts for different gRPC calls                                       
-	   client.WithEvaluateTimeout(5*time.Second),                                         
-	   client.WithEndorseTimeout(15*time.Second),                                         
-	   client.WithSubmitTimeout(5*time.Second),                                           
-	   client.WithCommitStatusTimeout(1*time.Minute),                                     
-    )                                                                                      
-    if err != nil {                                                                      
-        panic(err)                                                                         
-    }                                                                                
-    defer gw.Close()
-
-	chaincodeName := "mcap"
-	channelName := "mychannel"
-
-	network := gw.GetNetwork(channelName)                                                  
-	contract := network.GetContract(chaincodeName) 
+	id := ledgerClient.Identity()
+	logger.Info("loaded identity", "mspId", id.MspID(), "subject", id.Certificate().Subject.CommonName, "expires", id.Certificate().NotAfter)
+	go id.Run(context.Background(), cfg.Gateway.Identity.RefreshInterval, func(err error) {
+		logger.Error("failed to refresh identity", "err", err, "expires", id.Certificate().NotAfter)
+	})
 
 	w, err := fsnotify.NewWatcher()
 	if err != nil {
-		exit("creating a new watcher: %s", err)
+		exit("failed to create watcher", err)
 	}
 	defer w.Close()
 
-	go dedupLoop(w, contract)
+	if err := os.MkdirAll(cfg.StateDir, 0o750); err != nil {
+		exit("failed to create state directory", err)
+	}
+	pending, err := openBacklog(filepath.Join(cfg.StateDir, "backlog.json"))
+	if err != nil {
+		exit("failed to open backlog", err)
+	}
+	captures, err := openJournal(filepath.Join(cfg.StateDir, "journal.jsonl"), ledgerClient.Identity(), logger)
+	if err != nil {
+		exit("failed to open journal", err)
+	}
+	defer captures.Close()
+	logger.Info("opened journal", "pending", captures.Len())
+	hashes, err := openCheckpoints(filepath.Join(cfg.StateDir, "checkpoints.json"))
+	if err != nil {
+		exit("failed to open hash checkpoints", err)
+	}
+	logger.Info("opened hash checkpoints", "recordings", hashes.Len())
+
+	d := &daemon{
+		configPath:  *configPath,
+		started:     time.Now(),
+		cfg:         cfg,
+		ids:         ids,
+		identifiers: identifiers,
+		ledger:      ledgerClient,
+		metrics:     newMetrics(),
+		backlog:     pending,
+		journal:     captures,
+		hashes:      hashes,
+		queue:       make(chan job, cfg.QueueSize),
+		gate:        newGate(),
+		recent:      newRecentFiles(recentFilesSize),
+	}
+	d.health = newHealth(cfg.Health, ledgerClient.Peers, pending, captures, d.queue)
+	ledgerClient.OnRetry = func(err error, attempt int, wait time.Duration) {
+		d.metrics.observeTxFailure(err)
+		logger.Warn("retrying gateway call", "attempt", attempt, "wait", wait, "err", err)
+	}
+	ledgerClient.OnFailover = func(from, to string) {
+		d.metrics.failovers.Inc()
+		logger.Warn("switching gateway peer", "from", from, "to", to)
+	}
+	go ledgerClient.Run(context.Background())
+	d.runWorkers(cfg.Workers)
+	go dedupLoop(w, d)
+	go d.reconcileLoop(cfg.Journal.ReconcileInterval)
+
+	// Resume recordings left over from a previous run.
+	go func() {
+		for _, entry := range pending.Entries() {
+			entryLogger := slog.With("cid", entry.CID, "file", entry.Path)
+			entryLogger.Info("resuming recording from backlog", "detected", entry.Detected)
+			d.enqueue(entryLogger, entry)
+		}
+	}()
+
+	if cfg.HTTP.Listen != "" {
+		server := newHTTPServer(cfg.HTTP, d.metrics, d.health)
+		go func() {
+			logger.Info("serving HTTP endpoint", "addr", cfg.HTTP.Listen)
+			if err := server.ListenAndServe(); err != nil {
+				logger.Error("HTTP endpoint stopped", "err", err)
+			}
+		}()
+	}
+
+	if cfg.Admin.Socket != "" {
+		l, err := listenAdmin(cfg.Admin)
+		if err != nil {
+			exit("failed to open admin socket", err)
+		}
+		defer os.Remove(cfg.Admin.Socket)
+		server := newAdminServer(d)
+		go func() {
+			logger.Info("serving admin API", "socket", cfg.Admin.Socket)
+			if err := server.Serve(l); err != nil {
+				logger.Error("admin API stopped", "err", err)
+			}
+		}()
+	}
 
-	path := "/shared"
-	err = w.Add(path)
+	err = w.Add(cfg.WatchDir)
 	if err != nil {
-		exit("%q: %s", path, err)
+		exit("failed to watch "+cfg.WatchDir, err)
 	}
+	logger.Info("watching for recordings", "dir", cfg.WatchDir)
 
 	// Prevent main from exiting
 	select {}
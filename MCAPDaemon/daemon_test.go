@@ -0,0 +1,314 @@
+package main
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"log/slog"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Octavian-Anghel/Capstone-Project/adminapi"
+	"github.com/Octavian-Anghel/Capstone-Project/ledger"
+	"github.com/Octavian-Anghel/Capstone-Project/wallet"
+	"github.com/hyperledger/fabric-protos-go-apiv2/common"
+	"github.com/hyperledger/fabric-protos-go-apiv2/gateway"
+	"github.com/hyperledger/fabric-protos-go-apiv2/peer"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/protobuf/proto"
+)
+
+// Test that a worker leaves a recording alone while its capture is being
+// anchored elsewhere
+func TestWork_InFlight(t *testing.T) {
+	d := &daemon{
+		cfg:     defaultConfig(),
+		journal: openTestJournal(t, filepath.Join(t.TempDir(), "journal.jsonl"), newTestSigner(t)),
+		metrics: newMetrics(),
+		recent:  newRecentFiles(recentFilesSize),
+	}
+	d.health = newTestHealth(t, d.cfg.Health)
+	d.backlog = d.health.backlog
+	hashes, err := openCheckpoints(filepath.Join(t.TempDir(), "checkpoints.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	d.hashes = hashes
+
+	filePath := filepath.Join(t.TempDir(), "run1.mcap")
+	// The capture stays claimed, as if the reconciler were anchoring it.
+	if _, err := d.journal.Capture(capture{McapID: "abc", Path: filePath, Hash: "0123"}); err != nil {
+		t.Fatal(err)
+	}
+	entry := backlogEntry{Path: filePath, CID: "cid-1", Detected: time.Now()}
+	if err := d.backlog.Add(entry); err != nil {
+		t.Fatal(err)
+	}
+
+	d.work(job{logger: slog.New(slog.NewTextHandler(io.Discard, nil)), entry: entry})
+	if d.backlog.Len() != 1 {
+		t.Errorf("Expected the backlog entry to be kept, got %d entries", d.backlog.Len())
+	}
+	files := d.recent.list(func(adminapi.File) bool { return true })
+	if len(files) != 1 || files[0].Status != adminapi.FilePending {
+		t.Errorf("Expected the recording to stay pending, got %+v", files)
+	}
+}
+
+// fakeGateway endorses, orders and commits every transaction.
+type fakeGateway struct {
+	gateway.UnimplementedGatewayServer
+
+	mu        sync.Mutex
+	submitted int
+}
+
+func (g *fakeGateway) Endorse(context.Context, *gateway.EndorseRequest) (*gateway.EndorseResponse, error) {
+	data, _ := proto.Marshal(&peer.Transaction{Actions: []*peer.TransactionAction{{}}})
+	payload, _ := proto.Marshal(&common.Payload{Data: data})
+	return &gateway.EndorseResponse{PreparedTransaction: &common.Envelope{Payload: payload}}, nil
+}
+
+func (g *fakeGateway) Submit(context.Context, *gateway.SubmitRequest) (*gateway.SubmitResponse, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.submitted++
+	return &gateway.SubmitResponse{}, nil
+}
+
+func (g *fakeGateway) CommitStatus(context.Context, *gateway.SignedCommitStatusRequest) (*gateway.CommitStatusResponse, error) {
+	return &gateway.CommitStatusResponse{Result: peer.TxValidationCode_VALID, BlockNumber: 3}, nil
+}
+
+// serveGateway serves g over TLS and returns the peer config to reach it.
+func serveGateway(t *testing.T, g *fakeGateway) ledger.PeerConfig {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "peer0.org1.example.com"},
+		IPAddresses:           []net.IP{net.IPv4(127, 0, 0, 1)},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certPath := filepath.Join(t.TempDir(), "ca.crt")
+	if err := os.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := grpc.NewServer(grpc.Creds(credentials.NewServerTLSFromCert(&tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key})))
+	gateway.RegisterGatewayServer(srv, g)
+	go srv.Serve(l)
+	t.Cleanup(srv.Stop)
+	return ledger.PeerConfig{Endpoint: l.Addr().String(), TLSCertPath: certPath}
+}
+
+// flakySigner is a remote signer that fails once when fail is set.
+func flakySigner(t *testing.T, signer *testSigner, fail *atomic.Bool) string {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req wallet.SignRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if fail.CompareAndSwap(true, false) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			json.NewEncoder(w).Encode(wallet.SignResponse{Error: "token busy"})
+			return
+		}
+		signature, err := signer.Sign(req.Digest)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		json.NewEncoder(w).Encode(wallet.SignResponse{Signature: signature})
+	}))
+	t.Cleanup(srv.Close)
+	return srv.URL
+}
+
+// connectGateway connects to g as signer, through a remote signer that
+// fails once whenever fail is set.
+func connectGateway(t *testing.T, g *fakeGateway, signer *testSigner, fail *atomic.Bool) *ledger.Client {
+	t.Helper()
+	mspDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(mspDir, "signcerts"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: signer.cert.Raw})
+	if err := os.WriteFile(filepath.Join(mspDir, "signcerts", "cert.pem"), certPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	gatewayCfg := ledger.DefaultConfig()
+	gatewayCfg.Identity = wallet.Config{
+		Type:   wallet.TypeMSP,
+		MSPID:  "Org1MSP",
+		MSP:    wallet.MSPConfig{Dir: mspDir},
+		Signer: wallet.SignerConfig{Type: wallet.SignerRemote, Remote: wallet.RemoteConfig{URL: flakySigner(t, signer, fail), Timeout: time.Second}},
+	}
+	gatewayCfg.Peers = []ledger.PeerConfig{serveGateway(t, g)}
+	gatewayCfg.Retry = ledger.RetryPolicy{MaxAttempts: 1}
+	client, err := ledger.Connect(context.Background(), gatewayCfg)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+// Test that a recording stays pending through a brief signer outage and is
+// anchored once the signer is back
+func TestAnchor_SignerOutage(t *testing.T) {
+	signer := newTestSigner(t)
+	var fail atomic.Bool
+	g := &fakeGateway{}
+
+	d := &daemon{
+		cfg:     defaultConfig(),
+		journal: openTestJournal(t, filepath.Join(t.TempDir(), "journal.jsonl"), signer),
+		ledger:  connectGateway(t, g, signer, &fail),
+		metrics: newMetrics(),
+		gate:    newGate(),
+		recent:  newRecentFiles(recentFilesSize),
+	}
+	r, err := d.journal.Capture(capture{McapID: "abc", Path: filepath.Join(t.TempDir(), "run1.mcap"), Hash: "0123"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fail.Store(true)
+	err = d.anchor(slog.New(slog.NewTextHandler(io.Discard, nil)), r)
+	if !ledger.IsTransient(err) {
+		t.Fatalf("Expected a transient failure, got %v", err)
+	}
+	if d.journal.Len() != 1 {
+		t.Fatalf("Expected the capture to stay pending, got %d", d.journal.Len())
+	}
+
+	d.reconcile()
+	if d.journal.Len() != 0 || g.submitted != 1 {
+		t.Errorf("Expected the capture to be anchored once, got %d pending and %d submitted", d.journal.Len(), g.submitted)
+	}
+}
+
+// newTestDaemon returns a daemon watching a temporary directory and
+// anchoring through g.
+func newTestDaemon(t *testing.T, g *fakeGateway) *daemon {
+	t.Helper()
+	signer := newTestSigner(t)
+	d := &daemon{
+		cfg:         defaultConfig(),
+		journal:     openTestJournal(t, filepath.Join(t.TempDir(), "journal.jsonl"), signer),
+		ledger:      connectGateway(t, g, signer, new(atomic.Bool)),
+		metrics:     newMetrics(),
+		gate:        newGate(),
+		recent:      newRecentFiles(recentFilesSize),
+		identifiers: &identifierResolver{},
+	}
+	d.cfg.WatchDir = t.TempDir()
+	hashes, err := openCheckpoints(filepath.Join(t.TempDir(), "checkpoints.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	d.hashes = hashes
+	return d
+}
+
+// Test that a recording picked up again keeps the UUIDv7 it was anchored as
+func TestProcessFile_UUIDv7(t *testing.T) {
+	d := newTestDaemon(t, &fakeGateway{})
+	d.cfg.AssetID.Scheme = schemeUUIDv7
+	ids, err := newAssetIDScheme(d.cfg.AssetID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d.ids = ids
+
+	filePath := filepath.Join(d.cfg.WatchDir, "run1.mcap")
+	writeSensorRecording(t, filePath, "/env", `{"temperature":21}`)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	var anchoredAs []string
+	for range 2 {
+		if err := d.processFile(logger, filePath); err != nil {
+			t.Fatalf("Failed to process recording: %v", err)
+		}
+		if err := d.hashes.forget(filePath); err != nil {
+			t.Fatal(err)
+		}
+		files := d.recent.list(func(adminapi.File) bool { return true })
+		anchoredAs = append(anchoredAs, files[0].McapID)
+	}
+	if anchoredAs[0] == "" || anchoredAs[0] != anchoredAs[1] {
+		t.Errorf("Expected the recording to keep its asset ID, got %v", anchoredAs)
+	}
+}
+
+// Test that a pending capture of a recording that was replaced since is
+// abandoned and the new content anchored
+func TestProcessFile_Replaced(t *testing.T) {
+	d := newTestDaemon(t, &fakeGateway{})
+	ids, err := newAssetIDScheme(d.cfg.AssetID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d.ids = ids
+
+	filePath := filepath.Join(d.cfg.WatchDir, "run1.mcap")
+	writeSensorRecording(t, filePath, "/env", `{"temperature":21}`)
+	info, err := os.Stat(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stale, err := d.journal.Capture(capture{McapID: "old", Path: filePath, Hash: "0123", Size: info.Size()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	d.journal.Release(stale.Seq)
+
+	if err := d.processFile(slog.New(slog.NewTextHandler(io.Discard, nil)), filePath); err != nil {
+		t.Fatalf("Failed to process recording: %v", err)
+	}
+	if d.journal.Len() != 0 {
+		t.Errorf("Expected no pending captures, got %d", d.journal.Len())
+	}
+	if _, ok := d.journal.AnchoredAs(filePath, "0123"); ok {
+		t.Errorf("Expected the stale capture not to be anchored")
+	}
+	files := d.recent.list(func(adminapi.File) bool { return true })
+	if len(files) != 1 || files[0].McapID == "old" {
+		t.Errorf("Expected the new content to be anchored, got %+v", files)
+	}
+}
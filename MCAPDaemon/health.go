@@ -0,0 +1,132 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/Octavian-Anghel/Capstone-Project/ledger"
+	"google.golang.org/grpc/connectivity"
+)
+
+// HealthConfig sets the thresholds used by /healthz and /readyz.
+type HealthConfig struct {
+	// MaxBacklog is the number of unanchored recordings, queued or captured
+	// in the journal, above which the daemon reports itself as not ready.
+	MaxBacklog int `yaml:"maxBacklog"`
+
+	// StallTimeout is how long the workers may go without finishing a file
+	// while work is queued before the daemon reports itself as unhealthy.
+	StallTimeout time.Duration `yaml:"stallTimeout"`
+}
+
+// health tracks the liveness signals reported over HTTP.
+type health struct {
+	cfg HealthConfig
+
+	peers   func() []ledger.PeerState
+	backlog *backlog
+	journal *journal
+	queue   chan job
+
+	watcherAlive atomic.Bool
+	lastProgress atomic.Int64 // unix nanoseconds of the last finished file
+	paused       atomic.Bool  // workers are held by the admin API
+}
+
+func newHealth(cfg HealthConfig, peers func() []ledger.PeerState, b *backlog, j *journal, queue chan job) *health {
+	h := &health{cfg: cfg, peers: peers, backlog: b, journal: j, queue: queue}
+	h.progress()
+	return h
+}
+
+// progress records that a worker finished a file.
+func (h *health) progress() {
+	h.lastProgress.Store(time.Now().UnixNano())
+}
+
+// healthReport is the JSON body of /healthz and /readyz.
+type healthReport struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks"`
+}
+
+// liveness reports whether the process should be restarted: the watcher
+// loop has exited or the workers are stuck.
+func (h *health) liveness() (healthReport, bool) {
+	report := healthReport{Checks: make(map[string]string)}
+	ok := true
+
+	if h.watcherAlive.Load() {
+		report.Checks["watcher"] = "ok"
+	} else {
+		report.Checks["watcher"] = "stopped"
+		ok = false
+	}
+
+	idle := time.Since(time.Unix(0, h.lastProgress.Load()))
+	if h.paused.Load() {
+		report.Checks["workers"] = "paused"
+	} else if len(h.queue) > 0 && h.cfg.StallTimeout > 0 && idle > h.cfg.StallTimeout {
+		report.Checks["workers"] = fmt.Sprintf("stalled for %s with %d queued", idle.Round(time.Second), len(h.queue))
+		ok = false
+	} else {
+		report.Checks["workers"] = "ok"
+	}
+
+	return report.finish(ok), ok
+}
+
+// readiness reports whether the daemon can currently anchor recordings.
+func (h *health) readiness() (healthReport, bool) {
+	report, ok := h.liveness()
+
+	// Ready when any peer is usable. Idle connections dial on the next
+	// call, so only failures count against a peer.
+	reachable := false
+	for _, p := range h.peers() {
+		state := p.State.String()
+		if p.Active {
+			state += " (active)"
+		}
+		report.Checks["gateway "+p.Name] = state
+		if p.State == connectivity.Ready || p.State == connectivity.Idle {
+			reachable = true
+		}
+	}
+	if !reachable {
+		ok = false
+	}
+
+	// Captures waiting out a Fabric outage have left the backlog, so the
+	// journal is counted too.
+	pending := h.backlog.Len() + h.journal.Len()
+	report.Checks["backlog"] = fmt.Sprintf("%d/%d", pending, h.cfg.MaxBacklog)
+	if h.cfg.MaxBacklog > 0 && pending >= h.cfg.MaxBacklog {
+		ok = false
+	}
+
+	return report.finish(ok), ok
+}
+
+func (r healthReport) finish(ok bool) healthReport {
+	r.Status = "ok"
+	if !ok {
+		r.Status = "unavailable"
+	}
+	return r
+}
+
+// healthHandler adapts a liveness or readiness check to HTTP.
+func healthHandler(check func() (healthReport, bool)) http.HandlerFunc {
+	return func(w http.ResponseWriter, _ *http.Request) {
+		report, ok := check()
+		w.Header().Set("Content-Type", "application/json")
+		if !ok {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		_ = json.NewEncoder(w).Encode(report)
+	}
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Octavian-Anghel/Capstone-Project/ledger"
+	"google.golang.org/grpc/connectivity"
+)
+
+func newTestHealth(t *testing.T, cfg HealthConfig) *health {
+	t.Helper()
+	peers := func() []ledger.PeerState {
+		return []ledger.PeerState{
+			{Name: "peer0.org1.example.com", State: connectivity.TransientFailure},
+			{Name: "peer0.org2.example.com", State: connectivity.Idle, Active: true},
+		}
+	}
+
+	b, err := openBacklog(filepath.Join(t.TempDir(), "backlog.json"))
+	if err != nil {
+		t.Fatalf("Failed to open backlog: %v", err)
+	}
+	j := openTestJournal(t, filepath.Join(t.TempDir(), "journal.jsonl"), newTestSigner(t))
+	return newHealth(cfg, peers, b, j, make(chan job, 4))
+}
+
+func statusOf(t *testing.T, h http.HandlerFunc) int {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	return rec.Code
+}
+
+// Test readiness against the backlog threshold
+func TestReadiness_Backlog(t *testing.T) {
+	h := newTestHealth(t, HealthConfig{MaxBacklog: 1})
+	h.watcherAlive.Store(true)
+
+	if code := statusOf(t, healthHandler(h.readiness)); code != http.StatusOK {
+		t.Errorf("Expected ready daemon, got %d", code)
+	}
+
+	h.backlog.Add(backlogEntry{Path: "/shared/run1.mcap", Detected: time.Now()})
+	if code := statusOf(t, healthHandler(h.readiness)); code != http.StatusServiceUnavailable {
+		t.Errorf("Expected not ready with full backlog, got %d", code)
+	}
+	if code := statusOf(t, healthHandler(h.liveness)); code != http.StatusOK {
+		t.Errorf("Expected a full backlog to keep the daemon alive, got %d", code)
+	}
+}
+
+// Test that captures waiting in the journal count towards the backlog
+func TestReadiness_Journal(t *testing.T) {
+	h := newTestHealth(t, HealthConfig{MaxBacklog: 2})
+	h.watcherAlive.Store(true)
+
+	h.backlog.Add(backlogEntry{Path: "/shared/run1.mcap", Detected: time.Now()})
+	if code := statusOf(t, healthHandler(h.readiness)); code != http.StatusOK {
+		t.Errorf("Expected ready daemon, got %d", code)
+	}
+
+	if _, err := h.journal.Capture(capture{McapID: "abc", Path: "/shared/run2.mcap", Hash: "0123"}); err != nil {
+		t.Fatal(err)
+	}
+	if code := statusOf(t, healthHandler(h.readiness)); code != http.StatusServiceUnavailable {
+		t.Errorf("Expected not ready with captures waiting in the journal, got %d", code)
+	}
+}
+
+// Test liveness for a stopped watcher and stalled workers
+func TestLiveness(t *testing.T) {
+	h := newTestHealth(t, HealthConfig{StallTimeout: time.Minute})
+
+	if code := statusOf(t, healthHandler(h.liveness)); code != http.StatusServiceUnavailable {
+		t.Errorf("Expected unhealthy daemon without a watcher, got %d", code)
+	}
+
+	h.watcherAlive.Store(true)
+	h.queue <- job{}
+	h.lastProgress.Store(time.Now().Add(-2 * time.Minute).UnixNano())
+	if code := statusOf(t, healthHandler(h.liveness)); code != http.StatusServiceUnavailable {
+		t.Errorf("Expected unhealthy daemon with stalled workers, got %d", code)
+	}
+
+	h.progress()
+	if code := statusOf(t, healthHandler(h.liveness)); code != http.StatusOK {
+		t.Errorf("Expected healthy daemon after progress, got %d", code)
+	}
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// HTTPConfig configures the optional HTTP endpoint used for metrics and
+// container health checks.
+type HTTPConfig struct {
+	// Listen is the address to serve on, for example ":9102". The endpoint
+	// is disabled when empty.
+	Listen string `yaml:"listen"`
+}
+
+// newHTTPServer returns a server exposing /metrics, /healthz and /readyz.
+func newHTTPServer(cfg HTTPConfig, m *metrics, h *health) *http.Server {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry}))
+	mux.Handle("/healthz", healthHandler(h.liveness))
+	mux.Handle("/readyz", healthHandler(h.readiness))
+
+	return &http.Server{
+		Addr:              cfg.Listen,
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+}
@@ -0,0 +1,249 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Sources that can supply the operation ID and project of a recording.
+const (
+	sourceSidecar   = "sidecar"
+	sourceMetadata  = "metadata"
+	sourceDirectory = "directory"
+)
+
+// IdentifiersConfig configures how the operation ID and project of each
+// recording are resolved and validated.
+type IdentifiersConfig struct {
+	// Sources are consulted in order; the first source that supplies a
+	// field wins for that field.
+	Sources []string `yaml:"sources"`
+
+	Sidecar   SidecarConfig   `yaml:"sidecar"`
+	Metadata  MetadataConfig  `yaml:"metadata"`
+	Directory DirectoryConfig `yaml:"directory"`
+
+	// Schema is checked before anything is put on chain.
+	Schema IdentifierSchema `yaml:"schema"`
+}
+
+// SidecarConfig locates sidecar files next to a recording. For run1.mcap
+// with extension ".json" both run1.mcap.json and run1.json are tried.
+type SidecarConfig struct {
+	Extensions []string `yaml:"extensions"`
+}
+
+// MetadataConfig names the MCAP Metadata record and keys to read.
+type MetadataConfig struct {
+	Name         string `yaml:"name"`
+	OperationKey string `yaml:"operationKey"`
+	ProjectKey   string `yaml:"projectKey"`
+}
+
+// DirectoryConfig extracts identifiers from the path relative to the watch
+// directory with a regular expression using the named groups "operation"
+// and "project".
+type DirectoryConfig struct {
+	Pattern string `yaml:"pattern"`
+}
+
+// IdentifierSchema holds the rules for each identifier.
+type IdentifierSchema struct {
+	Operation FieldSchema `yaml:"operation"`
+	Project   FieldSchema `yaml:"project"`
+}
+
+// FieldSchema constrains a single identifier value.
+type FieldSchema struct {
+	Required  bool     `yaml:"required"`
+	Pattern   string   `yaml:"pattern"`
+	MaxLength int      `yaml:"maxLength"`
+	Enum      []string `yaml:"enum"`
+}
+
+// sidecarFile is the content of a JSON or YAML sidecar.
+type sidecarFile struct {
+	Operation string `yaml:"operation"`
+	Project   string `yaml:"project"`
+
+	// Sensors holds summarised sensor readings by name.
+	Sensors map[string]sidecarReading `yaml:"sensors"`
+}
+
+func defaultIdentifiersConfig() IdentifiersConfig {
+	safe := `^[A-Za-z0-9][A-Za-z0-9._:/-]*$`
+	return IdentifiersConfig{
+		Sources: []string{sourceSidecar, sourceMetadata, sourceDirectory},
+		Sidecar: SidecarConfig{Extensions: []string{".json", ".yaml", ".yml"}},
+		Metadata: MetadataConfig{
+			Name:         "anchor",
+			OperationKey: "operation",
+			ProjectKey:   "project",
+		},
+		Schema: IdentifierSchema{
+			Operation: FieldSchema{Pattern: safe, MaxLength: 128},
+			Project:   FieldSchema{Pattern: safe, MaxLength: 128},
+		},
+	}
+}
+
+// identifierResolver fills in the operation ID and project of recordings.
+type identifierResolver struct {
+	cfg       IdentifiersConfig
+	directory *regexp.Regexp
+	operation fieldValidator
+	project   fieldValidator
+}
+
+func newIdentifierResolver(cfg IdentifiersConfig) (*identifierResolver, error) {
+	r := &identifierResolver{cfg: cfg}
+
+	for _, source := range cfg.Sources {
+		switch source {
+		case sourceSidecar, sourceMetadata:
+		case sourceDirectory:
+			if cfg.Directory.Pattern == "" {
+				continue
+			}
+			re, err := regexp.Compile(cfg.Directory.Pattern)
+			if err != nil {
+				return nil, fmt.Errorf("invalid directory pattern: %w", err)
+			}
+			if re.SubexpIndex("operation") < 0 && re.SubexpIndex("project") < 0 {
+				return nil, errors.New("directory pattern needs an (?P<operation>) or (?P<project>) group")
+			}
+			r.directory = re
+		default:
+			return nil, fmt.Errorf("unknown identifier source %q", source)
+		}
+	}
+
+	var err error
+	if r.operation, err = newFieldValidator("operation", cfg.Schema.Operation); err != nil {
+		return nil, err
+	}
+	if r.project, err = newFieldValidator("project", cfg.Schema.Project); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Resolve sets rec.Operation and rec.Project from the configured sources and
+// validates the result.
+func (r *identifierResolver) Resolve(rec *recording) error {
+	for _, source := range r.cfg.Sources {
+		if rec.Operation != "" && rec.Project != "" {
+			break
+		}
+
+		var operation, project string
+		switch source {
+		case sourceSidecar:
+			sidecar, err := readSidecar(rec.Path, r.cfg.Sidecar.Extensions)
+			if err != nil {
+				return err
+			}
+			operation, project = sidecar.Operation, sidecar.Project
+		case sourceMetadata:
+			md := rec.Metadata[r.cfg.Metadata.Name]
+			operation, project = md[r.cfg.Metadata.OperationKey], md[r.cfg.Metadata.ProjectKey]
+		case sourceDirectory:
+			operation, project = r.matchDirectory(rec.RelPath)
+		}
+
+		if rec.Operation == "" {
+			rec.Operation = strings.TrimSpace(operation)
+		}
+		if rec.Project == "" {
+			rec.Project = strings.TrimSpace(project)
+		}
+	}
+
+	return errors.Join(r.operation.validate(rec.Operation), r.project.validate(rec.Project))
+}
+
+// readSidecar returns the first sidecar with one of extensions found next
+// to filePath. A missing sidecar is not an error.
+func readSidecar(filePath string, extensions []string) (sidecarFile, error) {
+	var sidecar sidecarFile
+	stem := strings.TrimSuffix(filePath, filepath.Ext(filePath))
+	for _, ext := range extensions {
+		for _, candidate := range []string{filePath + ext, stem + ext} {
+			data, err := os.ReadFile(filepath.Clean(candidate))
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			if err != nil {
+				return sidecar, fmt.Errorf("failed to read sidecar: %w", err)
+			}
+			// YAML is a superset of JSON, so one decoder handles both.
+			if err := yaml.Unmarshal(data, &sidecar); err != nil {
+				return sidecar, fmt.Errorf("failed to parse sidecar %s: %w", candidate, err)
+			}
+			return sidecar, nil
+		}
+	}
+	return sidecar, nil
+}
+
+func (r *identifierResolver) matchDirectory(relPath string) (operation string, project string) {
+	if r.directory == nil {
+		return "", ""
+	}
+	match := r.directory.FindStringSubmatch(relPath)
+	if match == nil {
+		return "", ""
+	}
+	if i := r.directory.SubexpIndex("operation"); i >= 0 {
+		operation = match[i]
+	}
+	if i := r.directory.SubexpIndex("project"); i >= 0 {
+		project = match[i]
+	}
+	return operation, project
+}
+
+// fieldValidator is a compiled FieldSchema.
+type fieldValidator struct {
+	name    string
+	schema  FieldSchema
+	pattern *regexp.Regexp
+}
+
+func newFieldValidator(name string, schema FieldSchema) (fieldValidator, error) {
+	v := fieldValidator{name: name, schema: schema}
+	if schema.Pattern != "" {
+		re, err := regexp.Compile(schema.Pattern)
+		if err != nil {
+			return v, fmt.Errorf("invalid %s pattern: %w", name, err)
+		}
+		v.pattern = re
+	}
+	return v, nil
+}
+
+func (v fieldValidator) validate(value string) error {
+	if value == "" {
+		if v.schema.Required {
+			return fmt.Errorf("%s is required", v.name)
+		}
+		return nil
+	}
+	if v.schema.MaxLength > 0 && len(value) > v.schema.MaxLength {
+		return fmt.Errorf("%s %q is longer than %d characters", v.name, value, v.schema.MaxLength)
+	}
+	if v.pattern != nil && !v.pattern.MatchString(value) {
+		return fmt.Errorf("%s %q does not match %s", v.name, value, v.schema.Pattern)
+	}
+	if len(v.schema.Enum) > 0 && !slices.Contains(v.schema.Enum, value) {
+		return fmt.Errorf("%s %q is not one of %v", v.name, value, v.schema.Enum)
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// Test precedence of sidecar over metadata over directory rules
+func TestResolveIdentifiers(t *testing.T) {
+	watchDir := t.TempDir()
+	cellDir := filepath.Join(watchDir, "ARP", "op-dir")
+	if err := os.MkdirAll(cellDir, 0o750); err != nil {
+		t.Fatalf("Failed to create directories: %v", err)
+	}
+	filePath := filepath.Join(cellDir, "run1.mcap")
+	if err := os.WriteFile(filepath.Join(cellDir, "run1.yaml"), []byte("operation: op-sidecar\n"), 0o600); err != nil {
+		t.Fatalf("Failed to create sidecar: %v", err)
+	}
+
+	cfg := defaultIdentifiersConfig()
+	cfg.Directory.Pattern = `^(?P<project>[^/]+)/(?P<operation>[^/]+)/`
+	resolver, err := newIdentifierResolver(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create resolver: %v", err)
+	}
+
+	rec := newRecording(watchDir, filePath)
+	rec.Metadata = map[string]map[string]string{"anchor": {"project": "line-7"}}
+	if err := resolver.Resolve(&rec); err != nil {
+		t.Fatalf("Error resolving identifiers: %v", err)
+	}
+	if rec.Operation != "op-sidecar" {
+		t.Errorf("Expected operation from sidecar, got %q", rec.Operation)
+	}
+	if rec.Project != "line-7" {
+		t.Errorf("Expected project from metadata, got %q", rec.Project)
+	}
+
+	rec = newRecording(watchDir, filepath.Join(cellDir, "run2.mcap"))
+	if err := resolver.Resolve(&rec); err != nil {
+		t.Fatalf("Error resolving identifiers: %v", err)
+	}
+	if rec.Operation != "op-dir" || rec.Project != "ARP" {
+		t.Errorf("Expected identifiers from directory, got %q/%q", rec.Operation, rec.Project)
+	}
+}
+
+// Test schema validation of resolved values
+func TestResolveIdentifiers_Schema(t *testing.T) {
+	cfg := defaultIdentifiersConfig()
+	cfg.Schema.Operation.Required = true
+	cfg.Schema.Project.Enum = []string{"ARP"}
+	resolver, err := newIdentifierResolver(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create resolver: %v", err)
+	}
+
+	rec := newRecording(t.TempDir(), "run.mcap")
+	if err := resolver.Resolve(&rec); err == nil {
+		t.Errorf("Expected error for missing required operation")
+	}
+
+	rec.Metadata = map[string]map[string]string{"anchor": {"operation": "op1", "project": "other"}}
+	if err := resolver.Resolve(&rec); err == nil {
+		t.Errorf("Expected error for project outside enum")
+	}
+
+	rec = newRecording(t.TempDir(), "run.mcap")
+	rec.Metadata = map[string]map[string]string{"anchor": {"operation": "op1; DROP", "project": "ARP"}}
+	if err := resolver.Resolve(&rec); err == nil {
+		t.Errorf("Expected error for operation not matching pattern")
+	}
+}
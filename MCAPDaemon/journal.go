@@ -0,0 +1,386 @@
+package main
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Octavian-Anghel/Capstone-Project/hashlib"
+	"github.com/Octavian-Anghel/Capstone-Project/ledger"
+)
+
+// JournalConfig controls the offline journal.
+type JournalConfig struct {
+	// ReconcileInterval is how often captured recordings that could not be
+	// anchored are retried.
+	ReconcileInterval time.Duration `yaml:"reconcileInterval"`
+}
+
+// Journal record kinds.
+const (
+	recordCapture = "capture"
+	recordAnchor  = "anchor"
+	recordAbandon = "abandon"
+)
+
+// journalRecord is one line of the journal. Records form a hash chain:
+// Prev is the Hash of the record before, and Hash covers every other field
+// including the client's signature, so editing, removing or reordering
+// records is detected when the journal is next opened.
+type journalRecord struct {
+	Seq  uint64    `json:"seq"`
+	Kind string    `json:"kind"`
+	Prev string    `json:"prev"`
+	Time time.Time `json:"time"`
+
+	// Capture is set on capture records.
+	Capture *capture `json:"capture,omitempty"`
+
+	// Ref is the Seq of the capture an anchor or abandon record settles.
+	Ref         uint64 `json:"ref,omitempty"`
+	TxID        string `json:"txId,omitempty"`
+	BlockNumber uint64 `json:"blockNumber,omitempty"`
+	Reason      string `json:"reason,omitempty"`
+
+	// Signer is the SHA-256 fingerprint of the signing certificate, which
+	// is included in full whenever it differs from the previous record's.
+	MSPID       string `json:"mspId"`
+	Signer      string `json:"signer"`
+	Certificate string `json:"certificate,omitempty"`
+	Signature   []byte `json:"signature"`
+
+	Hash string `json:"hash"`
+}
+
+// capture is a recording hashed by the daemon, with everything needed to
+// anchor it later.
+type capture struct {
+	McapID    string `json:"mcapId"`
+	Path      string `json:"path"`
+	Hash      string `json:"hash"`
+	Operation string `json:"operation"`
+	Project   string `json:"project"`
+	Size      int64  `json:"size"`
+
+	// Algorithm is the digest algorithm of Hash. Records written before
+	// it was kept leave it empty for the default.
+	Algorithm string                `json:"algorithm,omitempty"`
+	Sensors   *ledger.SensorSummary `json:"sensors,omitempty"`
+
+	// ChunkRoot is the root of the recording's chunk tree, empty when it
+	// has no chunk index.
+	ChunkRoot string `json:"chunkRoot,omitempty"`
+}
+
+// algorithm returns the identifier of the algorithm Hash was computed with.
+func (c capture) algorithm() string {
+	if c.Algorithm == "" {
+		return hashlib.DefaultAlgorithm
+	}
+	return c.Algorithm
+}
+
+// journalSigner signs journal records. *wallet.Identity implements it.
+type journalSigner interface {
+	MspID() string
+	Certificate() *x509.Certificate
+	Sign(digest []byte) ([]byte, error)
+}
+
+// journal is an append-only, signed log of every captured recording and
+// its fate. Recordings are captured before they are submitted, so the
+// daemon keeps working while the Fabric network is unreachable and anchors
+// the backlog of captures once it is back.
+type journal struct {
+	mu     sync.Mutex
+	path   string
+	file   *os.File
+	signer journalSigner
+
+	last     journalRecord
+	pending  map[uint64]journalRecord
+	inflight map[uint64]bool
+
+	// anchored maps the path and hash of every anchored recording to its
+	// asset ID.
+	anchored map[[2]string]string
+}
+
+// openJournal verifies the journal at journalPath and opens it for
+// appending. A record torn by a crash mid-write is dropped; any other
+// inconsistency is reported as an error.
+func openJournal(journalPath string, signer journalSigner, logger *slog.Logger) (*journal, error) {
+	j := &journal{
+		path:     filepath.Clean(journalPath),
+		signer:   signer,
+		pending:  make(map[uint64]journalRecord),
+		inflight: make(map[uint64]bool),
+		anchored: make(map[[2]string]string),
+	}
+
+	data, err := os.ReadFile(j.path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read journal: %w", err)
+	}
+	if n := bytes.LastIndexByte(data, '\n') + 1; n < len(data) {
+		logger.Warn("dropping incomplete journal record", "journal", j.path, "bytes", len(data)-n)
+		data = data[:n]
+		if err := os.Truncate(j.path, int64(n)); err != nil {
+			return nil, fmt.Errorf("failed to truncate journal: %w", err)
+		}
+	}
+
+	var cert *x509.Certificate
+	for _, line := range bytes.Split(bytes.TrimSuffix(data, []byte("\n")), []byte("\n")) {
+		if len(line) == 0 {
+			continue
+		}
+		var r journalRecord
+		if err := json.Unmarshal(line, &r); err != nil {
+			return nil, fmt.Errorf("journal record after %d is corrupt: %w", j.last.Seq, err)
+		}
+		if cert, err = j.verify(r, cert); err != nil {
+			return nil, fmt.Errorf("journal record %d: %w", r.Seq, err)
+		}
+		j.apply(r)
+	}
+
+	j.file, err = os.OpenFile(j.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open journal: %w", err)
+	}
+	return j, nil
+}
+
+// verify checks r against the chain so far and returns the certificate
+// that signed it.
+func (j *journal) verify(r journalRecord, cert *x509.Certificate) (*x509.Certificate, error) {
+	if r.Seq != j.last.Seq+1 || r.Prev != j.last.Hash {
+		return nil, fmt.Errorf("breaks the hash chain after record %d", j.last.Seq)
+	}
+	if r.Certificate != "" {
+		block, _ := pem.Decode([]byte(r.Certificate))
+		if block == nil {
+			return nil, fmt.Errorf("has an invalid certificate")
+		}
+		var err error
+		if cert, err = x509.ParseCertificate(block.Bytes); err != nil {
+			return nil, fmt.Errorf("has an invalid certificate: %w", err)
+		}
+	}
+	if cert == nil || fingerprint(cert) != r.Signer {
+		return nil, fmt.Errorf("was signed by an unknown certificate %s", r.Signer)
+	}
+
+	signed, digest, err := r.digests()
+	if err != nil {
+		return nil, err
+	}
+	if hex.EncodeToString(digest) != r.Hash {
+		return nil, fmt.Errorf("does not match its hash")
+	}
+	public, ok := cert.PublicKey.(*ecdsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("was signed with an unsupported %T key", cert.PublicKey)
+	}
+	if !ecdsa.VerifyASN1(public, signed, r.Signature) {
+		return nil, fmt.Errorf("has an invalid signature")
+	}
+	return cert, nil
+}
+
+// digests returns the digest that is signed, over the record without its
+// signature and hash, and the record hash, over everything but the hash.
+func (r journalRecord) digests() (signed []byte, hash []byte, err error) {
+	r.Hash = ""
+	signature := r.Signature
+	r.Signature = nil
+	b, err := json.Marshal(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	s := sha256.Sum256(b)
+
+	r.Signature = signature
+	if b, err = json.Marshal(r); err != nil {
+		return nil, nil, err
+	}
+	h := sha256.Sum256(b)
+	return s[:], h[:], nil
+}
+
+// apply updates the in-memory view of pending captures with r.
+func (j *journal) apply(r journalRecord) {
+	switch r.Kind {
+	case recordCapture:
+		j.pending[r.Seq] = r
+	case recordAnchor:
+		if p, ok := j.pending[r.Ref]; ok {
+			j.anchored[[2]string{p.Capture.Path, p.Capture.Hash}] = p.Capture.McapID
+		}
+		delete(j.pending, r.Ref)
+	case recordAbandon:
+		delete(j.pending, r.Ref)
+	}
+	j.last = r
+}
+
+// append signs r, chains it to the previous record and writes it to disk.
+// j.mu must be held.
+func (j *journal) append(r journalRecord) (journalRecord, error) {
+	cert := j.signer.Certificate()
+	r.Seq = j.last.Seq + 1
+	r.Prev = j.last.Hash
+	r.Time = time.Now().UTC()
+	r.MSPID = j.signer.MspID()
+	r.Signer = fingerprint(cert)
+	if r.Signer != j.last.Signer {
+		r.Certificate = string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw}))
+	}
+
+	signed, _, err := r.digests()
+	if err != nil {
+		return r, err
+	}
+	if r.Signature, err = j.signer.Sign(signed); err != nil {
+		return r, fmt.Errorf("failed to sign journal record: %w", err)
+	}
+	_, hash, err := r.digests()
+	if err != nil {
+		return r, err
+	}
+	r.Hash = hex.EncodeToString(hash)
+
+	line, err := json.Marshal(r)
+	if err != nil {
+		return r, err
+	}
+	if _, err := j.file.Write(append(line, '\n')); err != nil {
+		return r, fmt.Errorf("failed to write journal: %w", err)
+	}
+	if err := j.file.Sync(); err != nil {
+		return r, fmt.Errorf("failed to sync journal: %w", err)
+	}
+	j.apply(r)
+	return r, nil
+}
+
+// Capture records a hashed recording and returns its record. The capture
+// is claimed for the caller, who must call Anchored, Abandon or Release.
+func (j *journal) Capture(c capture) (journalRecord, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	r, err := j.append(journalRecord{Kind: recordCapture, Capture: &c})
+	if err == nil {
+		j.inflight[r.Seq] = true
+	}
+	return r, err
+}
+
+// Anchored records that capture ref was committed in txID.
+func (j *journal) Anchored(ref uint64, txID string, blockNumber uint64) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	delete(j.inflight, ref)
+	_, err := j.append(journalRecord{Kind: recordAnchor, Ref: ref, TxID: txID, BlockNumber: blockNumber})
+	return err
+}
+
+// Abandon records that capture ref will never be anchored.
+func (j *journal) Abandon(ref uint64, reason string) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	delete(j.inflight, ref)
+	_, err := j.append(journalRecord{Kind: recordAbandon, Ref: ref, Reason: reason})
+	return err
+}
+
+// Claim reserves pending capture ref so it is anchored only once at a time.
+func (j *journal) Claim(ref uint64) bool {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if _, ok := j.pending[ref]; !ok || j.inflight[ref] {
+		return false
+	}
+	j.inflight[ref] = true
+	return true
+}
+
+// Release gives up a claim on a capture that is still pending.
+func (j *journal) Release(ref uint64) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	delete(j.inflight, ref)
+}
+
+// Pending returns the captures not yet anchored or abandoned, oldest first.
+func (j *journal) Pending() []journalRecord {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	records := make([]journalRecord, 0, len(j.pending))
+	for _, r := range j.pending {
+		records = append(records, r)
+	}
+	sort.Slice(records, func(a, b int) bool {
+		return records[a].Seq < records[b].Seq
+	})
+	return records
+}
+
+// PendingFor returns the pending capture of filePath, if there is one.
+func (j *journal) PendingFor(filePath string) (journalRecord, bool) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	for _, r := range j.pending {
+		if r.Capture.Path == filePath {
+			return r, true
+		}
+	}
+	return journalRecord{}, false
+}
+
+// AnchoredAs returns the asset ID the recording at filePath with the given
+// hash was anchored under, if it has been.
+func (j *journal) AnchoredAs(filePath string, hash string) (string, bool) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	mcapID, ok := j.anchored[[2]string{filePath, hash}]
+	return mcapID, ok
+}
+
+// Len returns the number of pending captures.
+func (j *journal) Len() int {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return len(j.pending)
+}
+
+// Close closes the journal file.
+func (j *journal) Close() error {
+	return j.file.Close()
+}
+
+// fingerprint is the hex SHA-256 of a certificate.
+func fingerprint(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.Raw)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"io"
+	"log/slog"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testSigner signs journal records with a throwaway key.
+type testSigner struct {
+	key  *ecdsa.PrivateKey
+	cert *x509.Certificate
+}
+
+func newTestSigner(t *testing.T) *testSigner {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "recorder-01"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testSigner{key: key, cert: cert}
+}
+
+func (s *testSigner) MspID() string                  { return "Org1MSP" }
+func (s *testSigner) Certificate() *x509.Certificate { return s.cert }
+func (s *testSigner) Sign(digest []byte) ([]byte, error) {
+	return ecdsa.SignASN1(rand.Reader, s.key, digest)
+}
+
+func openTestJournal(t *testing.T, path string, signer journalSigner) *journal {
+	t.Helper()
+	j, err := openJournal(path, signer, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatalf("Failed to open journal: %v", err)
+	}
+	t.Cleanup(func() { j.Close() })
+	return j
+}
+
+// Test that captures stay pending until anchored, across a reopen and a
+// change of signing certificate
+func TestJournal_Pending(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.jsonl")
+	j := openTestJournal(t, path, newTestSigner(t))
+
+	first, err := j.Capture(capture{McapID: "a", Path: "/shared/a.mcap", Hash: "aa"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := j.Capture(capture{McapID: "b", Path: "/shared/b.mcap", Hash: "bb"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := j.Anchored(first.Seq, "tx1", 7); err != nil {
+		t.Fatal(err)
+	}
+	j.Close()
+
+	rotated := openTestJournal(t, path, newTestSigner(t))
+	pending := rotated.Pending()
+	if len(pending) != 1 || pending[0].Capture.McapID != "b" {
+		t.Fatalf("Expected b to be pending, got %+v", pending)
+	}
+	if _, ok := rotated.PendingFor("/shared/b.mcap"); !ok {
+		t.Errorf("Expected pending capture for b.mcap")
+	}
+	if mcapID, ok := rotated.AnchoredAs("/shared/a.mcap", "aa"); !ok || mcapID != "a" {
+		t.Errorf("Expected a.mcap to be anchored as a, got %q", mcapID)
+	}
+	if _, ok := rotated.AnchoredAs("/shared/b.mcap", "bb"); ok {
+		t.Errorf("Expected b.mcap not to be anchored")
+	}
+	if !rotated.Claim(pending[0].Seq) || rotated.Claim(pending[0].Seq) {
+		t.Errorf("Expected a pending capture to be claimed exactly once")
+	}
+	if err := rotated.Abandon(pending[0].Seq, "rejected"); err != nil {
+		t.Fatal(err)
+	}
+	rotated.Close()
+
+	reopened := openTestJournal(t, path, newTestSigner(t))
+	if reopened.Len() != 0 {
+		t.Errorf("Expected no pending captures, got %d", reopened.Len())
+	}
+}
+
+// Test that edited records are detected and torn writes are dropped
+func TestJournal_Tamper(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.jsonl")
+	signer := newTestSigner(t)
+	j := openTestJournal(t, path, signer)
+	for _, id := range []string{"a", "b"} {
+		if _, err := j.Capture(capture{McapID: id, Path: "/shared/" + id + ".mcap", Hash: "00"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	j.Close()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	torn := append(bytes.Clone(data), []byte(`{"seq":3,"kind":"cap`)...)
+	if err := os.WriteFile(path, torn, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if j := openTestJournal(t, path, signer); j.Len() != 2 {
+		t.Errorf("Expected torn record to be dropped, got %d pending", j.Len())
+	}
+
+	tampered := bytes.Replace(data, []byte(`"hash":"00"`), []byte(`"hash":"ff"`), 1)
+	if err := os.WriteFile(path, tampered, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := openJournal(path, signer, slog.New(slog.NewTextHandler(io.Discard, nil))); err == nil || !strings.Contains(err.Error(), "record 1") {
+		t.Errorf("Expected edited record 1 to be detected, got %v", err)
+	}
+
+	lines := bytes.SplitAfter(data, []byte("\n"))
+	if err := os.WriteFile(path, lines[1], 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := openJournal(path, signer, slog.New(slog.NewTextHandler(io.Discard, nil))); err == nil {
+		t.Errorf("Expected a removed record to break the chain")
+	}
+}
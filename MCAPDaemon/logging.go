@@ -0,0 +1,52 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+)
+
+// LogConfig configures the daemon logger.
+type LogConfig struct {
+	// Level is one of "debug", "info", "warn" or "error".
+	Level string `yaml:"level"`
+
+	// Format is "text" for humans or "json" for log shippers.
+	Format string `yaml:"format"`
+}
+
+// logLevel is the level of the process-wide logger, which a config reload
+// may change.
+var logLevel = new(slog.LevelVar)
+
+// newLogger builds the process-wide logger described by cfg.
+func newLogger(cfg LogConfig, w io.Writer) (*slog.Logger, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(cfg.Level)); err != nil {
+		return nil, fmt.Errorf("invalid log level %q", cfg.Level)
+	}
+	logLevel.Set(level)
+	opts := &slog.HandlerOptions{Level: logLevel}
+
+	switch strings.ToLower(cfg.Format) {
+	case "", "text":
+		return slog.New(slog.NewTextHandler(w, opts)), nil
+	case "json":
+		return slog.New(slog.NewJSONHandler(w, opts)), nil
+	default:
+		return nil, fmt.Errorf("invalid log format %q", cfg.Format)
+	}
+}
+
+// newCorrelationID returns a short random ID that tags every log line for a
+// single recording, from detection through hashing to the Fabric txID.
+func newCorrelationID() string {
+	var b [8]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "unknown"
+	}
+	return hex.EncodeToString(b[:])
+}
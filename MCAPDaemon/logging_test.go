@@ -0,0 +1,37 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+// Test JSON output and level filtering
+func TestNewLogger_JSON(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := newLogger(LogConfig{Level: "warn", Format: "json"}, &buf)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	logger.Info("dropped")
+	logger.With("cid", "abc").Warn("kept", "txId", "tx1")
+
+	var line map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &line); err != nil {
+		t.Fatalf("Expected a single JSON line, got %q: %v", buf.String(), err)
+	}
+	if line["msg"] != "kept" || line["cid"] != "abc" || line["txId"] != "tx1" {
+		t.Errorf("Unexpected log line: %v", line)
+	}
+}
+
+// Test rejection of unknown levels and formats
+func TestNewLogger_Invalid(t *testing.T) {
+	if _, err := newLogger(LogConfig{Level: "loud"}, &bytes.Buffer{}); err == nil {
+		t.Errorf("Expected error for unknown level")
+	}
+	if _, err := newLogger(LogConfig{Level: "info", Format: "xml"}, &bytes.Buffer{}); err == nil {
+		t.Errorf("Expected error for unknown format")
+	}
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"errors"
+	"time"
+
+	"github.com/Octavian-Anghel/Capstone-Project/ledger"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"google.golang.org/grpc/status"
+)
+
+// metrics are the Prometheus collectors exported on /metrics.
+type metrics struct {
+	registry *prometheus.Registry
+
+	filesDetected  prometheus.Counter
+	filesValidated prometheus.Counter
+	filesRejected  *prometheus.CounterVec
+	filesAnchored  prometheus.Counter
+
+	hashBytes      prometheus.Counter
+	hashThroughput prometheus.Gauge
+	hashDuration   prometheus.Histogram
+	submitDuration prometheus.Histogram
+
+	txFailures *prometheus.CounterVec
+	failovers  prometheus.Counter
+	queueDepth *prometheus.GaugeVec
+}
+
+func newMetrics() *metrics {
+	m := &metrics{
+		registry: prometheus.NewRegistry(),
+		filesDetected: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "mcapdaemon_files_detected_total",
+			Help: "MCAP files seen by the watcher.",
+		}),
+		filesValidated: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "mcapdaemon_files_validated_total",
+			Help: "MCAP files that passed magic and identifier checks.",
+		}),
+		filesRejected: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "mcapdaemon_files_rejected_total",
+			Help: "MCAP files rejected before anchoring, by reason.",
+		}, []string{"reason"}),
+		filesAnchored: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "mcapdaemon_files_anchored_total",
+			Help: "MCAP files whose hash was committed to the ledger.",
+		}),
+		hashBytes: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "mcapdaemon_hash_bytes_total",
+			Help: "Bytes hashed. rate() of this gives hash throughput.",
+		}),
+		hashThroughput: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "mcapdaemon_hash_throughput_bytes_per_second",
+			Help: "Throughput of the most recently hashed file.",
+		}),
+		hashDuration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:    "mcapdaemon_hash_duration_seconds",
+			Help:    "Time taken to hash a file.",
+			Buckets: prometheus.ExponentialBuckets(0.01, 2, 16),
+		}),
+		submitDuration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:    "mcapdaemon_submit_duration_seconds",
+			Help:    "Time from proposal to commit for CreateAsset transactions.",
+			Buckets: prometheus.ExponentialBuckets(0.05, 2, 12),
+		}),
+		txFailures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "mcapdaemon_transaction_failures_total",
+			Help: "Failed transactions by stage and gRPC status or validation code.",
+		}, []string{"stage", "code"}),
+		failovers: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "mcapdaemon_gateway_failovers_total",
+			Help: "Times traffic moved from one gateway peer to another.",
+		}),
+		queueDepth: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "mcapdaemon_queue_depth",
+			Help: "Files waiting in each daemon queue.",
+		}, []string{"queue"}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.filesDetected,
+		m.filesValidated,
+		m.filesRejected,
+		m.filesAnchored,
+		m.hashBytes,
+		m.hashThroughput,
+		m.hashDuration,
+		m.submitDuration,
+		m.txFailures,
+		m.failovers,
+		m.queueDepth,
+	)
+	return m
+}
+
+// observeHash records a completed hash of size bytes.
+func (m *metrics) observeHash(size int64, elapsed time.Duration) {
+	m.hashBytes.Add(float64(size))
+	m.hashDuration.Observe(elapsed.Seconds())
+	if elapsed > 0 {
+		m.hashThroughput.Set(float64(size) / elapsed.Seconds())
+	}
+}
+
+// observeTxFailure counts a failed transaction under the gateway stage that
+// produced err.
+func (m *metrics) observeTxFailure(err error) {
+	m.txFailures.WithLabelValues(failureLabels(err)).Inc()
+}
+
+// failureLabels maps a gateway error to its stage and code labels.
+func failureLabels(err error) (stage string, code string) {
+	var ledgerErr *ledger.Error
+	if !errors.As(err, &ledgerErr) {
+		return "other", status.Code(err).String()
+	}
+	if ledgerErr.Stage == ledger.StageCommit {
+		return string(ledgerErr.Stage), ledgerErr.ValidationCode.String()
+	}
+	return string(ledgerErr.Stage), ledgerErr.Code.String()
+}
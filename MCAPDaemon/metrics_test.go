@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Octavian-Anghel/Capstone-Project/ledger"
+	"github.com/hyperledger/fabric-protos-go-apiv2/peer"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Test stage and code labels for gateway errors
+func TestFailureLabels(t *testing.T) {
+	commitErr := &ledger.Error{Stage: ledger.StageCommit, TxID: "tx1", ValidationCode: peer.TxValidationCode_MVCC_READ_CONFLICT}
+	stage, code := failureLabels(fmt.Errorf("wrapped: %w", commitErr))
+	if stage != "commit" || code != "MVCC_READ_CONFLICT" {
+		t.Errorf("Unexpected labels for commit error: %s/%s", stage, code)
+	}
+
+	endorseErr := &ledger.Error{Stage: ledger.StageEndorse, Code: codes.Unavailable, Err: status.Error(codes.Unavailable, "peer down")}
+	stage, code = failureLabels(endorseErr)
+	if stage != "endorse" || code != "Unavailable" {
+		t.Errorf("Unexpected labels for gRPC error: %s/%s", stage, code)
+	}
+}
+
+// Test that the metrics endpoint serves the daemon collectors
+func TestMetricsEndpoint(t *testing.T) {
+	m := newMetrics()
+	m.filesDetected.Inc()
+	m.observeHash(1<<20, 500*time.Millisecond)
+	m.queueDepth.WithLabelValues("process").Set(3)
+
+	server := httptest.NewServer(newHTTPServer(HTTPConfig{}, m, nil).Handler)
+	defer server.Close()
+
+	resp, err := server.Client().Get(server.URL + "/metrics")
+	if err != nil {
+		t.Fatalf("Failed to scrape metrics: %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+
+	for _, want := range []string{
+		"mcapdaemon_files_detected_total 1",
+		"mcapdaemon_hash_bytes_total 1.048576e+06",
+		"mcapdaemon_hash_throughput_bytes_per_second 2.097152e+06",
+		`mcapdaemon_queue_depth{queue="process"} 3`,
+	} {
+		if !strings.Contains(string(body), want) {
+			t.Errorf("Expected %q in metrics output", want)
+		}
+	}
+}
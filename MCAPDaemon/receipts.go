@@ -0,0 +1,62 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ReceiptsConfig controls where transaction receipts are written.
+type ReceiptsConfig struct {
+	// Dir is the receipts store. When empty each receipt is written next to
+	// its recording as <file>.receipt.json.
+	Dir string `yaml:"dir"`
+}
+
+// receipt is the local proof that a recording was anchored.
+type receipt struct {
+	McapID         string    `json:"mcapId"`
+	Path           string    `json:"path"`
+	Hash           string    `json:"hash"`
+	Algorithm      string    `json:"algorithm"`
+	ChunkRoot      string    `json:"chunkRoot,omitempty"`
+	TxID           string    `json:"txId"`
+	BlockNumber    uint64    `json:"blockNumber"`
+	ValidationCode string    `json:"validationCode"`
+	Peer           string    `json:"peer"`
+	Channel        string    `json:"channel"`
+	Chaincode      string    `json:"chaincode"`
+	JournalSeq     uint64    `json:"journalSeq"`
+	Captured       time.Time `json:"captured"`
+	Committed      time.Time `json:"committed"`
+}
+
+// receiptPath returns where the receipt for r is stored.
+func (cfg ReceiptsConfig) receiptPath(r receipt) string {
+	if cfg.Dir == "" {
+		return r.Path + ".receipt.json"
+	}
+	// Asset IDs may contain path separators, e.g. the hostpath scheme.
+	name := strings.NewReplacer("/", "_", "\\", "_", ":", "_").Replace(r.McapID)
+	return filepath.Join(cfg.Dir, name+".json")
+}
+
+// writeReceipt stores r and returns the path it was written to.
+func (cfg ReceiptsConfig) writeReceipt(r receipt) (string, error) {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	receiptPath := filepath.Clean(cfg.receiptPath(r))
+	if err := os.MkdirAll(filepath.Dir(receiptPath), 0o750); err != nil {
+		return "", fmt.Errorf("failed to create receipts directory: %w", err)
+	}
+	if err := os.WriteFile(receiptPath, data, 0o600); err != nil {
+		return "", fmt.Errorf("failed to write receipt: %w", err)
+	}
+	return receiptPath, nil
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// Test receipts written next to the recording and into a receipts store
+func TestWriteReceipt(t *testing.T) {
+	watchDir := t.TempDir()
+	r := receipt{
+		McapID:         "recorder-01:cell7/run1.mcap",
+		Path:           filepath.Join(watchDir, "run1.mcap"),
+		TxID:           "tx1",
+		BlockNumber:    42,
+		ValidationCode: "VALID",
+	}
+
+	beside, err := ReceiptsConfig{}.writeReceipt(r)
+	if err != nil {
+		t.Fatalf("Error writing receipt: %v", err)
+	}
+	if beside != r.Path+".receipt.json" {
+		t.Errorf("Expected receipt next to the recording, got %s", beside)
+	}
+
+	storeDir := filepath.Join(t.TempDir(), "receipts")
+	stored, err := ReceiptsConfig{Dir: storeDir}.writeReceipt(r)
+	if err != nil {
+		t.Fatalf("Error writing receipt: %v", err)
+	}
+	if stored != filepath.Join(storeDir, "recorder-01_cell7_run1.mcap.json") {
+		t.Errorf("Unexpected receipt path %s", stored)
+	}
+
+	data, err := os.ReadFile(stored)
+	if err != nil {
+		t.Fatalf("Failed to read receipt: %v", err)
+	}
+	var got receipt
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("Failed to parse receipt: %v", err)
+	}
+	if got.TxID != "tx1" || got.BlockNumber != 42 || got.ValidationCode != "VALID" {
+		t.Errorf("Unexpected receipt content: %+v", got)
+	}
+}
@@ -0,0 +1,231 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+
+	"github.com/Octavian-Anghel/Capstone-Project/ledger"
+	"github.com/Octavian-Anghel/Capstone-Project/mcap"
+)
+
+// Sources that can supply the sensor summary of a recording.
+const (
+	sensorSourceSidecar  = "sidecar"
+	sensorSourceMessages = "messages"
+)
+
+// SensorsConfig selects the sensor readings summarised for each recording
+// and put on the ledger with it.
+type SensorsConfig struct {
+	// Sources are consulted in order; the first source that supplies any
+	// reading provides the whole summary.
+	Sources []string `yaml:"sources"`
+
+	// Readings are the values summarised. The messages source needs them;
+	// the sidecar source keeps only these names when any are listed.
+	Readings []SensorConfig `yaml:"readings"`
+}
+
+// SensorConfig names one sensor value and where it is found in the
+// recording's messages.
+type SensorConfig struct {
+	Name  string `yaml:"name"`
+	Topic string `yaml:"topic"`
+
+	// Field is a dotted path to a number within each JSON or ROS 2 CDR
+	// message, such as "temperature" or "pose.position.z". Empty means the
+	// message itself.
+	Field string `yaml:"field"`
+	Unit  string `yaml:"unit"`
+}
+
+// sidecarReading is one entry of a sidecar's "sensors" map.
+type sidecarReading struct {
+	Min   float64 `yaml:"min"`
+	Max   float64 `yaml:"max"`
+	Mean  float64 `yaml:"mean"`
+	Count int64   `yaml:"count"`
+	Unit  string  `yaml:"unit"`
+}
+
+func defaultSensorsConfig() SensorsConfig {
+	return SensorsConfig{Sources: []string{sensorSourceSidecar, sensorSourceMessages}}
+}
+
+func (c SensorsConfig) validate() error {
+	for _, source := range c.Sources {
+		if source != sensorSourceSidecar && source != sensorSourceMessages {
+			return fmt.Errorf("unknown sensor source %q", source)
+		}
+	}
+	names := make(map[string]bool, len(c.Readings))
+	for _, r := range c.Readings {
+		if r.Name == "" || r.Topic == "" {
+			return errors.New("every sensor reading needs a name and a topic")
+		}
+		if names[r.Name] {
+			return fmt.Errorf("sensor reading %s is listed twice", r.Name)
+		}
+		names[r.Name] = true
+	}
+	return nil
+}
+
+// summarizeSensors returns the sensor summary of the recording at filePath
+// from the first source that has one, or nil when none has. sidecarExts are
+// the sidecar extensions tried, as for identifiers.
+func summarizeSensors(cfg SensorsConfig, sidecarExts []string, filePath string) (*ledger.SensorSummary, error) {
+	for _, source := range cfg.Sources {
+		var readings []ledger.SensorReading
+		var err error
+		switch source {
+		case sensorSourceSidecar:
+			readings, err = sidecarSensors(cfg, sidecarExts, filePath)
+		case sensorSourceMessages:
+			readings, err = messageSensors(cfg, filePath)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", source, err)
+		}
+		if len(readings) > 0 {
+			return &ledger.SensorSummary{Source: source, Readings: readings}, nil
+		}
+	}
+	return nil, nil
+}
+
+// sidecarSensors reads the "sensors" map of the recording's sidecar.
+func sidecarSensors(cfg SensorsConfig, sidecarExts []string, filePath string) ([]ledger.SensorReading, error) {
+	sidecar, err := readSidecar(filePath, sidecarExts)
+	if err != nil || len(sidecar.Sensors) == 0 {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(sidecar.Sensors))
+	if len(cfg.Readings) > 0 {
+		for _, r := range cfg.Readings {
+			if _, ok := sidecar.Sensors[r.Name]; ok {
+				names = append(names, r.Name)
+			}
+		}
+	} else {
+		for name := range sidecar.Sensors {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+	}
+
+	readings := make([]ledger.SensorReading, 0, len(names))
+	for _, name := range names {
+		s := sidecar.Sensors[name]
+		if s.Count == 0 {
+			s.Count = 1
+		}
+		if s.Count < 0 || s.Min > s.Max || !finite(s.Min, s.Max, s.Mean) {
+			return nil, fmt.Errorf("sensor %s has an invalid summary", name)
+		}
+		reading := ledger.SensorReading{Name: name, Unit: s.Unit, Count: s.Count, Min: s.Min, Max: s.Max, Mean: s.Mean}
+		if i := slices.IndexFunc(cfg.Readings, func(r SensorConfig) bool { return r.Name == name }); i >= 0 {
+			reading.Topic = cfg.Readings[i].Topic
+			if reading.Unit == "" {
+				reading.Unit = cfg.Readings[i].Unit
+			}
+		}
+		readings = append(readings, reading)
+	}
+	return readings, nil
+}
+
+// sensorStats accumulates the samples of one reading.
+type sensorStats struct {
+	count    int64
+	min, max float64
+	sum      float64
+}
+
+// add counts sample v. NaN and infinite samples are skipped, as no
+// summary of them can be encoded.
+func (s *sensorStats) add(v float64) {
+	if !finite(v) {
+		return
+	}
+	if s.count == 0 || v < s.min {
+		s.min = v
+	}
+	if s.count == 0 || v > s.max {
+		s.max = v
+	}
+	s.count++
+	s.sum += v
+}
+
+// finite reports whether none of vs is NaN or infinite.
+func finite(vs ...float64) bool {
+	for _, v := range vs {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return false
+		}
+	}
+	return true
+}
+
+// messageSensors summarises the configured readings over every message of
+// the recording. Readings whose topic has no messages are left out.
+func messageSensors(cfg SensorsConfig, filePath string) ([]ledger.SensorReading, error) {
+	if len(cfg.Readings) == 0 {
+		return nil, nil
+	}
+	file, err := os.Open(filepath.Clean(filePath))
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	stats := make([]sensorStats, len(cfg.Readings))
+	err = mcap.ReadMessages(file, func(m mcap.Message) error {
+		var decoded any
+		for i, r := range cfg.Readings {
+			if r.Topic != m.Channel.Topic {
+				continue
+			}
+			if decoded == nil {
+				var err error
+				if decoded, err = m.Decode(); err != nil {
+					return err
+				}
+			}
+			v, err := mcap.Number(decoded, r.Field)
+			if err != nil {
+				return fmt.Errorf("sensor %s: %w", r.Name, err)
+			}
+			stats[i].add(v)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var readings []ledger.SensorReading
+	for i, r := range cfg.Readings {
+		s := stats[i]
+		if s.count == 0 {
+			continue
+		}
+		readings = append(readings, ledger.SensorReading{
+			Name:  r.Name,
+			Topic: r.Topic,
+			Unit:  r.Unit,
+			Count: s.count,
+			Min:   s.min,
+			Max:   s.max,
+			Mean:  math.Min(math.Max(s.sum/float64(s.count), s.min), s.max),
+		})
+	}
+	return readings, nil
+}
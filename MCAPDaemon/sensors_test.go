@@ -0,0 +1,181 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Octavian-Anghel/Capstone-Project/mcap"
+)
+
+// writeSensorRecording writes an MCAP file with one JSON channel on topic
+// and a message per payload.
+func writeSensorRecording(t *testing.T, filePath string, topic string, payloads ...string) {
+	t.Helper()
+	writeRecording(t, filePath, topic, "", payloads...)
+}
+
+// writeRecording writes an MCAP file with one channel on topic and a
+// message per payload. The channel is CDR encoded with ros2msg schema when
+// one is given, and JSON encoded otherwise.
+func writeRecording(t *testing.T, filePath string, topic string, schema string, payloads ...string) {
+	t.Helper()
+	var out bytes.Buffer
+	le := binary.LittleEndian
+	record := func(op mcap.Opcode, body []byte) {
+		out.WriteByte(byte(op))
+		binary.Write(&out, le, uint64(len(body)))
+		out.Write(body)
+	}
+	str := func(b *bytes.Buffer, s string) {
+		binary.Write(b, le, uint32(len(s)))
+		b.WriteString(s)
+	}
+
+	out.Write(mcap.Magic)
+	var schemaID uint16
+	encoding := "json"
+	if schema != "" {
+		var rec bytes.Buffer
+		binary.Write(&rec, le, uint16(1))
+		str(&rec, "sensor_msgs/msg/Reading")
+		str(&rec, "ros2msg")
+		str(&rec, schema)
+		record(mcap.OpSchema, rec.Bytes())
+		schemaID, encoding = 1, "cdr"
+	}
+	var channel bytes.Buffer
+	binary.Write(&channel, le, uint16(1))
+	binary.Write(&channel, le, schemaID)
+	str(&channel, topic)
+	str(&channel, encoding)
+	binary.Write(&channel, le, uint32(0))
+	record(mcap.OpChannel, channel.Bytes())
+	for i, payload := range payloads {
+		var msg bytes.Buffer
+		binary.Write(&msg, le, uint16(1))
+		binary.Write(&msg, le, uint32(i))
+		binary.Write(&msg, le, uint64(i))
+		binary.Write(&msg, le, uint64(i))
+		msg.WriteString(payload)
+		record(mcap.OpMessage, msg.Bytes())
+	}
+	out.Write(mcap.Magic)
+
+	if err := os.WriteFile(filePath, out.Bytes(), 0o600); err != nil {
+		t.Fatalf("Failed to create recording: %v", err)
+	}
+}
+
+// Test summarising readings from messages, with the sidecar taking priority
+func TestSummarizeSensors(t *testing.T) {
+	dir := t.TempDir()
+	filePath := filepath.Join(dir, "run1.mcap")
+	writeSensorRecording(t, filePath, "/env",
+		`{"temperature": 20.5, "humidity": 40}`,
+		`{"temperature": 22.5, "humidity": 44}`,
+		`{"temperature": 21.5, "humidity": 42}`,
+	)
+
+	cfg := SensorsConfig{
+		Sources: []string{sensorSourceSidecar, sensorSourceMessages},
+		Readings: []SensorConfig{
+			{Name: "temperature", Topic: "/env", Field: "temperature", Unit: "degC"},
+			{Name: "humidity", Topic: "/env", Field: "humidity", Unit: "%"},
+			{Name: "pressure", Topic: "/baro", Field: "pressure"},
+		},
+	}
+	exts := []string{".yaml"}
+
+	summary, err := summarizeSensors(cfg, exts, filePath)
+	if err != nil {
+		t.Fatalf("Error summarising sensors: %v", err)
+	}
+	if summary == nil || summary.Source != sensorSourceMessages || len(summary.Readings) != 2 {
+		t.Fatalf("Expected two readings from messages, got %+v", summary)
+	}
+	temperature := summary.Readings[0]
+	if temperature.Name != "temperature" || temperature.Unit != "degC" || temperature.Count != 3 ||
+		temperature.Min != 20.5 || temperature.Max != 22.5 || temperature.Mean != 21.5 {
+		t.Errorf("Unexpected temperature reading %+v", temperature)
+	}
+
+	sidecar := "sensors:\n  humidity: {min: 30, max: 35, mean: 32}\n  vibration: {min: 0, max: 1, mean: 0.2, count: 10}\n"
+	if err := os.WriteFile(filepath.Join(dir, "run1.yaml"), []byte(sidecar), 0o600); err != nil {
+		t.Fatalf("Failed to create sidecar: %v", err)
+	}
+	summary, err = summarizeSensors(cfg, exts, filePath)
+	if err != nil {
+		t.Fatalf("Error summarising sensors: %v", err)
+	}
+	if summary.Source != sensorSourceSidecar || len(summary.Readings) != 1 {
+		t.Fatalf("Expected only the configured humidity reading from the sidecar, got %+v", summary)
+	}
+	if h := summary.Readings[0]; h.Name != "humidity" || h.Unit != "%" || h.Count != 1 || h.Mean != 32 {
+		t.Errorf("Unexpected humidity reading %+v", h)
+	}
+
+	cfg.Readings = nil
+	summary, err = summarizeSensors(cfg, exts, filePath)
+	if err != nil {
+		t.Fatalf("Error summarising sensors: %v", err)
+	}
+	if len(summary.Readings) != 2 || summary.Readings[1].Name != "vibration" || summary.Readings[1].Count != 10 {
+		t.Errorf("Expected every sidecar reading by name, got %+v", summary.Readings)
+	}
+}
+
+// Test that NaN and infinite samples are left out of a summary
+func TestSummarizeSensors_NonFinite(t *testing.T) {
+	dir := t.TempDir()
+	filePath := filepath.Join(dir, "run1.mcap")
+	var payloads []string
+	for _, v := range []float64{20, math.NaN(), 22, math.Inf(1)} {
+		payload := []byte{0x00, 0x01, 0x00, 0x00}
+		payloads = append(payloads, string(binary.LittleEndian.AppendUint64(payload, math.Float64bits(v))))
+	}
+	writeRecording(t, filePath, "/env", "float64 temperature", payloads...)
+
+	cfg := SensorsConfig{
+		Sources:  []string{sensorSourceSidecar, sensorSourceMessages},
+		Readings: []SensorConfig{{Name: "temperature", Topic: "/env", Field: "temperature"}},
+	}
+	summary, err := summarizeSensors(cfg, []string{".yaml"}, filePath)
+	if err != nil {
+		t.Fatalf("Error summarising sensors: %v", err)
+	}
+	if len(summary.Readings) != 1 {
+		t.Fatalf("Expected one reading, got %+v", summary)
+	}
+	if r := summary.Readings[0]; r.Count != 2 || r.Min != 20 || r.Max != 22 || r.Mean != 21 {
+		t.Errorf("Expected only the finite samples to count, got %+v", r)
+	}
+
+	sidecar := "sensors:\n  temperature: {min: 20, max: 22, mean: .nan}\n"
+	if err := os.WriteFile(filepath.Join(dir, "run1.yaml"), []byte(sidecar), 0o600); err != nil {
+		t.Fatalf("Failed to create sidecar: %v", err)
+	}
+	if _, err := summarizeSensors(cfg, []string{".yaml"}, filePath); err == nil {
+		t.Error("Expected a sidecar with a NaN mean to be refused")
+	}
+}
+
+// Test that a field missing from the messages is reported
+func TestSummarizeSensors_BadField(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "run1.mcap")
+	writeSensorRecording(t, filePath, "/env", `{"temperature": 20.5}`)
+
+	cfg := SensorsConfig{
+		Sources:  []string{sensorSourceMessages},
+		Readings: []SensorConfig{{Name: "humidity", Topic: "/env", Field: "humidity"}},
+	}
+	if _, err := summarizeSensors(cfg, nil, filePath); err == nil {
+		t.Errorf("Expected an error for a missing field")
+	}
+	if err := (SensorsConfig{Sources: []string{"radio"}}).validate(); err == nil {
+		t.Errorf("Expected an error for an unknown source")
+	}
+}
@@ -0,0 +1,101 @@
+// Package adminapi is the local admin API of MCAPDaemon: JSON over HTTP on
+// a Unix socket, so only users with access to the socket file can drive
+// the daemon.
+//
+// The daemon serves these endpoints:
+//
+//	GET  /status    queue and worker state as a Status
+//	GET  /files     the most recent files, newest first, as []File
+//	GET  /failures  the most recent files that were not anchored
+//	POST /pause     stop workers from starting on new files
+//	POST /resume    let workers continue
+//	POST /reanchor  queue a ReanchorRequest's file again
+//	POST /reload    re-read the config file, answering with a ReloadResult
+//
+// Failed requests answer with a non-2xx status and an ErrorResponse.
+package adminapi
+
+import "time"
+
+// Status is the state of the daemon's work queue.
+type Status struct {
+	Paused bool `json:"paused"`
+
+	// Workers is the number of worker goroutines and Busy the number of
+	// them processing a file.
+	Workers int `json:"workers"`
+	Busy    int `json:"busy"`
+
+	// Queued files are validated and waiting for a worker.
+	Queued    int `json:"queued"`
+	QueueSize int `json:"queueSize"`
+
+	// Backlog is the number of detected files not yet processed, which
+	// survive a restart.
+	Backlog int `json:"backlog"`
+
+	// Journal is the number of captured recordings waiting to be anchored.
+	Journal int `json:"journal"`
+
+	WatchDir   string    `json:"watchDir"`
+	ConfigPath string    `json:"configPath"`
+	Started    time.Time `json:"started"`
+	Reloaded   time.Time `json:"reloaded,omitzero"`
+	Peers      []Peer    `json:"peers"`
+}
+
+// Peer is the connection state of a gateway peer.
+type Peer struct {
+	Name   string `json:"name"`
+	State  string `json:"state"`
+	Active bool   `json:"active"`
+}
+
+// File outcomes.
+const (
+	FileQueued     = "queued"
+	FileProcessing = "processing"
+	FileAnchored   = "anchored"
+	FileDuplicate  = "duplicate" // already anchored by an earlier run
+	FileRejected   = "rejected"  // will never be anchored as it is
+	FilePending    = "pending"   // captured, waiting for the gateway
+	FileFailed     = "failed"
+)
+
+// File is what became of a recording the daemon picked up.
+type File struct {
+	Path   string    `json:"path"`
+	CID    string    `json:"cid,omitempty"`
+	Status string    `json:"status"`
+	McapID string    `json:"mcapId,omitempty"`
+	TxID   string    `json:"txId,omitempty"`
+	Error  string    `json:"error,omitempty"`
+	Time   time.Time `json:"time"`
+}
+
+// Failed reports whether the file ended without being anchored.
+func (f File) Failed() bool {
+	switch f.Status {
+	case FileRejected, FilePending, FileFailed:
+		return true
+	}
+	return false
+}
+
+// ReanchorRequest asks the daemon to process a file in its watch
+// directory again, for example after fixing its sidecar.
+type ReanchorRequest struct {
+	Path string `json:"path"`
+}
+
+// ReloadResult lists the config settings a reload applied and those that
+// changed but only take effect after a restart.
+type ReloadResult struct {
+	Applied         []string `json:"applied"`
+	RestartRequired []string `json:"restartRequired,omitempty"`
+}
+
+// ErrorResponse is the body of failed requests.
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
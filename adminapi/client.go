@@ -0,0 +1,116 @@
+package adminapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+)
+
+// Client calls the admin API of a daemon on the local machine.
+type Client struct {
+	socket string
+	http   *http.Client
+}
+
+// NewClient returns a client for the daemon listening on socketPath.
+// Requests are bounded by their context only.
+func NewClient(socketPath string) *Client {
+	transport := &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "unix", socketPath)
+		},
+	}
+	return &Client{socket: socketPath, http: &http.Client{Transport: transport}}
+}
+
+// Socket returns the path of the daemon's socket.
+func (c *Client) Socket() string {
+	return c.socket
+}
+
+// Status returns the state of the daemon's work queue.
+func (c *Client) Status(ctx context.Context) (*Status, error) {
+	var status Status
+	if err := c.do(ctx, http.MethodGet, "/status", nil, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// Files returns the most recent files, newest first.
+func (c *Client) Files(ctx context.Context) ([]File, error) {
+	var files []File
+	err := c.do(ctx, http.MethodGet, "/files", nil, &files)
+	return files, err
+}
+
+// Failures returns the most recent files that were not anchored.
+func (c *Client) Failures(ctx context.Context) ([]File, error) {
+	var files []File
+	err := c.do(ctx, http.MethodGet, "/failures", nil, &files)
+	return files, err
+}
+
+// Pause stops workers from starting on new files. Files being processed
+// finish.
+func (c *Client) Pause(ctx context.Context) error {
+	return c.do(ctx, http.MethodPost, "/pause", nil, nil)
+}
+
+// Resume lets paused workers continue.
+func (c *Client) Resume(ctx context.Context) error {
+	return c.do(ctx, http.MethodPost, "/resume", nil, nil)
+}
+
+// Reanchor queues a file in the daemon's watch directory again.
+func (c *Client) Reanchor(ctx context.Context, filePath string) error {
+	return c.do(ctx, http.MethodPost, "/reanchor", ReanchorRequest{Path: filePath}, nil)
+}
+
+// Reload makes the daemon re-read its config file.
+func (c *Client) Reload(ctx context.Context) (*ReloadResult, error) {
+	var result ReloadResult
+	if err := c.do(ctx, http.MethodPost, "/reload", nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (c *Client) do(ctx context.Context, method string, path string, body any, result any) error {
+	var payload bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&payload).Encode(body); err != nil {
+			return err
+		}
+	}
+	req, err := http.NewRequestWithContext(ctx, method, "http://mcapdaemon"+path, &payload)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach daemon at %s: %w", c.socket, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		var e ErrorResponse
+		if err := json.NewDecoder(resp.Body).Decode(&e); err != nil || e.Error == "" {
+			return fmt.Errorf("daemon returned %s", resp.Status)
+		}
+		return fmt.Errorf("daemon returned %s: %s", resp.Status, e.Error)
+	}
+	if result == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return fmt.Errorf("invalid daemon response: %w", err)
+	}
+	return nil
+}
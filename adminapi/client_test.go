@@ -0,0 +1,61 @@
+package adminapi
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newTestDaemon serves handler on a Unix socket and returns a client for
+// it.
+func newTestDaemon(t *testing.T, handler http.Handler) *Client {
+	t.Helper()
+	socket := filepath.Join(t.TempDir(), "admin.sock")
+	l, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Skipf("Unix sockets unavailable: %v", err)
+	}
+	srv := httptest.NewUnstartedServer(handler)
+	srv.Listener = l
+	srv.Start()
+	t.Cleanup(srv.Close)
+	return NewClient(socket)
+}
+
+// Test decoding responses and error bodies
+func TestClient(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /status", func(w http.ResponseWriter, _ *http.Request) {
+		json.NewEncoder(w).Encode(Status{Paused: true, Workers: 2, Queued: 3})
+	})
+	mux.HandleFunc("POST /reanchor", func(w http.ResponseWriter, r *http.Request) {
+		var req ReanchorRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: req.Path + " is not in the watch directory"})
+	})
+	client := newTestDaemon(t, mux)
+	ctx := context.Background()
+
+	status, err := client.Status(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !status.Paused || status.Workers != 2 || status.Queued != 3 {
+		t.Errorf("Unexpected status %+v", status)
+	}
+
+	err = client.Reanchor(ctx, "/etc/passwd")
+	if err == nil || !strings.Contains(err.Error(), "400 Bad Request: /etc/passwd is not in the watch directory") {
+		t.Errorf("Expected the daemon's error, got %v", err)
+	}
+
+	if _, err := NewClient(filepath.Join(t.TempDir(), "missing.sock")).Status(ctx); err == nil || !strings.Contains(err.Error(), "failed to reach daemon") {
+		t.Errorf("Expected an unreachable daemon to be reported, got %v", err)
+	}
+}
@@ -0,0 +1,42 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// Chaincode events, emitted once the transaction that wrote the asset
+// commits.
+const (
+	EventAssetCreated = "AssetCreated"
+	EventAssetRevoked = "AssetRevoked"
+)
+
+// AssetEvent is the payload of the chaincode events: the asset as written
+// and the client that submitted the transaction.
+type AssetEvent struct {
+	Asset     *Asset `json:"Asset"`
+	MSPID     string `json:"MSPID"`
+	Submitter string `json:"Submitter"`
+}
+
+// setAssetEvent sets the chaincode event of the transaction. Fabric keeps
+// one event per transaction, so it is called once after the state write.
+func setAssetEvent(ctx contractapi.TransactionContextInterface, name string, asset *Asset) error {
+	mspID, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return fmt.Errorf("failed to read client MSP ID: %v", err)
+	}
+	submitter, err := ctx.GetClientIdentity().GetID()
+	if err != nil {
+		return fmt.Errorf("failed to read client identity: %v", err)
+	}
+
+	payload, err := json.Marshal(AssetEvent{Asset: asset, MSPID: mspID, Submitter: submitter})
+	if err != nil {
+		return err
+	}
+	return ctx.GetStub().SetEvent(name, payload)
+}
@@ -0,0 +1,184 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// HistoryEntry is one committed change to an asset. Asset is unset for
+// deletions.
+type HistoryEntry struct {
+	Asset     *Asset `json:"Asset,omitempty" metadata:",optional"`
+	IsDelete  bool   `json:"IsDelete"`
+	Timestamp string `json:"Timestamp"`
+	TxID      string `json:"TxID"`
+}
+
+// GetAssetHistory returns every committed change to an asset, newest first.
+func (s *SmartContract) GetAssetHistory(ctx contractapi.TransactionContextInterface, mcapID string) ([]*HistoryEntry, error) {
+	resultsIterator, err := ctx.GetStub().GetHistoryForKey(mcapID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read asset history: %v", err)
+	}
+	defer resultsIterator.Close()
+
+	var history []*HistoryEntry
+	for resultsIterator.HasNext() {
+		modification, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		entry := &HistoryEntry{
+			IsDelete:  modification.IsDelete,
+			Timestamp: modification.Timestamp.AsTime().UTC().Format(time.RFC3339),
+			TxID:      modification.TxId,
+		}
+		if !modification.IsDelete {
+			var asset Asset
+			if err := json.Unmarshal(modification.Value, &asset); err != nil {
+				return nil, err
+			}
+			entry.Asset = &asset
+		}
+		history = append(history, entry)
+	}
+
+	return history, nil
+}
+
+// ReadAssets returns the assets with the given IDs that exist, in the order
+// requested. It lets clients look up many assets in one evaluation.
+func (s *SmartContract) ReadAssets(ctx contractapi.TransactionContextInterface, mcapIDs []string) ([]*Asset, error) {
+	assets := []*Asset{}
+	for _, mcapID := range mcapIDs {
+		assetJSON, err := ctx.GetStub().GetState(mcapID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read from world state: %v", err)
+		}
+		if assetJSON == nil {
+			continue
+		}
+
+		var asset Asset
+		if err := json.Unmarshal(assetJSON, &asset); err != nil {
+			return nil, err
+		}
+		assets = append(assets, &asset)
+	}
+
+	return assets, nil
+}
+
+// AssetPage is one page of assets in key order. Bookmark resumes the
+// listing after the page and is empty once every asset has been returned.
+type AssetPage struct {
+	Assets       []*Asset `json:"Assets"`
+	Bookmark     string   `json:"Bookmark"`
+	FetchedCount int32    `json:"FetchedCount"`
+}
+
+// GetAssetsPage returns up to pageSize assets starting at bookmark, which
+// is empty for the first page.
+func (s *SmartContract) GetAssetsPage(ctx contractapi.TransactionContextInterface, pageSize int32, bookmark string) (*AssetPage, error) {
+	if pageSize < 1 {
+		return nil, fmt.Errorf("page size must be at least 1, got %d", pageSize)
+	}
+	resultsIterator, metadata, err := ctx.GetStub().GetStateByRangeWithPagination("", "", pageSize, bookmark)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	defer resultsIterator.Close()
+
+	page := &AssetPage{Assets: []*Asset{}}
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		var asset Asset
+		if err := json.Unmarshal(queryResponse.Value, &asset); err != nil {
+			return nil, err
+		}
+		page.Assets = append(page.Assets, &asset)
+	}
+
+	page.FetchedCount = metadata.GetFetchedRecordsCount()
+	if page.FetchedCount == pageSize {
+		page.Bookmark = metadata.GetBookmark()
+	}
+	return page, nil
+}
+
+// GetAllAssets returns all assets found in world state.
+func (s *SmartContract) GetAllAssets(ctx contractapi.TransactionContextInterface) ([]*Asset, error) {
+	return s.readAssets(ctx, func(*Asset) bool { return true })
+}
+
+// QueryAssets returns the assets matching every filter that is set. from
+// and to bound the asset Datetime as RFC3339 times, from inclusive and to
+// exclusive.
+func (s *SmartContract) QueryAssets(ctx contractapi.TransactionContextInterface, project string, operation string, from string, to string) ([]*Asset, error) {
+	var after, before time.Time
+	var err error
+	if from != "" {
+		if after, err = time.Parse(time.RFC3339, from); err != nil {
+			return nil, fmt.Errorf("invalid from time %q: %v", from, err)
+		}
+	}
+	if to != "" {
+		if before, err = time.Parse(time.RFC3339, to); err != nil {
+			return nil, fmt.Errorf("invalid to time %q: %v", to, err)
+		}
+	}
+
+	return s.readAssets(ctx, func(asset *Asset) bool {
+		if project != "" && asset.Project != project {
+			return false
+		}
+		if operation != "" && asset.Operation != operation {
+			return false
+		}
+		if from == "" && to == "" {
+			return true
+		}
+		datetime, err := time.Parse(time.RFC3339, asset.Datetime)
+		if err != nil {
+			return false
+		}
+		return (from == "" || !datetime.Before(after)) && (to == "" || datetime.Before(before))
+	})
+}
+
+// readAssets returns the assets in world state for which keep returns true.
+// Configuration is stored under composite keys, which a range query over
+// all simple keys does not return.
+func (s *SmartContract) readAssets(ctx contractapi.TransactionContextInterface, keep func(*Asset) bool) ([]*Asset, error) {
+	resultsIterator, err := ctx.GetStub().GetStateByRange("", "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	defer resultsIterator.Close()
+
+	var assets []*Asset
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		var asset Asset
+		if err := json.Unmarshal(queryResponse.Value, &asset); err != nil {
+			return nil, err
+		}
+		if keep(&asset) {
+			assets = append(assets, &asset)
+		}
+	}
+
+	return assets, nil
+}
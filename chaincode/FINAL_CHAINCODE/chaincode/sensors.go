@@ -0,0 +1,59 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// SensorSummary holds environmental readings summarised over a recording,
+// such as the temperature and humidity while it was captured. Source names
+// where the client took them from, for example "messages" or "sidecar".
+type SensorSummary struct {
+	Readings []SensorReading `json:"Readings"`
+	Source   string          `json:"Source"`
+}
+
+// SensorReading is the minimum, maximum and mean of one sensor value.
+type SensorReading struct {
+	Count int64   `json:"Count"`
+	Max   float64 `json:"Max"`
+	Mean  float64 `json:"Mean"`
+	Min   float64 `json:"Min"`
+	Name  string  `json:"Name"`
+	Topic string  `json:"Topic,omitempty" metadata:",optional"`
+	Unit  string  `json:"Unit,omitempty" metadata:",optional"`
+}
+
+// parseSensors decodes and checks the sensors argument of CreateAsset. An
+// empty argument means the client supplied no summary.
+func parseSensors(sensorsJSON string) (*SensorSummary, error) {
+	if sensorsJSON == "" {
+		return nil, nil
+	}
+
+	var summary SensorSummary
+	if err := json.Unmarshal([]byte(sensorsJSON), &summary); err != nil {
+		return nil, fmt.Errorf("invalid sensor summary: %v", err)
+	}
+	if len(summary.Readings) == 0 {
+		return nil, fmt.Errorf("invalid sensor summary: no readings")
+	}
+
+	names := make(map[string]bool, len(summary.Readings))
+	for _, r := range summary.Readings {
+		if r.Name == "" {
+			return nil, fmt.Errorf("invalid sensor summary: a reading has no name")
+		}
+		if names[r.Name] {
+			return nil, fmt.Errorf("invalid sensor summary: reading %s appears twice", r.Name)
+		}
+		names[r.Name] = true
+		if r.Count <= 0 {
+			return nil, fmt.Errorf("invalid sensor summary: reading %s has no samples", r.Name)
+		}
+		if r.Min > r.Max {
+			return nil, fmt.Errorf("invalid sensor summary: reading %s has a minimum above its maximum", r.Name)
+		}
+	}
+	return &summary, nil
+}
@@ -0,0 +1,133 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// SkewWindow bounds how far the capture time reported by a client may be
+// from the transaction timestamp. MaxPast is generous so recordings
+// captured while a client was offline can still be anchored once it
+// reconnects; MaxFuture only needs to cover clock drift.
+type SkewWindow struct {
+	MaxFuture string `json:"MaxFuture"`
+	MaxPast   string `json:"MaxPast"`
+}
+
+// DefaultSkewWindow applies until an admin calls SetSkewWindow.
+var DefaultSkewWindow = SkewWindow{MaxFuture: "5m0s", MaxPast: "720h0m0s"}
+
+const (
+	configObjectType = "config"
+	skewWindowKey    = "skewWindow"
+)
+
+func (w SkewWindow) durations() (future time.Duration, past time.Duration, err error) {
+	if future, err = time.ParseDuration(w.MaxFuture); err != nil {
+		return 0, 0, fmt.Errorf("invalid MaxFuture: %v", err)
+	}
+	if past, err = time.ParseDuration(w.MaxPast); err != nil {
+		return 0, 0, fmt.Errorf("invalid MaxPast: %v", err)
+	}
+	if future < 0 || past < 0 {
+		return 0, 0, fmt.Errorf("skew window durations must not be negative")
+	}
+	return future, past, nil
+}
+
+// SetSkewWindow sets how far ahead of and behind the transaction timestamp
+// capture times may be, as Go durations such as "5m" and "720h". Only
+// admins of the invoking organization may call it.
+func (s *SmartContract) SetSkewWindow(ctx contractapi.TransactionContextInterface, maxFuture string, maxPast string) error {
+	if err := assertAdmin(ctx); err != nil {
+		return err
+	}
+
+	future, past, err := SkewWindow{MaxFuture: maxFuture, MaxPast: maxPast}.durations()
+	if err != nil {
+		return err
+	}
+	windowJSON, err := json.Marshal(SkewWindow{MaxFuture: future.String(), MaxPast: past.String()})
+	if err != nil {
+		return err
+	}
+
+	key, err := ctx.GetStub().CreateCompositeKey(configObjectType, []string{skewWindowKey})
+	if err != nil {
+		return err
+	}
+	return ctx.GetStub().PutState(key, windowJSON)
+}
+
+// GetSkewWindow returns the skew window capture times are checked against.
+func (s *SmartContract) GetSkewWindow(ctx contractapi.TransactionContextInterface) (*SkewWindow, error) {
+	key, err := ctx.GetStub().CreateCompositeKey(configObjectType, []string{skewWindowKey})
+	if err != nil {
+		return nil, err
+	}
+	windowJSON, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+
+	window := DefaultSkewWindow
+	if windowJSON != nil {
+		if err := json.Unmarshal(windowJSON, &window); err != nil {
+			return nil, err
+		}
+	}
+	return &window, nil
+}
+
+// txTime returns the transaction timestamp, which the submitting client
+// sets but every endorsing peer and the orderer see unchanged.
+func txTime(ctx contractapi.TransactionContextInterface) (time.Time, error) {
+	timestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to read transaction timestamp: %v", err)
+	}
+	return timestamp.AsTime().UTC(), nil
+}
+
+// checkCaptureTime parses captureTime and rejects it if it falls outside
+// the skew window around now.
+func (s *SmartContract) checkCaptureTime(ctx contractapi.TransactionContextInterface, captureTime string, now time.Time) (time.Time, error) {
+	captured, err := time.Parse(time.RFC3339, captureTime)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid capture time %q: %v", captureTime, err)
+	}
+
+	window, err := s.GetSkewWindow(ctx)
+	if err != nil {
+		return time.Time{}, err
+	}
+	future, past, err := window.durations()
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	if captured.After(now.Add(future)) {
+		return time.Time{}, fmt.Errorf("capture time %s is more than %s after the transaction time %s", captureTime, future, now.Format(time.RFC3339))
+	}
+	if captured.Before(now.Add(-past)) {
+		return time.Time{}, fmt.Errorf("capture time %s is more than %s before the transaction time %s", captureTime, past, now.Format(time.RFC3339))
+	}
+	return captured.UTC(), nil
+}
+
+// assertAdmin fails unless the client holds an admin certificate, which
+// with NodeOUs enabled carries the "admin" organizational unit.
+func assertAdmin(ctx contractapi.TransactionContextInterface) error {
+	cert, err := ctx.GetClientIdentity().GetX509Certificate()
+	if err != nil {
+		return fmt.Errorf("failed to read client certificate: %v", err)
+	}
+	if cert == nil || !slices.Contains(cert.Subject.OrganizationalUnit, "admin") {
+		return fmt.Errorf("the client is not an admin")
+	}
+	return nil
+}
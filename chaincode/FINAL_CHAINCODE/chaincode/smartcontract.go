@@ -3,6 +3,8 @@ package chaincode
 import (
 	"encoding/json"
 	"fmt"
+	"regexp"
+	"time"
 
 	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
 )
@@ -16,15 +18,49 @@ type SmartContract struct {
 // Insert struct field in alphabetic order => to achieve determinism across languages
 // golang keeps the order when marshal to json but doesn't order automatically
 type Asset struct {
-	Datetime      string `json:"Datetime"`
-	Hash          string `json:"Hash"`
-	McapID        string `json:"McapID"`
-	Project       string `json: "Project"`
+	// Algorithm identifies the digest algorithm Hash was computed with.
+	// Assets anchored before it was recorded leave it empty, meaning sha256.
+	Algorithm   string `json:"Algorithm,omitempty" metadata:",optional"`
+	CaptureTime string `json:"CaptureTime"`
+	// ChunkRoot is the root of the Merkle tree over the recording's chunks,
+	// for proving a time slice without the whole file. It is empty when the
+	// recording has no chunk index.
+	ChunkRoot string `json:"ChunkRoot,omitempty" metadata:",optional"`
+	Datetime  string `json:"Datetime"`
+	Hash      string `json:"Hash"`
+	McapID    string `json:"McapID"`
+	Operation string `json:"Operation"`
+	Path      string `json:"Path"`
+	Project   string `json:"Project"`
+
+	// Revocation is set once the asset has been revoked.
+	Revocation *Revocation `json:"Revocation,omitempty" metadata:",optional"`
+
+	// Sensors is set when the client summarised sensor readings taken during
+	// the recording.
+	Sensors *SensorSummary `json:"Sensors,omitempty" metadata:",optional"`
 }
+
+// Revocation records why, when and by whom an asset was revoked.
+type Revocation struct {
+	Reason    string `json:"Reason"`
+	RevokedAt string `json:"RevokedAt"`
+	RevokedBy string `json:"RevokedBy"`
+}
+
+// DefaultAlgorithm is the digest algorithm of assets created without one.
+const DefaultAlgorithm = "sha256"
+
+// validAlgorithm bounds the algorithm identifiers clients may record.
+var validAlgorithm = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,31}$`)
+
+// validChunkRoot matches a hex SHA-256 chunk tree root.
+var validChunkRoot = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
 // InitLedger adds a base set of assets to the ledger
 func (s *SmartContract) InitLedger(ctx contractapi.TransactionContextInterface) error {
 	assets := []Asset{
-		{Datetime: "1/1/1970 12:00AM UTC", Hash: "Test Hash :)", PrintName: "Test Print", Project: "ARP",},
+		{Datetime: "1970-01-01T00:00:00Z", Hash: "Test Hash :)", McapID: "Test Print", Project: "ARP"},
 	}
 
 	for _, asset := range assets {
@@ -33,7 +69,7 @@ func (s *SmartContract) InitLedger(ctx contractapi.TransactionContextInterface)
 			return err
 		}
 
-		err = ctx.GetStub().PutState(asset.PrintName, assetJSON)
+		err = ctx.GetStub().PutState(asset.McapID, assetJSON)
 		if err != nil {
 			return fmt.Errorf("failed to put to world state. %v", err)
 		}
@@ -43,36 +79,77 @@ func (s *SmartContract) InitLedger(ctx contractapi.TransactionContextInterface)
 }
 
 // CreateAsset issues a new asset to the world state with given details.
-func (s *SmartContract) CreateAsset(ctx contractapi.TransactionContextInterface, datetime string, hash string, mcapID string, operationID string) error {
+// path is the location the recording was detected at and is informational
+// only; the asset is keyed by mcapID. The asset's Datetime is the
+// transaction timestamp, so clients cannot backdate it. captureTime is when
+// the client hashed the recording, which is earlier for recordings captured
+// while the client was offline, and must fall within the skew window.
+// sensors is a JSON SensorSummary, or empty when there is none. algorithm
+// identifies the digest algorithm of hash, such as "sha256" or "blake3",
+// and defaults to sha256. chunkRoot is the hex root of the recording's chunk
+// tree, or empty when it has none.
+func (s *SmartContract) CreateAsset(ctx contractapi.TransactionContextInterface, hash string, mcapID string, operationID string, project string, path string, captureTime string, sensors string, algorithm string, chunkRoot string) error {
 	exists, err := s.AssetExists(ctx, mcapID)
 	if err != nil {
 		return err
 	}
 	if exists {
-		return fmt.Errorf("the asset %s already exists", printname)
+		return fmt.Errorf("the asset %s already exists", mcapID)
+	}
+
+	now, err := txTime(ctx)
+	if err != nil {
+		return err
+	}
+	captured, err := s.checkCaptureTime(ctx, captureTime, now)
+	if err != nil {
+		return err
+	}
+	summary, err := parseSensors(sensors)
+	if err != nil {
+		return err
+	}
+	if algorithm == "" {
+		algorithm = DefaultAlgorithm
+	}
+	if !validAlgorithm.MatchString(algorithm) {
+		return fmt.Errorf("invalid hash algorithm %q", algorithm)
+	}
+	if chunkRoot != "" && !validChunkRoot.MatchString(chunkRoot) {
+		return fmt.Errorf("invalid chunk root %q", chunkRoot)
 	}
 
 	asset := Asset{
-		Datetime: datetime,
-		Hash:     hash,
-		Operation: operationID,
+		Algorithm:   algorithm,
+		CaptureTime: captured.Format(time.RFC3339),
+		ChunkRoot:   chunkRoot,
+		Datetime:    now.Format(time.RFC3339),
+		Hash:        hash,
+		McapID:      mcapID,
+		Operation:   operationID,
+		Path:        path,
+		Project:     project,
+		Sensors:     summary,
 	}
 	assetJSON, err := json.Marshal(asset)
 	if err != nil {
 		return err
 	}
 
-	return ctx.GetStub().PutState(mcapID, assetJSON)
+	if err := ctx.GetStub().PutState(mcapID, assetJSON); err != nil {
+		return err
+	}
+	return setAssetEvent(ctx, EventAssetCreated, &asset)
 }
 
 // ReadAsset returns the asset stored in the world state with given id.
-func (s *SmartContract) ReadAsset(ctx contractapi.TransactionContextInterface, printname string) (*Asset, error) {
-	assetJSON, err := ctx.GetStub().GetState(printname)
+func (s *SmartContract) ReadAsset(ctx contractapi.TransactionContextInterface, mcapID string) (*Asset, error) {
+	assetJSON, err := ctx.GetStub().GetState(mcapID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read from world state: %v", err)
 	}
 	if assetJSON == nil {
-		return nil, fmt.Errorf("the asset %s does not exist", printname)
+		return nil, fmt.Errorf("the asset %s does not exist", mcapID)
 	}
 
 	var asset Asset
@@ -85,21 +162,61 @@ func (s *SmartContract) ReadAsset(ctx contractapi.TransactionContextInterface, p
 }
 
 // DeleteAsset deletes an given asset from the world state.
-func (s *SmartContract) DeleteAsset(ctx contractapi.TransactionContextInterface, printname string) error {
-	exists, err := s.AssetExists(ctx, printname)
+func (s *SmartContract) DeleteAsset(ctx contractapi.TransactionContextInterface, mcapID string) error {
+	exists, err := s.AssetExists(ctx, mcapID)
 	if err != nil {
 		return err
 	}
 	if !exists {
-		return fmt.Errorf("the asset %s does not exist", printname)
+		return fmt.Errorf("the asset %s does not exist", mcapID)
 	}
 
-	return ctx.GetStub().DelState(printname)
+	return ctx.GetStub().DelState(mcapID)
+}
+
+// RevokeAsset marks an asset as revoked, for example because the recording
+// was found to be faulty. The asset and its history stay on the ledger.
+// Only admins may revoke assets.
+func (s *SmartContract) RevokeAsset(ctx contractapi.TransactionContextInterface, mcapID string, reason string) error {
+	if err := assertAdmin(ctx); err != nil {
+		return err
+	}
+
+	asset, err := s.ReadAsset(ctx, mcapID)
+	if err != nil {
+		return err
+	}
+	if asset.Revocation != nil {
+		return fmt.Errorf("the asset %s is already revoked", mcapID)
+	}
+
+	now, err := txTime(ctx)
+	if err != nil {
+		return err
+	}
+	revokedBy, err := ctx.GetClientIdentity().GetID()
+	if err != nil {
+		return fmt.Errorf("failed to read client identity: %v", err)
+	}
+	asset.Revocation = &Revocation{
+		Reason:    reason,
+		RevokedAt: now.Format(time.RFC3339),
+		RevokedBy: revokedBy,
+	}
+
+	assetJSON, err := json.Marshal(asset)
+	if err != nil {
+		return err
+	}
+	if err := ctx.GetStub().PutState(mcapID, assetJSON); err != nil {
+		return err
+	}
+	return setAssetEvent(ctx, EventAssetRevoked, asset)
 }
 
 // AssetExists returns true when asset with given ID exists in world state
-func (s *SmartContract) AssetExists(ctx contractapi.TransactionContextInterface, printname string) (bool, error) {
-	assetJSON, err := ctx.GetStub().GetState(printname)
+func (s *SmartContract) AssetExists(ctx contractapi.TransactionContextInterface, mcapID string) (bool, error) {
+	assetJSON, err := ctx.GetStub().GetState(mcapID)
 	if err != nil {
 		return false, fmt.Errorf("failed to read from world state: %v", err)
 	}
@@ -1,16 +1,23 @@
 package chaincode_test
 
 import (
+	"crypto/x509"
+	"crypto/x509/pkix"
 	"encoding/json"
 	"fmt"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/hyperledger/fabric-chaincode-go/v2/pkg/cid"
 	"github.com/hyperledger/fabric-chaincode-go/v2/shim"
 	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
 	"github.com/hyperledger/fabric-protos-go-apiv2/ledger/queryresult"
+	"github.com/hyperledger/fabric-protos-go-apiv2/peer"
 	"github.com/hyperledger/fabric-samples/asset-transfer-basic/chaincode-go/chaincode"
 	"github.com/hyperledger/fabric-samples/asset-transfer-basic/chaincode-go/chaincode/mocks"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 //go:generate counterfeiter -o mocks/transaction.go -fake-name TransactionContext . transactionContext
@@ -28,6 +35,30 @@ type stateQueryIterator interface {
 	shim.StateQueryIteratorInterface
 }
 
+//go:generate counterfeiter -o mocks/historyqueryiterator.go -fake-name HistoryQueryIterator . historyQueryIterator
+type historyQueryIterator interface {
+	shim.HistoryQueryIteratorInterface
+}
+
+// clientIdentity presents a certificate with the given organizational
+// units.
+type clientIdentity struct {
+	cid.ClientIdentity
+	ous []string
+}
+
+func (c clientIdentity) GetX509Certificate() (*x509.Certificate, error) {
+	return &x509.Certificate{Subject: pkix.Name{OrganizationalUnit: c.ous}}, nil
+}
+
+func (c clientIdentity) GetID() (string, error) {
+	return "x509::CN=Admin@org1.example.com", nil
+}
+
+func (c clientIdentity) GetMSPID() (string, error) {
+	return "Org1MSP", nil
+}
+
 func TestInitLedger(t *testing.T) {
 	chaincodeStub := &mocks.ChaincodeStub{}
 	transactionContext := &mocks.TransactionContext{}
@@ -46,26 +77,175 @@ func TestCreateAsset(t *testing.T) {
 	chaincodeStub := &mocks.ChaincodeStub{}
 	transactionContext := &mocks.TransactionContext{}
 	transactionContext.GetStubReturns(chaincodeStub)
+	transactionContext.GetClientIdentityReturns(clientIdentity{})
+	chaincodeStub.GetTxTimestampReturns(timestamppb.New(time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)), nil)
 
 	assetTransfer := chaincode.SmartContract{}
-	err := assetTransfer.CreateAsset(transactionContext, "", "", 0, "", 0)
+	err := assetTransfer.CreateAsset(transactionContext, "abc123", "asset1", "op1", "line-7", "/shared/run1.mcap", "2025-03-01T10:30:00+01:00", "", "", "")
 	require.NoError(t, err)
 
+	key, value := chaincodeStub.PutStateArgsForCall(0)
+	require.Equal(t, "asset1", key)
+	var stored chaincode.Asset
+	require.NoError(t, json.Unmarshal(value, &stored))
+	require.Equal(t, chaincode.Asset{
+		Algorithm:   "sha256",
+		CaptureTime: "2025-03-01T09:30:00Z",
+		Datetime:    "2025-03-01T12:00:00Z",
+		Hash:        "abc123",
+		McapID:      "asset1",
+		Operation:   "op1",
+		Path:        "/shared/run1.mcap",
+		Project:     "line-7",
+	}, stored)
+
+	name, payload := chaincodeStub.SetEventArgsForCall(0)
+	require.Equal(t, chaincode.EventAssetCreated, name)
+	var event chaincode.AssetEvent
+	require.NoError(t, json.Unmarshal(payload, &event))
+	require.Equal(t, chaincode.AssetEvent{Asset: &stored, MSPID: "Org1MSP", Submitter: "x509::CN=Admin@org1.example.com"}, event)
+
 	chaincodeStub.GetStateReturns([]byte{}, nil)
-	err = assetTransfer.CreateAsset(transactionContext, "asset1", "", 0, "", 0)
+	err = assetTransfer.CreateAsset(transactionContext, "", "asset1", "", "", "", "", "", "", "")
 	require.EqualError(t, err, "the asset asset1 already exists")
 
 	chaincodeStub.GetStateReturns(nil, fmt.Errorf("unable to retrieve asset"))
-	err = assetTransfer.CreateAsset(transactionContext, "asset1", "", 0, "", 0)
+	err = assetTransfer.CreateAsset(transactionContext, "", "asset1", "", "", "", "", "", "", "")
 	require.EqualError(t, err, "failed to read from world state: unable to retrieve asset")
 }
 
+func TestCreateAsset_SkewWindow(t *testing.T) {
+	chaincodeStub := &mocks.ChaincodeStub{}
+	transactionContext := &mocks.TransactionContext{}
+	transactionContext.GetStubReturns(chaincodeStub)
+	transactionContext.GetClientIdentityReturns(clientIdentity{})
+	chaincodeStub.GetTxTimestampReturns(timestamppb.New(time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)), nil)
+
+	assetTransfer := chaincode.SmartContract{}
+	err := assetTransfer.CreateAsset(transactionContext, "abc123", "asset1", "", "", "", "2025-03-01T12:10:00Z", "", "", "")
+	require.EqualError(t, err, "capture time 2025-03-01T12:10:00Z is more than 5m0s after the transaction time 2025-03-01T12:00:00Z")
+
+	err = assetTransfer.CreateAsset(transactionContext, "abc123", "asset1", "", "", "", "2025-01-01T12:00:00Z", "", "", "")
+	require.EqualError(t, err, "capture time 2025-01-01T12:00:00Z is more than 720h0m0s before the transaction time 2025-03-01T12:00:00Z")
+
+	err = assetTransfer.CreateAsset(transactionContext, "abc123", "asset1", "", "", "", "yesterday", "", "", "")
+	require.ErrorContains(t, err, `invalid capture time "yesterday"`)
+
+	window, err := json.Marshal(chaincode.SkewWindow{MaxFuture: "1m0s", MaxPast: "2160h0m0s"})
+	require.NoError(t, err)
+	chaincodeStub.CreateCompositeKeyReturns("\x00config\x00skewWindow\x00", nil)
+	chaincodeStub.GetStateCalls(func(key string) ([]byte, error) {
+		if key == "\x00config\x00skewWindow\x00" {
+			return window, nil
+		}
+		return nil, nil
+	})
+	err = assetTransfer.CreateAsset(transactionContext, "abc123", "asset1", "", "", "", "2025-01-01T12:00:00Z", "", "", "")
+	require.NoError(t, err)
+}
+
+func TestCreateAsset_Sensors(t *testing.T) {
+	chaincodeStub := &mocks.ChaincodeStub{}
+	transactionContext := &mocks.TransactionContext{}
+	transactionContext.GetStubReturns(chaincodeStub)
+	transactionContext.GetClientIdentityReturns(clientIdentity{})
+	chaincodeStub.GetTxTimestampReturns(timestamppb.New(time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)), nil)
+
+	assetTransfer := chaincode.SmartContract{}
+	sensors := `{"Source":"messages","Readings":[{"Name":"temperature","Topic":"/env","Unit":"degC","Count":3,"Min":20.5,"Max":22,"Mean":21.25}]}`
+	err := assetTransfer.CreateAsset(transactionContext, "abc123", "asset1", "", "", "", "2025-03-01T12:00:00Z", sensors, "", "")
+	require.NoError(t, err)
+
+	_, value := chaincodeStub.PutStateArgsForCall(0)
+	var stored chaincode.Asset
+	require.NoError(t, json.Unmarshal(value, &stored))
+	require.Equal(t, &chaincode.SensorSummary{
+		Source:   "messages",
+		Readings: []chaincode.SensorReading{{Name: "temperature", Topic: "/env", Unit: "degC", Count: 3, Min: 20.5, Max: 22, Mean: 21.25}},
+	}, stored.Sensors)
+
+	err = assetTransfer.CreateAsset(transactionContext, "abc123", "asset1", "", "", "", "2025-03-01T12:00:00Z", `{"Source":"sidecar","Readings":[{"Name":"humidity","Count":0}]}`, "", "")
+	require.EqualError(t, err, "invalid sensor summary: reading humidity has no samples")
+
+	err = assetTransfer.CreateAsset(transactionContext, "abc123", "asset1", "", "", "", "2025-03-01T12:00:00Z", `{"Source":"sidecar","Readings":[{"Name":"humidity","Count":1,"Min":50,"Max":40}]}`, "", "")
+	require.EqualError(t, err, "invalid sensor summary: reading humidity has a minimum above its maximum")
+
+	err = assetTransfer.CreateAsset(transactionContext, "abc123", "asset1", "", "", "", "2025-03-01T12:00:00Z", "{", "", "")
+	require.ErrorContains(t, err, "invalid sensor summary")
+}
+
+func TestCreateAsset_Algorithm(t *testing.T) {
+	chaincodeStub := &mocks.ChaincodeStub{}
+	transactionContext := &mocks.TransactionContext{}
+	transactionContext.GetStubReturns(chaincodeStub)
+	transactionContext.GetClientIdentityReturns(clientIdentity{})
+	chaincodeStub.GetTxTimestampReturns(timestamppb.New(time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)), nil)
+
+	assetTransfer := chaincode.SmartContract{}
+	err := assetTransfer.CreateAsset(transactionContext, "abc123", "asset1", "", "", "", "2025-03-01T12:00:00Z", "", "blake3", "")
+	require.NoError(t, err)
+
+	_, value := chaincodeStub.PutStateArgsForCall(0)
+	var stored chaincode.Asset
+	require.NoError(t, json.Unmarshal(value, &stored))
+	require.Equal(t, "blake3", stored.Algorithm)
+
+	err = assetTransfer.CreateAsset(transactionContext, "abc123", "asset1", "", "", "", "2025-03-01T12:00:00Z", "", "SHA 256", "")
+	require.EqualError(t, err, `invalid hash algorithm "SHA 256"`)
+}
+
+func TestCreateAsset_ChunkRoot(t *testing.T) {
+	chaincodeStub := &mocks.ChaincodeStub{}
+	transactionContext := &mocks.TransactionContext{}
+	transactionContext.GetStubReturns(chaincodeStub)
+	transactionContext.GetClientIdentityReturns(clientIdentity{})
+	chaincodeStub.GetTxTimestampReturns(timestamppb.New(time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)), nil)
+
+	root := strings.Repeat("ab", 32)
+	assetTransfer := chaincode.SmartContract{}
+	err := assetTransfer.CreateAsset(transactionContext, "abc123", "asset1", "", "", "", "2025-03-01T12:00:00Z", "", "", root)
+	require.NoError(t, err)
+
+	_, value := chaincodeStub.PutStateArgsForCall(0)
+	var stored chaincode.Asset
+	require.NoError(t, json.Unmarshal(value, &stored))
+	require.Equal(t, root, stored.ChunkRoot)
+
+	err = assetTransfer.CreateAsset(transactionContext, "abc123", "asset1", "", "", "", "2025-03-01T12:00:00Z", "", "", "abc")
+	require.EqualError(t, err, `invalid chunk root "abc"`)
+}
+
+func TestSetSkewWindow(t *testing.T) {
+	chaincodeStub := &mocks.ChaincodeStub{}
+	transactionContext := &mocks.TransactionContext{}
+	transactionContext.GetStubReturns(chaincodeStub)
+	transactionContext.GetClientIdentityReturns(clientIdentity{ous: []string{"client"}})
+	chaincodeStub.CreateCompositeKeyReturns("\x00config\x00skewWindow\x00", nil)
+
+	assetTransfer := chaincode.SmartContract{}
+	err := assetTransfer.SetSkewWindow(transactionContext, "1m", "2160h")
+	require.EqualError(t, err, "the client is not an admin")
+
+	transactionContext.GetClientIdentityReturns(clientIdentity{ous: []string{"admin"}})
+	err = assetTransfer.SetSkewWindow(transactionContext, "1m", "-1h")
+	require.EqualError(t, err, "skew window durations must not be negative")
+
+	err = assetTransfer.SetSkewWindow(transactionContext, "1m", "2160h")
+	require.NoError(t, err)
+
+	key, value := chaincodeStub.PutStateArgsForCall(0)
+	require.Equal(t, "\x00config\x00skewWindow\x00", key)
+	var stored chaincode.SkewWindow
+	require.NoError(t, json.Unmarshal(value, &stored))
+	require.Equal(t, chaincode.SkewWindow{MaxFuture: "1m0s", MaxPast: "2160h0m0s"}, stored)
+}
+
 func TestReadAsset(t *testing.T) {
 	chaincodeStub := &mocks.ChaincodeStub{}
 	transactionContext := &mocks.TransactionContext{}
 	transactionContext.GetStubReturns(chaincodeStub)
 
-	expectedAsset := &chaincode.Asset{ID: "asset1"}
+	expectedAsset := &chaincode.Asset{McapID: "asset1", Path: "/shared/run1.mcap"}
 	bytes, err := json.Marshal(expectedAsset)
 	require.NoError(t, err)
 
@@ -85,35 +265,72 @@ func TestReadAsset(t *testing.T) {
 	require.Nil(t, asset)
 }
 
-func TestUpdateAsset(t *testing.T) {
+func TestReadAssets(t *testing.T) {
 	chaincodeStub := &mocks.ChaincodeStub{}
 	transactionContext := &mocks.TransactionContext{}
 	transactionContext.GetStubReturns(chaincodeStub)
 
-	expectedAsset := &chaincode.Asset{ID: "asset1"}
-	bytes, err := json.Marshal(expectedAsset)
+	asset := &chaincode.Asset{McapID: "asset2", Hash: "abc123"}
+	bytes, err := json.Marshal(asset)
 	require.NoError(t, err)
+	chaincodeStub.GetStateCalls(func(key string) ([]byte, error) {
+		if key == "asset2" {
+			return bytes, nil
+		}
+		return nil, nil
+	})
 
-	chaincodeStub.GetStateReturns(bytes, nil)
 	assetTransfer := chaincode.SmartContract{}
-	err = assetTransfer.UpdateAsset(transactionContext, "", "", 0, "", 0)
+	assets, err := assetTransfer.ReadAssets(transactionContext, []string{"asset1", "asset2"})
 	require.NoError(t, err)
+	require.Equal(t, []*chaincode.Asset{asset}, assets)
 
-	chaincodeStub.GetStateReturns(nil, nil)
-	err = assetTransfer.UpdateAsset(transactionContext, "asset1", "", 0, "", 0)
-	require.EqualError(t, err, "the asset asset1 does not exist")
-
+	chaincodeStub.GetStateCalls(nil)
 	chaincodeStub.GetStateReturns(nil, fmt.Errorf("unable to retrieve asset"))
-	err = assetTransfer.UpdateAsset(transactionContext, "asset1", "", 0, "", 0)
+	_, err = assetTransfer.ReadAssets(transactionContext, []string{"asset1"})
 	require.EqualError(t, err, "failed to read from world state: unable to retrieve asset")
 }
 
+func TestGetAssetsPage(t *testing.T) {
+	asset := &chaincode.Asset{McapID: "asset1"}
+	bytes, err := json.Marshal(asset)
+	require.NoError(t, err)
+
+	iterator := &mocks.StateQueryIterator{}
+	iterator.HasNextReturnsOnCall(0, true)
+	iterator.HasNextReturnsOnCall(1, false)
+	iterator.NextReturns(&queryresult.KV{Key: "asset1", Value: bytes}, nil)
+
+	chaincodeStub := &mocks.ChaincodeStub{}
+	transactionContext := &mocks.TransactionContext{}
+	transactionContext.GetStubReturns(chaincodeStub)
+	chaincodeStub.GetStateByRangeWithPaginationReturns(iterator, &peer.QueryResponseMetadata{FetchedRecordsCount: 1, Bookmark: "asset2"}, nil)
+
+	assetTransfer := chaincode.SmartContract{}
+	page, err := assetTransfer.GetAssetsPage(transactionContext, 1, "")
+	require.NoError(t, err)
+	require.Equal(t, &chaincode.AssetPage{Assets: []*chaincode.Asset{asset}, Bookmark: "asset2", FetchedCount: 1}, page)
+
+	_, _, pageSize, bookmark := chaincodeStub.GetStateByRangeWithPaginationArgsForCall(0)
+	require.Equal(t, int32(1), pageSize)
+	require.Equal(t, "", bookmark)
+
+	iterator = &mocks.StateQueryIterator{}
+	chaincodeStub.GetStateByRangeWithPaginationReturns(iterator, &peer.QueryResponseMetadata{Bookmark: "asset2"}, nil)
+	page, err = assetTransfer.GetAssetsPage(transactionContext, 10, "asset2")
+	require.NoError(t, err)
+	require.Equal(t, &chaincode.AssetPage{Assets: []*chaincode.Asset{}}, page)
+
+	_, err = assetTransfer.GetAssetsPage(transactionContext, 0, "")
+	require.EqualError(t, err, "page size must be at least 1, got 0")
+}
+
 func TestDeleteAsset(t *testing.T) {
 	chaincodeStub := &mocks.ChaincodeStub{}
 	transactionContext := &mocks.TransactionContext{}
 	transactionContext.GetStubReturns(chaincodeStub)
 
-	asset := &chaincode.Asset{ID: "asset1"}
+	asset := &chaincode.Asset{McapID: "asset1"}
 	bytes, err := json.Marshal(asset)
 	require.NoError(t, err)
 
@@ -132,53 +349,118 @@ func TestDeleteAsset(t *testing.T) {
 	require.EqualError(t, err, "failed to read from world state: unable to retrieve asset")
 }
 
-func TestTransferAsset(t *testing.T) {
+func TestRevokeAsset(t *testing.T) {
 	chaincodeStub := &mocks.ChaincodeStub{}
 	transactionContext := &mocks.TransactionContext{}
 	transactionContext.GetStubReturns(chaincodeStub)
+	transactionContext.GetClientIdentityReturns(clientIdentity{ous: []string{"client"}})
+	chaincodeStub.GetTxTimestampReturns(timestamppb.New(time.Date(2025, 3, 2, 8, 0, 0, 0, time.UTC)), nil)
 
-	asset := &chaincode.Asset{ID: "asset1"}
-	bytes, err := json.Marshal(asset)
-	require.NoError(t, err)
+	assetTransfer := chaincode.SmartContract{}
+	err := assetTransfer.RevokeAsset(transactionContext, "asset1", "sensor fault")
+	require.EqualError(t, err, "the client is not an admin")
 
+	transactionContext.GetClientIdentityReturns(clientIdentity{ous: []string{"admin"}})
+	err = assetTransfer.RevokeAsset(transactionContext, "asset1", "sensor fault")
+	require.EqualError(t, err, "the asset asset1 does not exist")
+
+	bytes, err := json.Marshal(chaincode.Asset{McapID: "asset1", Hash: "abc123"})
+	require.NoError(t, err)
 	chaincodeStub.GetStateReturns(bytes, nil)
-	assetTransfer := chaincode.SmartContract{}
-	_, err = assetTransfer.TransferAsset(transactionContext, "", "")
+	err = assetTransfer.RevokeAsset(transactionContext, "asset1", "sensor fault")
 	require.NoError(t, err)
 
-	chaincodeStub.GetStateReturns(nil, fmt.Errorf("unable to retrieve asset"))
-	_, err = assetTransfer.TransferAsset(transactionContext, "", "")
-	require.EqualError(t, err, "failed to read from world state: unable to retrieve asset")
+	_, value := chaincodeStub.PutStateArgsForCall(0)
+	var stored chaincode.Asset
+	require.NoError(t, json.Unmarshal(value, &stored))
+	require.Equal(t, &chaincode.Revocation{
+		Reason:    "sensor fault",
+		RevokedAt: "2025-03-02T08:00:00Z",
+		RevokedBy: "x509::CN=Admin@org1.example.com",
+	}, stored.Revocation)
+
+	name, payload := chaincodeStub.SetEventArgsForCall(0)
+	require.Equal(t, chaincode.EventAssetRevoked, name)
+	var event chaincode.AssetEvent
+	require.NoError(t, json.Unmarshal(payload, &event))
+	require.Equal(t, &stored, event.Asset)
+
+	chaincodeStub.GetStateReturns(value, nil)
+	err = assetTransfer.RevokeAsset(transactionContext, "asset1", "again")
+	require.EqualError(t, err, "the asset asset1 is already revoked")
 }
 
-func TestGetAllAssets(t *testing.T) {
-	asset := &chaincode.Asset{ID: "asset1"}
+func TestGetAssetHistory(t *testing.T) {
+	asset := &chaincode.Asset{McapID: "asset1", Hash: "abc123"}
 	bytes, err := json.Marshal(asset)
 	require.NoError(t, err)
 
-	iterator := &mocks.StateQueryIterator{}
+	iterator := &mocks.HistoryQueryIterator{}
 	iterator.HasNextReturnsOnCall(0, true)
-	iterator.HasNextReturnsOnCall(1, false)
-	iterator.NextReturns(&queryresult.KV{Value: bytes}, nil)
+	iterator.HasNextReturnsOnCall(1, true)
+	iterator.HasNextReturnsOnCall(2, false)
+	iterator.NextReturnsOnCall(0, &queryresult.KeyModification{TxId: "tx2", IsDelete: true, Timestamp: timestamppb.New(time.Date(2025, 3, 2, 8, 0, 0, 0, time.UTC))}, nil)
+	iterator.NextReturnsOnCall(1, &queryresult.KeyModification{TxId: "tx1", Value: bytes, Timestamp: timestamppb.New(time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC))}, nil)
 
 	chaincodeStub := &mocks.ChaincodeStub{}
 	transactionContext := &mocks.TransactionContext{}
 	transactionContext.GetStubReturns(chaincodeStub)
+	chaincodeStub.GetHistoryForKeyReturns(iterator, nil)
 
-	chaincodeStub.GetStateByRangeReturns(iterator, nil)
 	assetTransfer := &chaincode.SmartContract{}
-	assets, err := assetTransfer.GetAllAssets(transactionContext)
+	history, err := assetTransfer.GetAssetHistory(transactionContext, "asset1")
 	require.NoError(t, err)
-	require.Equal(t, []*chaincode.Asset{asset}, assets)
+	require.Equal(t, []*chaincode.HistoryEntry{
+		{IsDelete: true, Timestamp: "2025-03-02T08:00:00Z", TxID: "tx2"},
+		{Asset: asset, Timestamp: "2025-03-01T12:00:00Z", TxID: "tx1"},
+	}, history)
+
+	chaincodeStub.GetHistoryForKeyReturns(nil, fmt.Errorf("history database disabled"))
+	_, err = assetTransfer.GetAssetHistory(transactionContext, "asset1")
+	require.EqualError(t, err, "failed to read asset history: history database disabled")
+}
+
+func TestQueryAssets(t *testing.T) {
+	assets := []*chaincode.Asset{
+		{McapID: "asset1", Datetime: "2025-03-01T12:00:00Z", Operation: "op1", Project: "line-7"},
+		{McapID: "asset2", Datetime: "2025-03-02T12:00:00Z", Operation: "op2", Project: "line-7"},
+		{McapID: "asset3", Datetime: "2025-03-03T12:00:00Z", Operation: "op1", Project: "line-9"},
+	}
+	newIterator := func() *mocks.StateQueryIterator {
+		iterator := &mocks.StateQueryIterator{}
+		for i, asset := range assets {
+			bytes, err := json.Marshal(asset)
+			require.NoError(t, err)
+			iterator.HasNextReturnsOnCall(i, true)
+			iterator.NextReturnsOnCall(i, &queryresult.KV{Key: asset.McapID, Value: bytes}, nil)
+		}
+		return iterator
+	}
+
+	chaincodeStub := &mocks.ChaincodeStub{}
+	transactionContext := &mocks.TransactionContext{}
+	transactionContext.GetStubReturns(chaincodeStub)
+	assetTransfer := &chaincode.SmartContract{}
+
+	chaincodeStub.GetStateByRangeReturns(newIterator(), nil)
+	all, err := assetTransfer.GetAllAssets(transactionContext)
+	require.NoError(t, err)
+	require.Equal(t, assets, all)
+
+	chaincodeStub.GetStateByRangeReturns(newIterator(), nil)
+	matched, err := assetTransfer.QueryAssets(transactionContext, "line-7", "", "2025-03-02T00:00:00Z", "")
+	require.NoError(t, err)
+	require.Equal(t, assets[1:2], matched)
+
+	chaincodeStub.GetStateByRangeReturns(newIterator(), nil)
+	matched, err = assetTransfer.QueryAssets(transactionContext, "", "op1", "", "2025-03-03T12:00:00Z")
+	require.NoError(t, err)
+	require.Equal(t, assets[:1], matched)
 
-	iterator.HasNextReturns(true)
-	iterator.NextReturns(nil, fmt.Errorf("failed retrieving next item"))
-	assets, err = assetTransfer.GetAllAssets(transactionContext)
-	require.EqualError(t, err, "failed retrieving next item")
-	require.Nil(t, assets)
+	_, err = assetTransfer.QueryAssets(transactionContext, "", "", "last week", "")
+	require.ErrorContains(t, err, `invalid from time "last week"`)
 
 	chaincodeStub.GetStateByRangeReturns(nil, fmt.Errorf("failed retrieving all assets"))
-	assets, err = assetTransfer.GetAllAssets(transactionContext)
-	require.EqualError(t, err, "failed retrieving all assets")
-	require.Nil(t, assets)
+	_, err = assetTransfer.GetAllAssets(transactionContext)
+	require.EqualError(t, err, "failed to read from world state: failed retrieving all assets")
 }
@@ -0,0 +1,107 @@
+package hashlib
+
+import (
+	"crypto/sha256"
+	"crypto/sha3"
+	"crypto/sha512"
+	"fmt"
+	"hash"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/zeebo/blake3"
+	"golang.org/x/crypto/blake2b"
+)
+
+// Identifiers of the built-in algorithms, as recorded with ledger assets.
+const (
+	SHA256     = "sha256"
+	SHA512     = "sha512"
+	SHA3_256   = "sha3-256"
+	BLAKE2b512 = "blake2b-512"
+	BLAKE3     = "blake3"
+)
+
+// DefaultAlgorithm is used when none is chosen, and is the algorithm of
+// every asset anchored before algorithms were recorded.
+const DefaultAlgorithm = SHA256
+
+// Hasher is a digest algorithm that files can be hashed and anchored with.
+type Hasher interface {
+	// ID is the stable identifier recorded with each ledger asset.
+	ID() string
+
+	// New returns a fresh hash.Hash computing the algorithm.
+	New() hash.Hash
+}
+
+// hasher is a Hasher backed by a constructor function.
+type hasher struct {
+	id      string
+	newHash func() hash.Hash
+}
+
+func (h hasher) ID() string     { return h.id }
+func (h hasher) New() hash.Hash { return h.newHash() }
+
+// NewHasher returns a Hasher identified by id that uses newHash.
+func NewHasher(id string, newHash func() hash.Hash) Hasher {
+	return hasher{id: id, newHash: newHash}
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]Hasher{}
+)
+
+func init() {
+	Register(NewHasher(SHA256, sha256.New))
+	Register(NewHasher(SHA512, sha512.New))
+	Register(NewHasher(SHA3_256, func() hash.Hash { return sha3.New256() }))
+	Register(NewHasher(BLAKE2b512, func() hash.Hash {
+		// Only a key longer than 64 bytes makes New512 fail.
+		h, _ := blake2b.New512(nil)
+		return h
+	}))
+	Register(NewHasher(BLAKE3, func() hash.Hash { return blake3.New() }))
+}
+
+// Register makes h available to Lookup under h.ID(). It panics if the
+// identifier is already taken, since anchors made with one algorithm must
+// never be checked with another.
+func Register(h Hasher) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if _, ok := registry[h.ID()]; ok {
+		panic("hashlib: algorithm registered twice: " + h.ID())
+	}
+	registry[h.ID()] = h
+}
+
+// Lookup returns the algorithm with identifier id. An empty id is the
+// DefaultAlgorithm.
+func Lookup(id string) (Hasher, error) {
+	if id == "" {
+		id = DefaultAlgorithm
+	}
+	registryMu.RLock()
+	h, ok := registry[id]
+	registryMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown hash algorithm %q, expected one of %s", id, strings.Join(Algorithms(), ", "))
+	}
+	return h, nil
+}
+
+// Algorithms returns the identifiers of every registered algorithm, sorted.
+func Algorithms() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	ids := make([]string, 0, len(registry))
+	for id := range registry {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
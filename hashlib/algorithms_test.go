@@ -0,0 +1,60 @@
+package hashlib
+
+import (
+	"context"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// Test every built-in algorithm against its "abc" test vector
+func TestAlgorithms(t *testing.T) {
+	vectors := map[string]string{
+		SHA256:     "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad",
+		SHA512:     "ddaf35a193617abacc417349ae20413112e6fa4e89a97ea20a9eeee64b55d39a2192992a274fc1a836ba3c23a3feebbd454d4423643ce80e2a9ac94fa54ca49f",
+		SHA3_256:   "3a985da74fe225b2045c172d6bd390bd855f086e3e9d525b46bfe24511431532",
+		BLAKE2b512: "ba80a53f981c4d0d6a2797b69f12f6e94c212f14685ac4b74b12bb6fdbffa2d17d87c5392aab792dc252d5de4533cc9518d38aa8dbf1925ab92386edd4009923",
+		BLAKE3:     "6437b3ac38465133ffb63b75273a8db548c558465d79db03fd359c6cd5bd9d85",
+	}
+	testFile := filepath.Join(t.TempDir(), "abc.mcap")
+	if err := os.WriteFile(testFile, []byte("abc"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	for id, want := range vectors {
+		h, err := Lookup(id)
+		if err != nil {
+			t.Fatalf("Expected %s to be registered: %v", id, err)
+		}
+		d := h.New()
+		d.Write([]byte("abc"))
+		if got := hex.EncodeToString(d.Sum(nil)); got != want {
+			t.Errorf("Expected %s(abc) = %s, got %s", id, want, got)
+		}
+		if got, err := HashFileWith(context.Background(), h, testFile, nil); err != nil || got != want {
+			t.Errorf("Expected %s of the file to be %s, got %s, %v", id, want, got, err)
+		}
+	}
+	if len(Algorithms()) != len(vectors) {
+		t.Errorf("Expected %d algorithms, got %v", len(vectors), Algorithms())
+	}
+}
+
+// Test the default and unknown algorithms, and duplicate registration
+func TestLookup(t *testing.T) {
+	if h, err := Lookup(""); err != nil || h.ID() != SHA256 {
+		t.Errorf("Expected the default algorithm to be sha256, got %v, %v", h, err)
+	}
+	if _, err := Lookup("md5"); err == nil {
+		t.Errorf("Expected an unknown algorithm to fail")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Expected registering sha256 twice to panic")
+		}
+	}()
+	h, _ := Lookup(SHA256)
+	Register(h)
+}
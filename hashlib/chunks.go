@@ -0,0 +1,245 @@
+package hashlib
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/Octavian-Anghel/Capstone-Project/mcap"
+)
+
+// Domain separation prefixes, so a leaf can never be passed off as an
+// inner node or the other way round.
+const (
+	leafPrefix = 0x00
+	nodePrefix = 0x01
+)
+
+// ChunkLeaf is one Chunk record of an MCAP file and the time range of the
+// messages inside it, in nanoseconds since the epoch. Hash is the SHA-256
+// of the leaf prefix followed by the whole record as stored in the file.
+type ChunkLeaf struct {
+	Index     int    `json:"index"`
+	StartTime uint64 `json:"startTime"`
+	EndTime   uint64 `json:"endTime"`
+	Offset    uint64 `json:"offset"`
+	Length    uint64 `json:"length"`
+	Hash      string `json:"hash"`
+}
+
+// ChunkTree is a Merkle tree over the Chunk records of an MCAP file in
+// file order. An odd node at the end of a level is promoted unchanged.
+// Records outside chunks are not covered; the whole-file hash is.
+type ChunkTree struct {
+	Root   string      `json:"root"`
+	Leaves []ChunkLeaf `json:"leaves"`
+
+	levels [][][]byte
+}
+
+// ChunkProof shows that one chunk belongs to a tree of LeafCount leaves.
+// Siblings are the hex hashes needed to rebuild the root, bottom up. Chunk
+// optionally carries the record itself, so the proof can be checked by
+// someone who has no other part of the file.
+type ChunkProof struct {
+	Leaf      ChunkLeaf `json:"leaf"`
+	LeafCount int       `json:"leafCount"`
+	Siblings  []string  `json:"siblings"`
+	Chunk     []byte    `json:"chunk,omitempty"`
+}
+
+// ErrNoChunks is returned by HashChunks for files whose summary indexes no
+// chunks.
+var ErrNoChunks = errors.New("file has no chunks")
+
+// HashChunks builds the chunk tree of an MCAP file from the ChunkIndex
+// records in its summary section, reporting progress over the chunk bytes
+// and stopping early when ctx is cancelled. Files without a summary fail
+// with an error matching mcap.ErrNoSummary.
+func HashChunks(ctx context.Context, filePath string, progress ProgressFunc) (*ChunkTree, error) {
+	file, err := os.Open(filepath.Clean(filePath))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	indexes, err := mcap.ReadChunkIndexes(file)
+	if err != nil {
+		return nil, err
+	}
+	if len(indexes) == 0 {
+		return nil, fmt.Errorf("%s: %w", filePath, ErrNoChunks)
+	}
+	sort.Slice(indexes, func(a, b int) bool {
+		return indexes[a].ChunkStartOffset < indexes[b].ChunkStartOffset
+	})
+
+	var total int64
+	for _, ci := range indexes {
+		total += int64(ci.ChunkLength)
+	}
+	tracker := &progressReader{ctx: ctx, total: total, progress: progress}
+
+	tree := &ChunkTree{Leaves: make([]ChunkLeaf, len(indexes))}
+	hashes := make([][]byte, len(indexes))
+	for i, ci := range indexes {
+		tracker.r = io.NewSectionReader(file, int64(ci.ChunkStartOffset), int64(ci.ChunkLength))
+		h := sha256.New()
+		h.Write([]byte{leafPrefix})
+		var op [1]byte
+		if _, err := io.ReadFull(tracker, op[:]); err != nil {
+			return nil, fmt.Errorf("failed to read chunk %d: %w", i, err)
+		}
+		if mcap.Opcode(op[0]) != mcap.OpChunk {
+			return nil, fmt.Errorf("chunk index %d does not point at a chunk record", i)
+		}
+		h.Write(op[:])
+		n, err := io.Copy(h, tracker)
+		if err != nil {
+			return nil, fmt.Errorf("failed to hash chunk %d: %w", i, err)
+		}
+		if uint64(n)+1 != ci.ChunkLength {
+			return nil, fmt.Errorf("chunk %d is truncated", i)
+		}
+
+		hashes[i] = h.Sum(nil)
+		tree.Leaves[i] = ChunkLeaf{
+			Index:     i,
+			StartTime: ci.MessageStartTime,
+			EndTime:   ci.MessageEndTime,
+			Offset:    ci.ChunkStartOffset,
+			Length:    ci.ChunkLength,
+			Hash:      hex.EncodeToString(hashes[i]),
+		}
+	}
+
+	tree.levels = buildLevels(hashes)
+	tree.Root = hex.EncodeToString(tree.levels[len(tree.levels)-1][0])
+	return tree, nil
+}
+
+func buildLevels(leaves [][]byte) [][][]byte {
+	levels := [][][]byte{leaves}
+	for level := leaves; len(level) > 1; {
+		next := make([][]byte, 0, (len(level)+1)/2)
+		for i := 0; i < len(level); i += 2 {
+			if i+1 == len(level) {
+				next = append(next, level[i])
+				continue
+			}
+			next = append(next, hashNode(level[i], level[i+1]))
+		}
+		levels = append(levels, next)
+		level = next
+	}
+	return levels
+}
+
+func hashNode(left []byte, right []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte{nodePrefix})
+	h.Write(left)
+	h.Write(right)
+	return h.Sum(nil)
+}
+
+// Proof returns the proof for leaf i.
+func (t *ChunkTree) Proof(i int) (ChunkProof, error) {
+	if i < 0 || i >= len(t.Leaves) {
+		return ChunkProof{}, fmt.Errorf("no chunk %d in a tree of %d", i, len(t.Leaves))
+	}
+	p := ChunkProof{Leaf: t.Leaves[i], LeafCount: len(t.Leaves)}
+	for _, level := range t.levels[:len(t.levels)-1] {
+		if sibling := i ^ 1; sibling < len(level) {
+			p.Siblings = append(p.Siblings, hex.EncodeToString(level[sibling]))
+		}
+		i /= 2
+	}
+	return p, nil
+}
+
+// ProveRange returns proofs for every chunk holding messages logged between
+// start and end inclusive, in nanoseconds since the epoch.
+func (t *ChunkTree) ProveRange(start uint64, end uint64) ([]ChunkProof, error) {
+	var proofs []ChunkProof
+	for _, leaf := range t.Leaves {
+		if leaf.EndTime < start || leaf.StartTime > end {
+			continue
+		}
+		p, err := t.Proof(leaf.Index)
+		if err != nil {
+			return nil, err
+		}
+		proofs = append(proofs, p)
+	}
+	return proofs, nil
+}
+
+// ErrProofMismatch is returned when a chunk proof does not lead to the
+// expected root.
+var ErrProofMismatch = errors.New("chunk proof does not match the root")
+
+// VerifyChunkProof checks that chunk, a whole Chunk record, is leaf
+// p.Leaf of the tree with the given hex root, and that the record holds
+// the time range the leaf claims.
+func VerifyChunkProof(root string, p ChunkProof, chunk []byte) error {
+	if len(chunk) < 9+16 || mcap.Opcode(chunk[0]) != mcap.OpChunk {
+		return fmt.Errorf("chunk %d is not a chunk record", p.Leaf.Index)
+	}
+	body := chunk[9:]
+	if binary.LittleEndian.Uint64(body[0:8]) != p.Leaf.StartTime || binary.LittleEndian.Uint64(body[8:16]) != p.Leaf.EndTime {
+		return fmt.Errorf("chunk %d does not cover the claimed time range", p.Leaf.Index)
+	}
+
+	h := sha256.New()
+	h.Write([]byte{leafPrefix})
+	h.Write(chunk)
+	node := h.Sum(nil)
+	if p.Leaf.Hash != "" && p.Leaf.Hash != hex.EncodeToString(node) {
+		return fmt.Errorf("chunk %d: %w", p.Leaf.Index, ErrProofMismatch)
+	}
+
+	i, n := p.Leaf.Index, p.LeafCount
+	if i < 0 || i >= n {
+		return fmt.Errorf("chunk %d is outside a tree of %d", i, n)
+	}
+	siblings := p.Siblings
+	for ; n > 1; i, n = i/2, (n+1)/2 {
+		if i^1 >= n {
+			continue
+		}
+		if len(siblings) == 0 {
+			return fmt.Errorf("chunk %d: proof is missing siblings", p.Leaf.Index)
+		}
+		sibling, err := hex.DecodeString(siblings[0])
+		if err != nil {
+			return fmt.Errorf("chunk %d: invalid sibling hash: %w", p.Leaf.Index, err)
+		}
+		siblings = siblings[1:]
+		if i%2 == 0 {
+			node = hashNode(node, sibling)
+		} else {
+			node = hashNode(sibling, node)
+		}
+	}
+	if len(siblings) != 0 {
+		return fmt.Errorf("chunk %d: proof has extra siblings", p.Leaf.Index)
+	}
+
+	want, err := hex.DecodeString(root)
+	if err != nil {
+		return fmt.Errorf("invalid root: %w", err)
+	}
+	if !bytes.Equal(node, want) {
+		return fmt.Errorf("chunk %d: %w", p.Leaf.Index, ErrProofMismatch)
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package hashlib
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Octavian-Anghel/Capstone-Project/mcap"
+)
+
+// writeChunkedMCAP writes an MCAP file with one chunk per time range and a
+// summary section indexing them. It returns the chunk records.
+func writeChunkedMCAP(t *testing.T, filePath string, ranges [][2]uint64) [][]byte {
+	t.Helper()
+	le := binary.LittleEndian
+	var out bytes.Buffer
+	writeRecord := func(op mcap.Opcode, fields ...any) []byte {
+		var body bytes.Buffer
+		for _, f := range fields {
+			if s, ok := f.(string); ok {
+				binary.Write(&body, le, uint32(len(s)))
+				body.WriteString(s)
+				continue
+			}
+			binary.Write(&body, le, f)
+		}
+		start := out.Len()
+		out.WriteByte(byte(op))
+		binary.Write(&out, le, uint64(body.Len()))
+		out.Write(body.Bytes())
+		return out.Bytes()[start:]
+	}
+
+	out.Write(mcap.Magic)
+	var chunks [][]byte
+	var offsets []uint64
+	for i, r := range ranges {
+		records := []byte(fmt.Sprintf("messages %d", i))
+		offsets = append(offsets, uint64(out.Len()))
+		chunk := writeRecord(mcap.OpChunk, r[0], r[1], uint64(len(records)), uint32(0), "", uint64(len(records)), records)
+		chunks = append(chunks, bytes.Clone(chunk))
+	}
+	writeRecord(mcap.OpDataEnd, uint32(0))
+
+	summaryStart := uint64(out.Len())
+	for i, r := range ranges {
+		writeRecord(mcap.OpChunkIndex, r[0], r[1], offsets[i], uint64(len(chunks[i])), uint32(0), uint64(0), "", uint64(0), uint64(0))
+	}
+	writeRecord(mcap.OpFooter, summaryStart, uint64(0), uint32(0))
+	out.Write(mcap.Magic)
+
+	if err := os.WriteFile(filePath, out.Bytes(), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	return chunks
+}
+
+// Test building the chunk tree and proving each chunk against its root
+func TestHashChunks(t *testing.T) {
+	testFile := filepath.Join(t.TempDir(), "chunked.mcap")
+	ranges := [][2]uint64{{0, 9}, {10, 19}, {20, 29}, {30, 39}, {40, 49}}
+	chunks := writeChunkedMCAP(t, testFile, ranges)
+
+	var done, total int64
+	tree, err := HashChunks(context.Background(), testFile, func(d, t int64) { done, total = d, t })
+	if err != nil {
+		t.Fatalf("Error hashing chunks: %v", err)
+	}
+	if len(tree.Leaves) != len(ranges) || tree.Leaves[3].StartTime != 30 || tree.Leaves[3].EndTime != 39 {
+		t.Fatalf("Unexpected leaves %+v", tree.Leaves)
+	}
+	if done != total || total == 0 {
+		t.Errorf("Expected progress to reach %d, got %d", total, done)
+	}
+
+	for i, chunk := range chunks {
+		p, err := tree.Proof(i)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := VerifyChunkProof(tree.Root, p, chunk); err != nil {
+			t.Errorf("Expected chunk %d to verify, got %v", i, err)
+		}
+	}
+
+	proofs, err := tree.ProveRange(15, 25)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(proofs) != 2 || proofs[0].Leaf.Index != 1 || proofs[1].Leaf.Index != 2 {
+		t.Errorf("Expected proofs for chunks 1 and 2, got %+v", proofs)
+	}
+
+	tampered := bytes.Clone(chunks[2])
+	tampered[len(tampered)-1] ^= 0xff
+	p, _ := tree.Proof(2)
+	if err := VerifyChunkProof(tree.Root, p, tampered); !errors.Is(err, ErrProofMismatch) {
+		t.Errorf("Expected a tampered chunk to fail, got %v", err)
+	}
+	if err := VerifyChunkProof(tree.Root, p, chunks[1]); err == nil {
+		t.Errorf("Expected another chunk to fail")
+	}
+	p.Leaf.Hash = ""
+	p.Leaf.Index = 3
+	p.Leaf.StartTime, p.Leaf.EndTime = 20, 29
+	if err := VerifyChunkProof(tree.Root, p, chunks[2]); !errors.Is(err, ErrProofMismatch) {
+		t.Errorf("Expected a chunk moved to another leaf to fail, got %v", err)
+	}
+}
+
+// Test that files without a chunk index are reported
+func TestHashChunks_NoSummary(t *testing.T) {
+	testFile := filepath.Join(t.TempDir(), "plain.mcap")
+	content := append(append(bytes.Clone(mcap.Magic), "no summary here"...), mcap.Magic...)
+	if err := os.WriteFile(testFile, content, 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	if _, err := HashChunks(context.Background(), testFile, nil); !errors.Is(err, mcap.ErrNoSummary) {
+		t.Errorf("Expected an error for a file without a summary, got %v", err)
+	}
+
+	writeChunkedMCAP(t, testFile, nil)
+	if _, err := HashChunks(context.Background(), testFile, nil); !errors.Is(err, ErrNoChunks) {
+		t.Errorf("Expected an error for a file without chunks, got %v", err)
+	}
+}
@@ -1,11 +1,10 @@
 package hashlib
 
 import (
-	"crypto/sha256"
-	"encoding/hex"
+	"context"
 	"fmt"
 	"io"
-	"log"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"sync"
@@ -15,62 +14,69 @@ import (
 const NUM_THREADS = 4
 
 // GetSHA computes SHA256 hash for a chunk of a file
+//
+// Deprecated: GetSHA opens the file again for every chunk and can only log
+// its errors. Use HashReaderAt, which returns them.
 func GetSHA(filename string, startIndex int64, bytesPerThread int64, wg *sync.WaitGroup, hashResults chan<- string) {
 	defer wg.Done()
 
 	// Resolve and clean the absolute path
 	absPath, err := filepath.Abs(filename)
 	if err != nil {
-		log.Println("Failed to resolve absolute path:", err)
+		slog.Error("failed to resolve absolute path", "path", filename, "err", err)
 		return
 	}
 	cleanPath := filepath.Clean(absPath)
 
-	// Ensure file exists and is not a directory
-	fileInfo, err := os.Stat(cleanPath)
-	if err != nil || fileInfo.IsDir() {
-		log.Println("Invalid file path:", cleanPath)
-		return
-	}
-
 	// Open the file securely
 	file, err := os.Open(cleanPath) // Removed os.OpenFile for security
 	if err != nil {
-		log.Println("Error opening file:", err)
+		slog.Error("failed to open file", "path", cleanPath, "err", err)
 		return
 	}
 	defer file.Close()
 
-	_, err = file.Seek(startIndex, io.SeekStart)
-	if err != nil {
-		log.Println("Error seeking file:", err)
+	fileInfo, err := file.Stat()
+	if err != nil || fileInfo.IsDir() {
+		slog.Error("invalid file path", "path", cleanPath)
 		return
 	}
 
-	hasher := sha256.New()
-	buffer := make([]byte, 4096)
-	var totalRead int64
-
-	for totalRead < bytesPerThread {
-		n, err := file.Read(buffer)
-		if n > 0 {
-			hasher.Write(buffer[:n])
-			totalRead += int64(n)
-		}
-		if err == io.EOF || totalRead >= bytesPerThread {
-			break
-		} else if err != nil {
-			log.Println("Error reading file:", err)
-			return
-		}
+	// Chunks running past the end of the file are hashed up to it
+	length := max(min(bytesPerThread, fileInfo.Size()-startIndex), 0)
+	h, _ := Lookup(SHA256)
+	hash, err := hashRange(context.Background(), h, file, startIndex, length, nil)
+	if err != nil {
+		slog.Error("failed to read file", "path", cleanPath, "err", err)
+		return
 	}
 
-	hashResults <- hex.EncodeToString(hasher.Sum(nil))
-	log.Printf("Hashing complete for chunk starting at: %d\n", startIndex)
+	hashResults <- hash
+	slog.Debug("hashing complete for chunk", "path", cleanPath, "offset", startIndex)
 }
 
+// ProgressFunc is called as input is hashed with the number of bytes
+// hashed so far and the size of the input.
+type ProgressFunc func(done int64, total int64)
+
 // HashFile generates SHA256 hash of an entire file
 func HashFile(filePath string) (string, error) {
+	return HashFileContext(context.Background(), filePath, nil)
+}
+
+// HashFileContext generates the SHA256 hash of an entire file, reporting
+// progress after every read and stopping early when ctx is cancelled
+func HashFileContext(ctx context.Context, filePath string, progress ProgressFunc) (string, error) {
+	h, err := Lookup(DefaultAlgorithm)
+	if err != nil {
+		return "", err
+	}
+	return HashFileWith(ctx, h, filePath, progress)
+}
+
+// HashFileWith generates the hash of an entire file with algorithm h, like
+// HashFileContext
+func HashFileWith(ctx context.Context, h Hasher, filePath string, progress ProgressFunc) (string, error) {
 	// Resolve and clean the absolute path
 	absPath, err := filepath.Abs(filePath)
 	if err != nil {
@@ -91,11 +97,31 @@ func HashFile(filePath string) (string, error) {
 	}
 	defer file.Close()
 
-	hash := sha256.New()
-	_, err = io.Copy(hash, file)
+	hash, err := hashReader(ctx, h, file, fileInfo.Size(), progress)
 	if err != nil {
 		return "", fmt.Errorf("failed to hash file: %w", err)
 	}
+	return hash, nil
+}
 
-	return hex.EncodeToString(hash.Sum(nil)), nil
-}
\ No newline at end of file
+// progressReader reports the bytes read through it and fails once its
+// context is done.
+type progressReader struct {
+	ctx      context.Context
+	r        io.Reader
+	done     int64
+	total    int64
+	progress ProgressFunc
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	if err := p.ctx.Err(); err != nil {
+		return 0, err
+	}
+	n, err := p.r.Read(b)
+	p.done += int64(n)
+	if n > 0 && p.progress != nil {
+		p.progress(p.done, p.total)
+	}
+	return n, err
+}
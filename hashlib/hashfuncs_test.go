@@ -1,9 +1,13 @@
 package hashlib
 
 import (
+	"bytes"
+	"context"
 	"crypto/sha256"
 	"encoding/hex"
+	"errors"
 	"os"
+	"path/filepath"
 	"sync"
 	"testing"
 )
@@ -90,4 +94,31 @@ func TestParallelHashing(t *testing.T) {
 	if fullHash != expectedHash {
 		t.Errorf("Expected full hash %s, but got %s", expectedHash, fullHash)
 	}
-}
\ No newline at end of file
+}
+
+// Test progress reporting and cancellation of full-file hashing
+func TestHashFileContext(t *testing.T) {
+	testFile := filepath.Join(t.TempDir(), "progress.mcap")
+	content := bytes.Repeat([]byte("mcap"), 100000)
+	if err := os.WriteFile(testFile, content, 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	var done, total int64
+	hash, err := HashFileContext(context.Background(), testFile, func(d, t int64) { done, total = d, t })
+	if err != nil {
+		t.Fatalf("Error hashing file: %v", err)
+	}
+	if hash != computeSHA256(content) {
+		t.Errorf("Expected hash %s, but got %s", computeSHA256(content), hash)
+	}
+	if done != int64(len(content)) || total != int64(len(content)) {
+		t.Errorf("Expected progress %d of %d, got %d of %d", len(content), len(content), done, total)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	_, err = HashFileContext(ctx, testFile, func(int64, int64) { cancel() })
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected cancellation to stop hashing, got %v", err)
+	}
+}
@@ -0,0 +1,114 @@
+package hashlib
+
+import (
+	"context"
+	"encoding"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+)
+
+// ErrNotResumable is returned for algorithms whose state cannot be saved.
+var ErrNotResumable = errors.New("hash algorithm cannot save its state")
+
+// ErrTruncated is returned when an input is shorter than the part of it
+// already hashed, so it cannot be the input the hash was started on.
+var ErrTruncated = errors.New("input is shorter than the bytes already hashed")
+
+// Checkpoint is the saved state of an Incremental hash: the serialized
+// state of the algorithm after hashing the first Offset bytes of its input.
+type Checkpoint struct {
+	Algorithm string `json:"algorithm"`
+	Offset    int64  `json:"offset"`
+	State     []byte `json:"state"`
+}
+
+// Incremental hashes an input that grows over time, such as a recording
+// still being written. Each Update hashes only the bytes added since the
+// last one, and a Checkpoint lets a later process carry on from there.
+// The input must only ever be appended to.
+type Incremental struct {
+	hasher Hasher
+	hash   hash.Hash
+	offset int64
+}
+
+// NewIncremental starts an incremental hash with algorithm h, which must
+// implement encoding.BinaryMarshaler and encoding.BinaryUnmarshaler.
+func NewIncremental(h Hasher) (*Incremental, error) {
+	hash := h.New()
+	_, marshals := hash.(encoding.BinaryMarshaler)
+	_, unmarshals := hash.(encoding.BinaryUnmarshaler)
+	if !marshals || !unmarshals {
+		return nil, fmt.Errorf("%s: %w", h.ID(), ErrNotResumable)
+	}
+	return &Incremental{hasher: h, hash: hash}, nil
+}
+
+// Resume continues an incremental hash from a checkpoint.
+func Resume(c Checkpoint) (*Incremental, error) {
+	h, err := Lookup(c.Algorithm)
+	if err != nil {
+		return nil, err
+	}
+	inc, err := NewIncremental(h)
+	if err != nil {
+		return nil, err
+	}
+	if c.Offset < 0 {
+		return nil, fmt.Errorf("invalid checkpoint offset %d", c.Offset)
+	}
+	if err := inc.hash.(encoding.BinaryUnmarshaler).UnmarshalBinary(c.State); err != nil {
+		return nil, fmt.Errorf("invalid %s checkpoint: %w", c.Algorithm, err)
+	}
+	inc.offset = c.Offset
+	return inc, nil
+}
+
+// Algorithm returns the identifier of the algorithm being computed.
+func (inc *Incremental) Algorithm() string {
+	return inc.hasher.ID()
+}
+
+// Offset returns the number of input bytes hashed so far.
+func (inc *Incremental) Offset() int64 {
+	return inc.offset
+}
+
+// Update hashes the bytes of r between Offset and size, which is the
+// current length of the input, reporting progress over those bytes and
+// stopping early when ctx is cancelled. Bytes hashed before an error still
+// count towards Offset. An input shorter than Offset fails with
+// ErrTruncated.
+func (inc *Incremental) Update(ctx context.Context, r io.ReaderAt, size int64, progress ProgressFunc) error {
+	if size < inc.offset {
+		return fmt.Errorf("%w: %d bytes hashed, input has %d", ErrTruncated, inc.offset, size)
+	}
+	length := size - inc.offset
+	n, err := io.Copy(inc.hash, &progressReader{ctx: ctx, r: io.NewSectionReader(r, inc.offset, length), total: length, progress: progress})
+	inc.offset += n
+	if err != nil {
+		return fmt.Errorf("failed to hash input at %d: %w", inc.offset, err)
+	}
+	if n != length {
+		return fmt.Errorf("failed to hash input at %d: %w", inc.offset, io.ErrUnexpectedEOF)
+	}
+	return nil
+}
+
+// Checkpoint saves the state of the hash so it can be resumed later.
+func (inc *Incremental) Checkpoint() (Checkpoint, error) {
+	state, err := inc.hash.(encoding.BinaryMarshaler).MarshalBinary()
+	if err != nil {
+		return Checkpoint{}, fmt.Errorf("failed to save %s state: %w", inc.hasher.ID(), err)
+	}
+	return Checkpoint{Algorithm: inc.hasher.ID(), Offset: inc.offset, State: state}, nil
+}
+
+// Sum returns the hex digest of the bytes hashed so far. Further updates
+// may follow.
+func (inc *Incremental) Sum() string {
+	return hex.EncodeToString(inc.hash.Sum(nil))
+}
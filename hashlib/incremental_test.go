@@ -0,0 +1,82 @@
+package hashlib
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+// Test hashing a growing input across checkpoints
+func TestIncremental(t *testing.T) {
+	content := bytes.Repeat([]byte("mcap"), 10000)
+
+	for _, id := range []string{SHA256, SHA512, SHA3_256, BLAKE2b512} {
+		h, _ := Lookup(id)
+		inc, err := NewIncremental(h)
+		if err != nil {
+			t.Fatalf("Expected %s to be resumable: %v", id, err)
+		}
+		if err := inc.Update(context.Background(), bytes.NewReader(content), 15000, nil); err != nil {
+			t.Fatalf("Error hashing %s: %v", id, err)
+		}
+
+		// Save and restore the state the way a restarted daemon would
+		c, err := inc.Checkpoint()
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := json.Marshal(c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var restored Checkpoint
+		if err := json.Unmarshal(data, &restored); err != nil {
+			t.Fatal(err)
+		}
+		inc, err = Resume(restored)
+		if err != nil {
+			t.Fatalf("Error resuming %s: %v", id, err)
+		}
+		if inc.Offset() != 15000 || inc.Algorithm() != id {
+			t.Errorf("Expected %s at 15000, got %s at %d", id, inc.Algorithm(), inc.Offset())
+		}
+
+		var done int64
+		if err := inc.Update(context.Background(), bytes.NewReader(content), int64(len(content)), func(d, _ int64) { done = d }); err != nil {
+			t.Fatalf("Error hashing %s: %v", id, err)
+		}
+		if done != int64(len(content))-15000 {
+			t.Errorf("Expected only the new %d bytes to be read, got %d", len(content)-15000, done)
+		}
+		want, _ := HashReader(context.Background(), bytes.NewReader(content), Options{Hasher: h})
+		if inc.Sum() != want {
+			t.Errorf("Expected %s digest %s, got %s", id, want, inc.Sum())
+		}
+	}
+}
+
+// Test the errors of incremental hashing
+func TestIncremental_Errors(t *testing.T) {
+	blake3, _ := Lookup(BLAKE3)
+	if _, err := NewIncremental(blake3); !errors.Is(err, ErrNotResumable) {
+		t.Errorf("Expected BLAKE3 not to be resumable, got %v", err)
+	}
+
+	h, _ := Lookup(SHA256)
+	inc, _ := NewIncremental(h)
+	if err := inc.Update(context.Background(), bytes.NewReader([]byte("abcdef")), 6, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := inc.Update(context.Background(), bytes.NewReader([]byte("abc")), 3, nil); !errors.Is(err, ErrTruncated) {
+		t.Errorf("Expected a shorter input to fail, got %v", err)
+	}
+
+	if _, err := Resume(Checkpoint{Algorithm: SHA256, State: []byte("garbage")}); err == nil {
+		t.Errorf("Expected an invalid state to fail")
+	}
+	if _, err := Resume(Checkpoint{Algorithm: "md5"}); err == nil {
+		t.Errorf("Expected an unknown algorithm to fail")
+	}
+}
@@ -0,0 +1,142 @@
+package hashlib
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// Options configures HashReader and HashReaderAt. The zero value hashes
+// with the DefaultAlgorithm in one part and reports no progress.
+type Options struct {
+	// Hasher is the algorithm to hash with. Nil means DefaultAlgorithm.
+	Hasher Hasher
+
+	// Progress is called after every read with the bytes hashed so far
+	// and the input size, which is -1 when HashReader does not know it.
+	// HashReaderAt never calls it from two goroutines at once.
+	Progress ProgressFunc
+
+	// Parts is the number of contiguous ranges HashReaderAt splits its
+	// input into and hashes concurrently. Values below 1 mean 1.
+	Parts int
+}
+
+func (o Options) hasher() (Hasher, error) {
+	if o.Hasher != nil {
+		return o.Hasher, nil
+	}
+	return Lookup(DefaultAlgorithm)
+}
+
+// RangeHash is the digest of Length bytes of an input starting at Offset.
+type RangeHash struct {
+	Offset int64  `json:"offset"`
+	Length int64  `json:"length"`
+	Hash   string `json:"hash"`
+}
+
+// HashReader hashes everything read from r until EOF and returns the hex
+// digest. It stops with ctx.Err() once ctx is done.
+func HashReader(ctx context.Context, r io.Reader, opts Options) (string, error) {
+	h, err := opts.hasher()
+	if err != nil {
+		return "", err
+	}
+	return hashReader(ctx, h, r, -1, opts.Progress)
+}
+
+func hashReader(ctx context.Context, h Hasher, r io.Reader, total int64, progress ProgressFunc) (string, error) {
+	hash := h.New()
+	if _, err := io.Copy(hash, &progressReader{ctx: ctx, r: r, total: total, progress: progress}); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// HashReaderAt hashes the first size bytes of r in opts.Parts contiguous
+// ranges, one goroutine each, and returns their digests in order. The
+// last range takes the bytes left over when size does not divide evenly,
+// so the ranges always cover the whole input. With one part the digest is
+// the same as HashReader's. The first error cancels the other ranges.
+func HashReaderAt(ctx context.Context, r io.ReaderAt, size int64, opts Options) ([]RangeHash, error) {
+	h, err := opts.hasher()
+	if err != nil {
+		return nil, err
+	}
+	if size < 0 {
+		return nil, fmt.Errorf("invalid size %d", size)
+	}
+	parts := int64(max(opts.Parts, 1))
+	if parts > size {
+		parts = max(size, 1)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		done     int64
+		firstErr error
+	)
+	results := make([]RangeHash, parts)
+	partSize := size / parts
+	for i := range results {
+		offset := int64(i) * partSize
+		length := partSize
+		if int64(i) == parts-1 {
+			length = size - offset
+		}
+		results[i] = RangeHash{Offset: offset, Length: length}
+
+		var progress ProgressFunc
+		if opts.Progress != nil {
+			var last int64
+			progress = func(d int64, _ int64) {
+				mu.Lock()
+				defer mu.Unlock()
+				done += d - last
+				last = d
+				opts.Progress(done, size)
+			}
+		}
+
+		wg.Add(1)
+		go func(rh *RangeHash) {
+			defer wg.Done()
+			var err error
+			rh.Hash, err = hashRange(ctx, h, r, rh.Offset, rh.Length, progress)
+			if err != nil {
+				mu.Lock()
+				if firstErr == nil {
+					firstErr = err
+					cancel()
+				}
+				mu.Unlock()
+			}
+		}(&results[i])
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	return results, nil
+}
+
+// hashRange hashes length bytes of r at offset, failing if r ends first.
+func hashRange(ctx context.Context, h Hasher, r io.ReaderAt, offset int64, length int64, progress ProgressFunc) (string, error) {
+	hash := h.New()
+	n, err := io.Copy(hash, &progressReader{ctx: ctx, r: io.NewSectionReader(r, offset, length), total: length, progress: progress})
+	if err != nil {
+		return "", fmt.Errorf("failed to hash range at %d: %w", offset, err)
+	}
+	if n != length {
+		return "", fmt.Errorf("failed to hash range at %d: %w", offset, io.ErrUnexpectedEOF)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
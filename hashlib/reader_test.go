@@ -0,0 +1,96 @@
+package hashlib
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+// Test hashing a stream with progress and cancellation
+func TestHashReader(t *testing.T) {
+	content := bytes.Repeat([]byte("mcap"), 100000)
+
+	var done, total int64
+	hash, err := HashReader(context.Background(), bytes.NewReader(content), Options{Progress: func(d, t int64) { done, total = d, t }})
+	if err != nil {
+		t.Fatalf("Error hashing reader: %v", err)
+	}
+	if hash != computeSHA256(content) {
+		t.Errorf("Expected hash %s, but got %s", computeSHA256(content), hash)
+	}
+	if done != int64(len(content)) || total != -1 {
+		t.Errorf("Expected progress %d of -1, got %d of %d", len(content), done, total)
+	}
+
+	blake3, _ := Lookup(BLAKE3)
+	if hash, _ := HashReader(context.Background(), strings.NewReader("abc"), Options{Hasher: blake3}); hash != "6437b3ac38465133ffb63b75273a8db548c558465d79db03fd359c6cd5bd9d85" {
+		t.Errorf("Unexpected BLAKE3 digest %s", hash)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	_, err = HashReader(ctx, bytes.NewReader(content), Options{Progress: func(int64, int64) { cancel() }})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected cancellation to stop hashing, got %v", err)
+	}
+
+	errBroken := errors.New("connection reset")
+	broken := io.MultiReader(strings.NewReader("abc"), iotest.ErrReader(errBroken))
+	if _, err := HashReader(context.Background(), broken, Options{}); !errors.Is(err, errBroken) {
+		t.Errorf("Expected the read error to be returned, got %v", err)
+	}
+}
+
+// Test hashing ranges of one reader concurrently
+func TestHashReaderAt(t *testing.T) {
+	content := []byte("0123456789")
+
+	var done, total int64
+	ranges, err := HashReaderAt(context.Background(), bytes.NewReader(content), int64(len(content)), Options{
+		Parts:    3,
+		Progress: func(d, t int64) { done, total = d, t },
+	})
+	if err != nil {
+		t.Fatalf("Error hashing ranges: %v", err)
+	}
+	want := []RangeHash{
+		{Offset: 0, Length: 3, Hash: computeSHA256(content[0:3])},
+		{Offset: 3, Length: 3, Hash: computeSHA256(content[3:6])},
+		{Offset: 6, Length: 4, Hash: computeSHA256(content[6:10])},
+	}
+	if len(ranges) != len(want) {
+		t.Fatalf("Expected %d ranges, got %+v", len(want), ranges)
+	}
+	for i := range want {
+		if ranges[i] != want[i] {
+			t.Errorf("Expected range %d to be %+v, got %+v", i, want[i], ranges[i])
+		}
+	}
+	if done != total || total != int64(len(content)) {
+		t.Errorf("Expected progress to reach %d, got %d of %d", len(content), done, total)
+	}
+
+	ranges, err = HashReaderAt(context.Background(), bytes.NewReader(content), int64(len(content)), Options{})
+	if err != nil || len(ranges) != 1 || ranges[0].Hash != computeSHA256(content) {
+		t.Errorf("Expected one range over the whole input, got %+v, %v", ranges, err)
+	}
+
+	ranges, err = HashReaderAt(context.Background(), bytes.NewReader(nil), 0, Options{Parts: 4})
+	if err != nil || len(ranges) != 1 || ranges[0].Hash != computeSHA256(nil) {
+		t.Errorf("Expected one empty range, got %+v, %v", ranges, err)
+	}
+
+	_, err = HashReaderAt(context.Background(), bytes.NewReader(content), 20, Options{Parts: 2})
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("Expected a short input to fail, got %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := HashReaderAt(ctx, bytes.NewReader(content), int64(len(content)), Options{Parts: 2}); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected cancellation to stop hashing, got %v", err)
+	}
+}
@@ -0,0 +1,263 @@
+package ledger
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/Octavian-Anghel/Capstone-Project/hashlib"
+	"github.com/hyperledger/fabric-gateway/pkg/client"
+	"github.com/hyperledger/fabric-protos-go-apiv2/peer"
+)
+
+// NewAsset holds the arguments of the CreateAsset transaction.
+type NewAsset struct {
+	// CaptureTime is when the recording was hashed. The contract stamps
+	// the asset with the transaction time itself and rejects capture times
+	// outside its skew window.
+	CaptureTime time.Time
+	Hash        string
+	McapID      string
+	Operation   string
+	Project     string
+	Path        string
+
+	// Algorithm identifies the digest algorithm of Hash, as registered
+	// with hashlib. It defaults to hashlib.DefaultAlgorithm.
+	Algorithm string
+
+	// ChunkRoot is the root of the recording's hashlib.ChunkTree, or empty
+	// when the recording has no chunk index.
+	ChunkRoot string
+
+	// Sensors is an optional summary of sensor readings taken during the
+	// recording.
+	Sensors *SensorSummary
+}
+
+// Asset is a recording anchored on the ledger.
+type Asset struct {
+	McapID      string      `json:"McapID"`
+	Hash        string      `json:"Hash"`
+	Datetime    string      `json:"Datetime"`
+	CaptureTime string      `json:"CaptureTime"`
+	Operation   string      `json:"Operation"`
+	Project     string      `json:"Project"`
+	Path        string      `json:"Path"`
+	Revocation  *Revocation `json:"Revocation,omitempty"`
+
+	// Algorithm is empty for assets anchored before algorithms were
+	// recorded; use HashAlgorithm to read it.
+	Algorithm string         `json:"Algorithm,omitempty"`
+	Sensors   *SensorSummary `json:"Sensors,omitempty"`
+
+	// ChunkRoot is the root of the recording's hashlib.ChunkTree. It is
+	// empty for recordings without a chunk index and for assets anchored
+	// before chunk roots were recorded.
+	ChunkRoot string `json:"ChunkRoot,omitempty"`
+}
+
+// HashAlgorithm returns the identifier of the algorithm the asset hash was
+// computed with.
+func (a *Asset) HashAlgorithm() string {
+	if a.Algorithm == "" {
+		return hashlib.DefaultAlgorithm
+	}
+	return a.Algorithm
+}
+
+// Revocation records why, when and by whom an asset was revoked.
+type Revocation struct {
+	Reason    string `json:"Reason"`
+	RevokedAt string `json:"RevokedAt"`
+	RevokedBy string `json:"RevokedBy"`
+}
+
+// SensorSummary holds sensor readings summarised over a recording. Source
+// says where they came from, such as "messages" or "sidecar".
+type SensorSummary struct {
+	Source   string          `json:"Source"`
+	Readings []SensorReading `json:"Readings"`
+}
+
+// SensorReading is the minimum, maximum and mean of one sensor value over
+// Count samples.
+type SensorReading struct {
+	Name  string  `json:"Name"`
+	Topic string  `json:"Topic,omitempty"`
+	Unit  string  `json:"Unit,omitempty"`
+	Count int64   `json:"Count"`
+	Min   float64 `json:"Min"`
+	Max   float64 `json:"Max"`
+	Mean  float64 `json:"Mean"`
+}
+
+// HistoryEntry is one committed change to an asset. Asset is nil for
+// deletions.
+type HistoryEntry struct {
+	TxID      string `json:"TxID"`
+	Timestamp string `json:"Timestamp"`
+	IsDelete  bool   `json:"IsDelete"`
+	Asset     *Asset `json:"Asset,omitempty"`
+}
+
+// Changes describes what each entry of an asset history, newest first,
+// did to the asset: "create", "update", "revoke" or "delete".
+func Changes(history []HistoryEntry) []string {
+	changes := make([]string, len(history))
+	for i, entry := range history {
+		switch {
+		case entry.IsDelete:
+			changes[i] = "delete"
+		case i == len(history)-1:
+			changes[i] = "create"
+		case entry.Asset.Revocation != nil && (history[i+1].Asset == nil || history[i+1].Asset.Revocation == nil):
+			changes[i] = "revoke"
+		default:
+			changes[i] = "update"
+		}
+	}
+	return changes
+}
+
+// AssetPage is one page of assets in key order. Bookmark resumes the
+// listing after the page and is empty once every asset has been returned.
+type AssetPage struct {
+	Assets       []Asset `json:"Assets"`
+	Bookmark     string  `json:"Bookmark"`
+	FetchedCount int32   `json:"FetchedCount"`
+}
+
+// AssetQuery filters QueryAssets. Unset fields match every asset.
+type AssetQuery struct {
+	Project   string
+	Operation string
+
+	// From and To bound the asset Datetime, From inclusive and To
+	// exclusive.
+	From time.Time
+	To   time.Time
+}
+
+// CreateAsset anchors a recording hash on the ledger and waits for the
+// transaction to commit. An asset that already exists fails with an error
+// matching ErrAssetExists.
+func (c *Client) CreateAsset(ctx context.Context, a NewAsset) (*TxStatus, error) {
+	var sensors string
+	if a.Sensors != nil {
+		sensorsJSON, err := json.Marshal(a.Sensors)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode sensor summary: %w", err)
+		}
+		sensors = string(sensorsJSON)
+	}
+	algorithm := a.Algorithm
+	if algorithm == "" {
+		algorithm = hashlib.DefaultAlgorithm
+	}
+	return c.Submit(ctx, "CreateAsset",
+		a.Hash, a.McapID, a.Operation, a.Project, a.Path, a.CaptureTime.Format(time.RFC3339), sensors, algorithm, a.ChunkRoot,
+	)
+}
+
+// RevokeAsset marks an asset as revoked. It requires an admin identity.
+func (c *Client) RevokeAsset(ctx context.Context, mcapID string, reason string) (*TxStatus, error) {
+	return c.Submit(ctx, "RevokeAsset", mcapID, reason)
+}
+
+// ReadAsset returns an asset. An asset that does not exist fails with an
+// error matching ErrAssetNotFound.
+func (c *Client) ReadAsset(ctx context.Context, mcapID string) (*Asset, error) {
+	var asset *Asset
+	err := c.evaluateJSON(ctx, &asset, "ReadAsset", mcapID)
+	return asset, err
+}
+
+// ReadAssets returns the assets with the given IDs that exist, in one
+// evaluation.
+func (c *Client) ReadAssets(ctx context.Context, mcapIDs []string) ([]Asset, error) {
+	ids, err := json.Marshal(mcapIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	var assets []Asset
+	err = c.evaluateJSON(ctx, &assets, "ReadAssets", string(ids))
+	return assets, err
+}
+
+// AssetHistory returns every committed change to an asset, newest first.
+func (c *Client) AssetHistory(ctx context.Context, mcapID string) ([]HistoryEntry, error) {
+	var history []HistoryEntry
+	err := c.evaluateJSON(ctx, &history, "GetAssetHistory", mcapID)
+	return history, err
+}
+
+// CreatedWith returns the status of the transaction that created asset
+// mcapID when the asset was created with hash, or nil when it was created
+// with another hash. It tells a CreateAsset retry that failed with
+// ErrAssetExists whether an earlier attempt had committed after all. The
+// block number is not in the asset history and is left zero.
+func (c *Client) CreatedWith(ctx context.Context, mcapID, hash string) (*TxStatus, error) {
+	history, err := c.AssetHistory(ctx, mcapID)
+	if err != nil || len(history) == 0 {
+		return nil, err
+	}
+	created := history[len(history)-1]
+	if created.Asset == nil || created.Asset.Hash != hash {
+		return nil, nil
+	}
+	return &TxStatus{Status: client.Status{
+		Code:          peer.TxValidationCode_VALID,
+		Successful:    true,
+		TransactionID: created.TxID,
+	}}, nil
+}
+
+// QueryAssets returns the assets matching q.
+func (c *Client) QueryAssets(ctx context.Context, q AssetQuery) ([]Asset, error) {
+	var from, to string
+	if !q.From.IsZero() {
+		from = q.From.Format(time.RFC3339)
+	}
+	if !q.To.IsZero() {
+		to = q.To.Format(time.RFC3339)
+	}
+
+	var assets []Asset
+	err := c.evaluateJSON(ctx, &assets, "QueryAssets", q.Project, q.Operation, from, to)
+	return assets, err
+}
+
+// AssetsPage returns up to pageSize assets starting at bookmark, which is
+// empty for the first page.
+func (c *Client) AssetsPage(ctx context.Context, pageSize int32, bookmark string) (*AssetPage, error) {
+	var page AssetPage
+	err := c.evaluateJSON(ctx, &page, "GetAssetsPage", strconv.Itoa(int(pageSize)), bookmark)
+	return &page, err
+}
+
+// AllAssets returns every asset on the ledger.
+func (c *Client) AllAssets(ctx context.Context) ([]Asset, error) {
+	var assets []Asset
+	err := c.evaluateJSON(ctx, &assets, "GetAllAssets")
+	return assets, err
+}
+
+// evaluateJSON evaluates a query transaction and decodes its JSON result
+// into v. Empty results leave v unchanged.
+func (c *Client) evaluateJSON(ctx context.Context, v any, name string, args ...string) error {
+	result, err := c.Evaluate(ctx, name, args...)
+	if err != nil {
+		return err
+	}
+	if len(result) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(result, v); err != nil {
+		return fmt.Errorf("failed to decode %s result: %w", name, err)
+	}
+	return nil
+}
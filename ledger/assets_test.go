@@ -0,0 +1,21 @@
+package ledger
+
+import (
+	"slices"
+	"testing"
+)
+
+// Test naming the changes in an asset history
+func TestChanges(t *testing.T) {
+	revoked := &Asset{Hash: "abc", Revocation: &Revocation{Reason: "sensor fault"}}
+	history := []HistoryEntry{
+		{IsDelete: true},
+		{Asset: revoked},
+		{Asset: revoked},
+		{Asset: &Asset{Hash: "abc"}},
+	}
+	want := []string{"delete", "update", "revoke", "create"}
+	if got := Changes(history); !slices.Equal(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
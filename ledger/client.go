@@ -0,0 +1,162 @@
+package ledger
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/Octavian-Anghel/Capstone-Project/wallet"
+	"github.com/hyperledger/fabric-gateway/pkg/client"
+)
+
+// Client wraps Gateway connections to the MCAP chaincode through one or
+// more peers. Failures are returned as *Error and transient ones are
+// retried per Config.Retry, moving to another peer when the current one
+// is unreachable.
+type Client struct {
+	cfg      Config
+	identity *wallet.Identity
+	peers    []*gatewayPeer
+	active   atomic.Int32
+
+	// OnRetry, if set, is called before each retry of a failed call.
+	OnRetry func(err error, attempt int, wait time.Duration)
+
+	// OnFailover, if set, is called when traffic moves between peers.
+	OnFailover func(from, to string)
+}
+
+// TxStatus is the commit status of a transaction and the peer it was
+// submitted through.
+type TxStatus struct {
+	client.Status
+	Peer string
+}
+
+// Connect loads the client identity, enrolling it with the CA if needed,
+// and opens connections to the gateway peers.
+func Connect(ctx context.Context, cfg Config) (*Client, error) {
+	if len(cfg.Peers) == 0 {
+		return nil, fmt.Errorf("no gateway peers configured")
+	}
+
+	provider, err := wallet.New(cfg.Identity)
+	if err != nil {
+		return nil, err
+	}
+	id, err := wallet.Load(ctx, provider)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load identity: %w", err)
+	}
+
+	c := &Client{cfg: cfg, identity: id}
+	for _, pc := range cfg.Peers {
+		p, err := dialPeer(cfg, pc, id, signWith(id.Sign))
+		if err != nil {
+			c.Close()
+			return nil, err
+		}
+		c.peers = append(c.peers, p)
+	}
+	return c, nil
+}
+
+// Close releases the gateways, their gRPC connections and the signer.
+func (c *Client) Close() error {
+	for _, p := range c.peers {
+		p.close()
+	}
+	return c.identity.Close()
+}
+
+// Identity returns the client identity. Long-running callers should keep
+// it fresh with Identity().Run.
+func (c *Client) Identity() *wallet.Identity {
+	return c.identity
+}
+
+// Peer returns the name of the peer transactions are currently sent to.
+func (c *Client) Peer() string {
+	return c.peers[c.active.Load()].name
+}
+
+// Submit submits a transaction and waits for it to commit, retrying
+// transient failures. Once a transaction has been submitted, failures to
+// read its commit status are retried by asking again for the status of
+// the same transaction, which may already have committed, rather than by
+// submitting a new one.
+func (c *Client) Submit(ctx context.Context, name string, args ...string) (*TxStatus, error) {
+	var (
+		txStatus *TxStatus
+		pending  []byte // commit status request of the submitted transaction
+	)
+	err := c.cfg.Retry.Do(ctx, func() error {
+		p := c.pick()
+		var (
+			status *client.Status
+			err    error
+		)
+		if pending != nil {
+			status, pending, err = statusOnce(ctx, p.gateway, pending)
+		} else {
+			status, pending, err = submitOnce(ctx, p.contract, name, args)
+		}
+		if status != nil {
+			txStatus = &TxStatus{Status: *status, Peer: p.name}
+		}
+		c.failed(p, err)
+		return err
+	}, c.OnRetry)
+	return txStatus, err
+}
+
+// submitOnce submits a transaction and waits for its commit status. When
+// the transaction was submitted but its status could not be read, the
+// commit status request is returned so it can be asked again.
+func submitOnce(ctx context.Context, contract *client.Contract, name string, args []string) (*client.Status, []byte, error) {
+	_, commit, err := contract.SubmitAsyncWithContext(ctx, name, client.WithArguments(args...))
+	if err != nil {
+		return nil, nil, classify(err, StageEndorse)
+	}
+	return waitForCommit(ctx, commit)
+}
+
+// statusOnce asks gateway for the commit status of an already submitted
+// transaction, from the request returned by submitOnce.
+func statusOnce(ctx context.Context, gateway *client.Gateway, request []byte) (*client.Status, []byte, error) {
+	commit, err := gateway.NewCommit(request)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to rebuild commit status request: %w", err)
+	}
+	return waitForCommit(ctx, commit)
+}
+
+func waitForCommit(ctx context.Context, commit *client.Commit) (*client.Status, []byte, error) {
+	txStatus, err := commit.StatusWithContext(ctx)
+	if err != nil {
+		// Without the request the status cannot be asked again and a retry
+		// submits the transaction anew.
+		request, _ := commit.Bytes()
+		return nil, request, classify(err, StageCommitStatus)
+	}
+	if !txStatus.Successful {
+		return txStatus, nil, commitFailure(txStatus)
+	}
+	return txStatus, nil, nil
+}
+
+// Evaluate runs a query transaction on a gateway peer, retrying
+// transient failures.
+func (c *Client) Evaluate(ctx context.Context, name string, args ...string) ([]byte, error) {
+	var result []byte
+	err := c.cfg.Retry.Do(ctx, func() error {
+		p := c.pick()
+		var err error
+		result, err = p.contract.EvaluateWithContext(ctx, name, client.WithArguments(args...))
+		err = classify(err, StageEvaluate)
+		c.failed(p, err)
+		return err
+	}, c.OnRetry)
+	return result, err
+}
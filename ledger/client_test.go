@@ -0,0 +1,107 @@
+package ledger
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-gateway/pkg/client"
+	"github.com/hyperledger/fabric-protos-go-apiv2/common"
+	"github.com/hyperledger/fabric-protos-go-apiv2/gateway"
+	"github.com/hyperledger/fabric-protos-go-apiv2/peer"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// fakeGateway endorses and accepts every transaction and fails the first
+// commitStatusFailures commit status requests.
+type fakeGateway struct {
+	gateway.UnimplementedGatewayServer
+
+	mu                   sync.Mutex
+	submitted            int
+	statusRequests       int
+	commitStatusFailures int
+}
+
+func (g *fakeGateway) Endorse(context.Context, *gateway.EndorseRequest) (*gateway.EndorseResponse, error) {
+	data, _ := proto.Marshal(&peer.Transaction{Actions: []*peer.TransactionAction{{}}})
+	payload, _ := proto.Marshal(&common.Payload{Data: data})
+	return &gateway.EndorseResponse{PreparedTransaction: &common.Envelope{Payload: payload}}, nil
+}
+
+func (g *fakeGateway) Submit(context.Context, *gateway.SubmitRequest) (*gateway.SubmitResponse, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.submitted++
+	return &gateway.SubmitResponse{}, nil
+}
+
+func (g *fakeGateway) CommitStatus(context.Context, *gateway.SignedCommitStatusRequest) (*gateway.CommitStatusResponse, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.statusRequests++
+	if g.statusRequests <= g.commitStatusFailures {
+		return nil, status.Error(codes.Unavailable, "peer restarting")
+	}
+	return &gateway.CommitStatusResponse{Result: peer.TxValidationCode_VALID, BlockNumber: 7}, nil
+}
+
+type fakeIdentity struct{}
+
+func (fakeIdentity) MspID() string       { return "Org1MSP" }
+func (fakeIdentity) Credentials() []byte { return nil }
+
+// testGatewayPeer returns a peer whose gateway is served by g.
+func testGatewayPeer(t *testing.T, g *fakeGateway) *gatewayPeer {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := grpc.NewServer()
+	gateway.RegisterGatewayServer(srv, g)
+	go srv.Serve(l)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///"+l.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	gw, err := client.Connect(fakeIdentity{}, client.WithSign(func([]byte) ([]byte, error) { return nil, nil }), client.WithClientConnection(conn))
+	if err != nil {
+		t.Fatal(err)
+	}
+	network := gw.GetNetwork("mychannel")
+	return &gatewayPeer{name: "peer0", endpoint: l.Addr().String(), conn: conn, gateway: gw, network: network, contract: network.GetContract("mcap")}
+}
+
+// Test that a failure to read the commit status is retried without
+// submitting the transaction again
+func TestSubmit_CommitStatusRetry(t *testing.T) {
+	g := &fakeGateway{commitStatusFailures: 2}
+	c := &Client{
+		cfg:   Config{Retry: RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, Multiplier: 1}},
+		peers: []*gatewayPeer{testGatewayPeer(t, g)},
+	}
+
+	retries := 0
+	c.OnRetry = func(error, int, time.Duration) { retries++ }
+	txStatus, err := c.Submit(context.Background(), "CreateAsset", "mcap-1")
+	if err != nil {
+		t.Fatalf("Error submitting: %v", err)
+	}
+	if !txStatus.Successful || txStatus.BlockNumber != 7 {
+		t.Errorf("Expected a commit in block 7, got %+v", txStatus)
+	}
+	if g.submitted != 1 || g.statusRequests != 3 || retries != 2 {
+		t.Errorf("Expected 1 submit and 3 status requests, got %d submits, %d status requests, %d retries", g.submitted, g.statusRequests, retries)
+	}
+}
@@ -0,0 +1,120 @@
+// Package ledger is the shared Fabric Gateway client used by the daemon,
+// the TUI and mcapctl to talk to the MCAP chaincode.
+package ledger
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/Octavian-Anghel/Capstone-Project/wallet"
+	"gopkg.in/yaml.v3"
+)
+
+// Config describes how to reach the MCAP chaincode.
+type Config struct {
+	// Identity is the client identity transactions are signed with.
+	Identity wallet.Config `yaml:"identity"`
+
+	// Peers are the gateway peers in order of preference. Calls go to the
+	// first healthy one and fail over to the others.
+	Peers []PeerConfig `yaml:"peers"`
+
+	Keepalive Keepalive `yaml:"keepalive"`
+
+	// HealthCheckInterval is how often idle or failed peer connections
+	// are redialled, and traffic moved back to a recovered preferred peer.
+	HealthCheckInterval time.Duration `yaml:"healthCheckInterval"`
+
+	Channel   string `yaml:"channel"`
+	Chaincode string `yaml:"chaincode"`
+
+	Timeouts Timeouts    `yaml:"timeouts"`
+	Retry    RetryPolicy `yaml:"retry"`
+}
+
+// PeerConfig is one gateway peer.
+type PeerConfig struct {
+	// Endpoint is the gRPC target, for example dns:///localhost:7051.
+	Endpoint string `yaml:"endpoint"`
+	// HostOverride is the host name the peer's TLS certificate was issued
+	// for, when it differs from the endpoint.
+	HostOverride string `yaml:"hostOverride"`
+	// TLSCertPath is the CA certificate used to verify the peer.
+	TLSCertPath string `yaml:"tlsCertPath"`
+}
+
+func (pc PeerConfig) name() string {
+	if pc.HostOverride != "" {
+		return pc.HostOverride
+	}
+	return pc.Endpoint
+}
+
+// Keepalive sets the gRPC keepalive pings sent to each peer, so a dead
+// connection is noticed before the next transaction times out. Time must
+// not be below the peer's keepalive minInterval (60s by default) or the
+// peer closes the connection.
+type Keepalive struct {
+	Time                time.Duration `yaml:"time"`
+	Timeout             time.Duration `yaml:"timeout"`
+	PermitWithoutStream bool          `yaml:"permitWithoutStream"`
+}
+
+// Timeouts are the default deadlines for each kind of gateway call.
+type Timeouts struct {
+	Evaluate     time.Duration `yaml:"evaluate"`
+	Endorse      time.Duration `yaml:"endorse"`
+	Submit       time.Duration `yaml:"submit"`
+	CommitStatus time.Duration `yaml:"commitStatus"`
+}
+
+// DefaultConfig returns the settings for the Fabric test network.
+func DefaultConfig() Config {
+	cryptoPath := "/home/oz/fabric-samples/test-network/organizations/peerOrganizations/org1.example.com"
+	return Config{
+		Identity: wallet.DefaultConfig(),
+		Peers: []PeerConfig{{
+			Endpoint:     "dns:///localhost:7051",
+			HostOverride: "peer0.org1.example.com",
+			TLSCertPath:  cryptoPath + "/peers/peer0.org1.example.com/tls/ca.crt",
+		}},
+		Keepalive: Keepalive{
+			Time:                1 * time.Minute,
+			Timeout:             20 * time.Second,
+			PermitWithoutStream: true,
+		},
+		HealthCheckInterval: 10 * time.Second,
+		Channel:             "mychannel",
+		Chaincode:           "mcap",
+		Timeouts: Timeouts{
+			Evaluate:     5 * time.Second,
+			Endorse:      15 * time.Second,
+			Submit:       5 * time.Second,
+			CommitStatus: 1 * time.Minute,
+		},
+		Retry: DefaultRetryPolicy(),
+	}
+}
+
+// LoadConfig reads the gateway section of a YAML config file over the
+// defaults, so the daemon config can be shared with the TUI and mcapctl.
+// An empty path returns the defaults unchanged.
+func LoadConfig(configPath string) (Config, error) {
+	file := struct {
+		Gateway Config `yaml:"gateway"`
+	}{DefaultConfig()}
+	if configPath == "" {
+		return file.Gateway, nil
+	}
+
+	data, err := os.ReadFile(filepath.Clean(configPath))
+	if err != nil {
+		return file.Gateway, fmt.Errorf("failed to read config file: %w", err)
+	}
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return file.Gateway, fmt.Errorf("failed to parse config file %s: %w", configPath, err)
+	}
+	return file.Gateway, nil
+}
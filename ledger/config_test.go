@@ -0,0 +1,17 @@
+package ledger
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+// Test reading the gateway section of the daemon config
+func TestLoadConfig_DaemonConfig(t *testing.T) {
+	cfg, err := LoadConfig(filepath.Join("..", "MCAPDaemon", "mcapdaemon.example.yaml"))
+	if err != nil {
+		t.Fatalf("Failed to load daemon config: %v", err)
+	}
+	if cfg.Channel != "mychannel" || len(cfg.Peers) != 2 || cfg.Retry.MaxAttempts == 0 {
+		t.Errorf("Unexpected gateway config %+v", cfg)
+	}
+}
@@ -0,0 +1,218 @@
+package ledger
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/fabric-gateway/pkg/client"
+	"github.com/hyperledger/fabric-gateway/pkg/identity"
+	"github.com/hyperledger/fabric-protos-go-apiv2/gateway"
+	"github.com/hyperledger/fabric-protos-go-apiv2/peer"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Stage is the step of the transaction flow at which a failure occurred.
+type Stage string
+
+// Transaction flow stages, matching the gateway error types.
+const (
+	StageSign         Stage = "sign"
+	StageEvaluate     Stage = "evaluate"
+	StageEndorse      Stage = "endorse"
+	StageSubmit       Stage = "submit"
+	StageCommitStatus Stage = "commit_status"
+	StageCommit       Stage = "commit"
+)
+
+// ErrAssetExists is matched by errors for assets that are already on the
+// ledger. Callers anchoring a recording can treat it as done.
+var ErrAssetExists = errors.New("asset already exists")
+
+// ErrAssetNotFound is matched by errors for assets that are not on the
+// ledger.
+var ErrAssetNotFound = errors.New("asset not found")
+
+// chaincodeMessages are the chaincode error messages matched by the
+// sentinel errors.
+var chaincodeMessages = map[error]string{
+	ErrAssetExists:   "already exists",
+	ErrAssetNotFound: "does not exist",
+}
+
+// ErrorDetail is an error reported by an individual peer or orderer.
+type ErrorDetail struct {
+	Address string
+	MSPID   string
+	Message string
+}
+
+// Error is a classified gateway failure.
+type Error struct {
+	Stage Stage
+	TxID  string
+
+	// Code is the gRPC status code. It is codes.OK for commit failures,
+	// which instead set ValidationCode.
+	Code           codes.Code
+	ValidationCode peer.TxValidationCode
+
+	// Details are the per-peer errors attached to the gRPC status.
+	Details []ErrorDetail
+
+	Err error
+}
+
+func (e *Error) Error() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s failed", e.Stage)
+	if e.TxID != "" {
+		fmt.Fprintf(&sb, " for transaction %s", e.TxID)
+	}
+	if e.Stage == StageCommit {
+		fmt.Fprintf(&sb, " with validation code %s", e.ValidationCode)
+	} else {
+		fmt.Fprintf(&sb, " with %s: %s", e.Code, status.Convert(e.Err).Message())
+	}
+	for _, d := range e.Details {
+		fmt.Fprintf(&sb, "; %s (%s): %s", d.Address, d.MSPID, d.Message)
+	}
+	return sb.String()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is reports chaincode "already exists" failures as ErrAssetExists and
+// "does not exist" failures as ErrAssetNotFound.
+func (e *Error) Is(target error) bool {
+	message, ok := chaincodeMessages[target]
+	if !ok {
+		return false
+	}
+	if strings.Contains(status.Convert(e.Err).Message(), message) {
+		return true
+	}
+	for _, d := range e.Details {
+		if strings.Contains(d.Message, message) {
+			return true
+		}
+	}
+	return false
+}
+
+// Transient reports whether retrying the transaction may succeed.
+func (e *Error) Transient() bool {
+	if errors.Is(e, ErrAssetExists) {
+		return false
+	}
+	if e.Stage == StageCommit {
+		switch e.ValidationCode {
+		case peer.TxValidationCode_MVCC_READ_CONFLICT, peer.TxValidationCode_PHANTOM_READ_CONFLICT:
+			return true
+		}
+		return false
+	}
+
+	switch e.Code {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted, codes.Canceled:
+		return true
+	case codes.Aborted:
+		// Endorsement aborts carry chaincode errors, which are deterministic.
+		return e.Stage != StageEndorse && e.Stage != StageEvaluate
+	}
+	return false
+}
+
+// IsTransient reports whether err is a gateway failure worth retrying.
+func IsTransient(err error) bool {
+	var ledgerErr *Error
+	return errors.As(err, &ledgerErr) && ledgerErr.Transient()
+}
+
+// signError is a failure of the client's signer, such as a busy PKCS#11
+// token or an unreachable remote signer.
+type signError struct {
+	err error
+}
+
+func (e *signError) Error() string { return e.err.Error() }
+func (e *signError) Unwrap() error { return e.err }
+
+// signWith returns sign with its failures marked for classify, as the
+// gateway client passes them on as they are.
+func signWith(sign identity.Sign) identity.Sign {
+	return func(digest []byte) ([]byte, error) {
+		signature, err := sign(digest)
+		if err != nil {
+			return nil, &signError{err: err}
+		}
+		return signature, nil
+	}
+}
+
+// classify converts an error returned by the fabric-gateway client into an
+// *Error. Signer failures are classified as unavailable, since HSMs and
+// remote signers are usually only briefly out of reach. Other errors that
+// did not come from the gateway are returned unchanged.
+func classify(err error, stage Stage) error {
+	if err == nil {
+		return nil
+	}
+	var signErr *signError
+	if errors.As(err, &signErr) {
+		return &Error{Stage: StageSign, Code: codes.Unavailable, Err: err}
+	}
+
+	var (
+		endorseErr      *client.EndorseError
+		submitErr       *client.SubmitError
+		commitStatusErr *client.CommitStatusError
+		commitErr       *client.CommitError
+		transactionErr  *client.TransactionError
+	)
+	out := &Error{Stage: stage, Err: err}
+	switch {
+	case errors.As(err, &commitErr):
+		out.Stage = StageCommit
+		out.TxID = commitErr.TransactionID
+		out.ValidationCode = commitErr.Code
+		return out
+	case errors.As(err, &endorseErr):
+		out.Stage = StageEndorse
+		out.TxID = endorseErr.TransactionID
+	case errors.As(err, &submitErr):
+		out.Stage = StageSubmit
+		out.TxID = submitErr.TransactionID
+	case errors.As(err, &commitStatusErr):
+		out.Stage = StageCommitStatus
+		out.TxID = commitStatusErr.TransactionID
+	case errors.As(err, &transactionErr):
+		out.TxID = transactionErr.TransactionID
+	}
+
+	st, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+	out.Code = st.Code()
+	for _, detail := range st.Details() {
+		if d, ok := detail.(*gateway.ErrorDetail); ok {
+			out.Details = append(out.Details, ErrorDetail{Address: d.GetAddress(), MSPID: d.GetMspId(), Message: d.GetMessage()})
+		}
+	}
+	return out
+}
+
+// commitFailure builds the error for a transaction that was committed as
+// invalid.
+func commitFailure(txStatus *client.Status) error {
+	return &Error{
+		Stage:          StageCommit,
+		TxID:           txStatus.TransactionID,
+		ValidationCode: txStatus.Code,
+		Err:            fmt.Errorf("transaction %s failed to commit in block %d", txStatus.TransactionID, txStatus.BlockNumber),
+	}
+}
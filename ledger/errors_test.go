@@ -0,0 +1,102 @@
+package ledger
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/fabric-gateway/pkg/client"
+	"github.com/hyperledger/fabric-protos-go-apiv2/gateway"
+	"github.com/hyperledger/fabric-protos-go-apiv2/peer"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// statusError builds a gRPC status error carrying peer error details.
+func statusError(t *testing.T, code codes.Code, msg string, details ...*gateway.ErrorDetail) error {
+	t.Helper()
+	st := status.New(code, msg)
+	for _, d := range details {
+		var err error
+		if st, err = st.WithDetails(d); err != nil {
+			t.Fatalf("Failed to attach details: %v", err)
+		}
+	}
+	return st.Err()
+}
+
+// Test extraction of gRPC codes and peer details
+func TestClassify_Details(t *testing.T) {
+	err := statusError(t, codes.Aborted, "failed to endorse transaction, see attached details for more info",
+		&gateway.ErrorDetail{Address: "peer0.org1.example.com:7051", MspId: "Org1MSP", Message: "chaincode response 500, the asset run1 already exists"},
+	)
+
+	classified := classify(err, StageEndorse)
+	var ledgerErr *Error
+	if !errors.As(classified, &ledgerErr) {
+		t.Fatalf("Expected *Error, got %T", classified)
+	}
+	if ledgerErr.Stage != StageEndorse || ledgerErr.Code != codes.Aborted {
+		t.Errorf("Unexpected classification: %s/%s", ledgerErr.Stage, ledgerErr.Code)
+	}
+	if len(ledgerErr.Details) != 1 || ledgerErr.Details[0].MSPID != "Org1MSP" {
+		t.Errorf("Expected peer details, got %+v", ledgerErr.Details)
+	}
+	if !errors.Is(classified, ErrAssetExists) || errors.Is(classified, ErrAssetNotFound) {
+		t.Errorf("Expected only ErrAssetExists for duplicate asset")
+	}
+	if IsTransient(classified) {
+		t.Errorf("Expected duplicate asset to be permanent")
+	}
+}
+
+// Test which failures are worth retrying
+func TestTransient(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"peer unavailable", &Error{Stage: StageEndorse, Code: codes.Unavailable}, true},
+		{"commit status timeout", &Error{Stage: StageCommitStatus, Code: codes.DeadlineExceeded}, true},
+		{"chaincode error", &Error{Stage: StageEndorse, Code: codes.Aborted}, false},
+		{"orderer aborted", &Error{Stage: StageSubmit, Code: codes.Aborted}, true},
+		{"access denied", &Error{Stage: StageEndorse, Code: codes.PermissionDenied}, false},
+		{"mvcc conflict", &Error{Stage: StageCommit, ValidationCode: peer.TxValidationCode_MVCC_READ_CONFLICT}, true},
+		{"policy failure", &Error{Stage: StageCommit, ValidationCode: peer.TxValidationCode_ENDORSEMENT_POLICY_FAILURE}, false},
+		{"not a gateway error", errors.New("disk full"), false},
+		{"signer unreachable", classify(&signError{err: errors.New("connection refused")}, StageEndorse), true},
+	}
+	for _, tt := range tests {
+		if got := IsTransient(fmt.Errorf("wrapped: %w", tt.err)); got != tt.want {
+			t.Errorf("%s: expected transient=%v, got %v", tt.name, tt.want, got)
+		}
+	}
+}
+
+// Test commit failures built from a commit status
+func TestCommitFailure(t *testing.T) {
+	err := commitFailure(&client.Status{TransactionID: "tx1", BlockNumber: 7, Code: peer.TxValidationCode_MVCC_READ_CONFLICT})
+	var ledgerErr *Error
+	if !errors.As(err, &ledgerErr) || ledgerErr.Stage != StageCommit || ledgerErr.TxID != "tx1" {
+		t.Fatalf("Unexpected commit failure: %v", err)
+	}
+	if !IsTransient(err) {
+		t.Errorf("Expected MVCC conflict to be transient")
+	}
+}
+
+// Test that signer failures are transient but not blamed on the peer
+func TestClassify_Sign(t *testing.T) {
+	sign := signWith(func([]byte) ([]byte, error) { return nil, errors.New("CKR_DEVICE_ERROR") })
+	_, err := sign([]byte("digest"))
+	classified := classify(fmt.Errorf("failed to sign: %w", err), StageEndorse)
+
+	var ledgerErr *Error
+	if !errors.As(classified, &ledgerErr) || ledgerErr.Stage != StageSign || ledgerErr.Code != codes.Unavailable {
+		t.Fatalf("Unexpected classification %v", classified)
+	}
+	if !IsTransient(classified) || peerFailure(classified) {
+		t.Errorf("Expected a transient failure of the signer rather than the peer")
+	}
+}
@@ -0,0 +1,95 @@
+package ledger
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/fabric-gateway/pkg/client"
+)
+
+// Chaincode events emitted by the MCAP contract.
+const (
+	EventAssetCreated = "AssetCreated"
+	EventAssetRevoked = "AssetRevoked"
+)
+
+// Event is a committed chaincode event of the MCAP contract.
+type Event struct {
+	Name        string
+	BlockNumber uint64
+	TxID        string
+
+	// The payload: the asset as written by the transaction and the client
+	// that submitted it.
+	Asset     Asset  `json:"Asset"`
+	MSPID     string `json:"MSPID"`
+	Submitter string `json:"Submitter"`
+}
+
+// Events streams the contract's chaincode events from the active peer,
+// starting at startBlock or, if it is zero, with the next block. The
+// channel is closed when ctx is done or the peer ends the stream, after
+// which callers resume with the block after the last event they received.
+func (c *Client) Events(ctx context.Context, startBlock uint64) (<-chan Event, error) {
+	var opts []client.ChaincodeEventsOption
+	if startBlock > 0 {
+		opts = append(opts, client.WithStartBlock(startBlock))
+	}
+
+	p := c.pick()
+	events, err := p.network.ChaincodeEvents(ctx, c.cfg.Chaincode, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to subscribe to chaincode events on %s: %w", p.name, err)
+	}
+
+	out := make(chan Event)
+	go func() {
+		defer close(out)
+		for ce := range events {
+			event, err := parseEvent(ce)
+			if err != nil {
+				// Events written by older contract versions carry no payload
+				continue
+			}
+			select {
+			case out <- event:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out, nil
+}
+
+func parseEvent(ce *client.ChaincodeEvent) (Event, error) {
+	event := Event{Name: ce.EventName, BlockNumber: ce.BlockNumber, TxID: ce.TransactionID}
+	if err := json.Unmarshal(ce.Payload, &event); err != nil {
+		return Event{}, fmt.Errorf("invalid %s event payload: %w", ce.EventName, err)
+	}
+	return event, nil
+}
+
+// SubmitterName returns the common name in a client identity ID as
+// reported by the contract, such as the Submitter of an event or the
+// RevokedBy of a revocation. IDs it cannot parse are returned unchanged.
+func SubmitterName(id string) string {
+	decoded := id
+	if b, err := base64.StdEncoding.DecodeString(id); err == nil {
+		decoded = string(b)
+	}
+	// x509::<subject>::<issuer>
+	subject, ok := strings.CutPrefix(decoded, "x509::")
+	if !ok {
+		return id
+	}
+	subject, _, _ = strings.Cut(subject, "::")
+	for _, rdn := range strings.Split(subject, ",") {
+		if cn, ok := strings.CutPrefix(strings.TrimSpace(rdn), "CN="); ok {
+			return cn
+		}
+	}
+	return id
+}
@@ -0,0 +1,44 @@
+package ledger
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/hyperledger/fabric-gateway/pkg/client"
+)
+
+// Test decoding the event payload written by the contract
+func TestParseEvent(t *testing.T) {
+	event, err := parseEvent(&client.ChaincodeEvent{
+		BlockNumber:   12,
+		TransactionID: "tx1",
+		EventName:     EventAssetRevoked,
+		Payload:       []byte(`{"Asset":{"McapID":"run1","Revocation":{"Reason":"sensor fault"}},"MSPID":"Org1MSP","Submitter":"x509::CN=Admin"}`),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if event.Name != EventAssetRevoked || event.BlockNumber != 12 || event.TxID != "tx1" ||
+		event.Asset.McapID != "run1" || event.Asset.Revocation == nil || event.MSPID != "Org1MSP" {
+		t.Errorf("Unexpected event %+v", event)
+	}
+
+	if _, err := parseEvent(&client.ChaincodeEvent{EventName: "legacy"}); err == nil {
+		t.Error("Expected an empty payload to be rejected")
+	}
+}
+
+// Test extracting the common name from client identity IDs
+func TestSubmitterName(t *testing.T) {
+	id := "x509::CN=User1@org1.example.com,OU=client,L=San Francisco,ST=California,C=US::CN=ca.org1.example.com,O=org1.example.com"
+	tests := map[string]string{
+		base64.StdEncoding.EncodeToString([]byte(id)): "User1@org1.example.com",
+		id:            "User1@org1.example.com",
+		"recorder-01": "recorder-01",
+	}
+	for in, want := range tests {
+		if got := SubmitterName(in); got != want {
+			t.Errorf("SubmitterName(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,216 @@
+package ledger
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/hyperledger/fabric-gateway/pkg/client"
+	"github.com/hyperledger/fabric-gateway/pkg/hash"
+	"github.com/hyperledger/fabric-gateway/pkg/identity"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/keepalive"
+)
+
+// gatewayPeer is a gateway connection to one of the configured peers.
+type gatewayPeer struct {
+	name     string
+	endpoint string
+	conn     *grpc.ClientConn
+	gateway  *client.Gateway
+	network  *client.Network
+	contract *client.Contract
+}
+
+// PeerState describes one gateway peer for health reporting.
+type PeerState struct {
+	Name     string
+	Endpoint string
+	State    connectivity.State
+	Active   bool
+}
+
+// usable reports whether calls sent to p have a chance of succeeding.
+// Idle and connecting peers are usable because gRPC dials them on demand.
+func (p *gatewayPeer) usable() bool {
+	switch p.conn.GetState() {
+	case connectivity.TransientFailure, connectivity.Shutdown:
+		return false
+	}
+	return true
+}
+
+func (p *gatewayPeer) close() {
+	p.gateway.Close()
+	p.conn.Close()
+}
+
+// dialPeer opens a gateway connection to pc. The connection is established
+// lazily, so peers that are down at start-up are not an error.
+func dialPeer(cfg Config, pc PeerConfig, id identity.Identity, sign identity.Sign) (*gatewayPeer, error) {
+	conn, err := newGrpcConnection(cfg, pc)
+	if err != nil {
+		return nil, err
+	}
+
+	gw, err := client.Connect(
+		id,
+		client.WithSign(sign),
+		client.WithHash(hash.SHA256),
+		client.WithClientConnection(conn),
+		client.WithEvaluateTimeout(cfg.Timeouts.Evaluate),
+		client.WithEndorseTimeout(cfg.Timeouts.Endorse),
+		client.WithSubmitTimeout(cfg.Timeouts.Submit),
+		client.WithCommitStatusTimeout(cfg.Timeouts.CommitStatus),
+	)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to connect to gateway %s: %w", pc.name(), err)
+	}
+
+	network := gw.GetNetwork(cfg.Channel)
+	return &gatewayPeer{
+		name:     pc.name(),
+		endpoint: pc.Endpoint,
+		conn:     conn,
+		gateway:  gw,
+		network:  network,
+		contract: network.GetContract(cfg.Chaincode),
+	}, nil
+}
+
+// newGrpcConnection creates a gRPC connection to the Gateway server.
+func newGrpcConnection(cfg Config, pc PeerConfig) (*grpc.ClientConn, error) {
+	certificatePEM, err := os.ReadFile(filepath.Clean(pc.TLSCertPath))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read TLS certificate file: %w", err)
+	}
+
+	certificate, err := identity.CertificateFromPEM(certificatePEM)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse TLS certificate: %w", err)
+	}
+
+	certPool := x509.NewCertPool()
+	certPool.AddCert(certificate)
+	transportCredentials := credentials.NewClientTLSFromCert(certPool, pc.HostOverride)
+
+	connection, err := grpc.NewClient(pc.Endpoint,
+		grpc.WithTransportCredentials(transportCredentials),
+		grpc.WithKeepaliveParams(keepalive.ClientParameters{
+			Time:                cfg.Keepalive.Time,
+			Timeout:             cfg.Keepalive.Timeout,
+			PermitWithoutStream: cfg.Keepalive.PermitWithoutStream,
+		}),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create gRPC connection to %s: %w", pc.name(), err)
+	}
+
+	return connection, nil
+}
+
+// pick returns the peer calls should go to: the active peer if it is
+// usable, otherwise the next usable peer in configured order.
+func (c *Client) pick() *gatewayPeer {
+	n := len(c.peers)
+	active := int(c.active.Load())
+	for i := 0; i < n; i++ {
+		idx := (active + i) % n
+		if c.peers[idx].usable() {
+			c.activate(idx)
+			return c.peers[idx]
+		}
+	}
+	return c.peers[active]
+}
+
+// failed moves traffic away from p when err shows the peer itself, rather
+// than the transaction, is at fault.
+func (c *Client) failed(p *gatewayPeer, err error) {
+	if len(c.peers) < 2 || !peerFailure(err) {
+		return
+	}
+	for idx, candidate := range c.peers {
+		if candidate == p {
+			c.activate((idx + 1) % len(c.peers))
+			return
+		}
+	}
+}
+
+// activate makes peers[idx] the active peer.
+func (c *Client) activate(idx int) {
+	prev := int(c.active.Swap(int32(idx)))
+	if prev != idx && c.OnFailover != nil {
+		c.OnFailover(c.peers[prev].name, c.peers[idx].name)
+	}
+}
+
+// peerFailure reports whether err means the peer could not be reached or
+// did not answer in time.
+func peerFailure(err error) bool {
+	var ledgerErr *Error
+	if !errors.As(err, &ledgerErr) || ledgerErr.Stage == StageCommit || ledgerErr.Stage == StageSign {
+		return false
+	}
+	switch ledgerErr.Code {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted:
+		return true
+	}
+	return false
+}
+
+// Run health-checks the peers every Config.HealthCheckInterval until ctx
+// is done. Peers that are idle or failing are asked to reconnect, and
+// traffic moves to the first ready peer in configured order, so it fails
+// back once a preferred peer recovers.
+func (c *Client) Run(ctx context.Context) {
+	if c.cfg.HealthCheckInterval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(c.cfg.HealthCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.checkPeers()
+		}
+	}
+}
+
+func (c *Client) checkPeers() {
+	ready := -1
+	for idx, p := range c.peers {
+		switch p.conn.GetState() {
+		case connectivity.Idle, connectivity.TransientFailure:
+			p.conn.Connect()
+		case connectivity.Ready:
+			if ready < 0 {
+				ready = idx
+			}
+		}
+	}
+	if ready >= 0 {
+		c.activate(ready)
+	}
+}
+
+// Peers reports the connection state of every configured peer.
+func (c *Client) Peers() []PeerState {
+	active := int(c.active.Load())
+	states := make([]PeerState, len(c.peers))
+	for idx, p := range c.peers {
+		states[idx] = PeerState{Name: p.name, Endpoint: p.endpoint, State: p.conn.GetState(), Active: idx == active}
+	}
+	return states
+}
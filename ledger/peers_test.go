@@ -0,0 +1,95 @@
+package ledger
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// testPeer returns a peer connected to a running gRPC server, or to a
+// closed port when up is false, once the connection has settled.
+func testPeer(t *testing.T, name string, up bool) *gatewayPeer {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := l.Addr().String()
+	if up {
+		srv := grpc.NewServer()
+		go srv.Serve(l)
+		t.Cleanup(srv.Stop)
+	} else {
+		l.Close()
+	}
+
+	conn, err := grpc.NewClient("passthrough:///"+addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	want := connectivity.TransientFailure
+	if up {
+		want = connectivity.Ready
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	conn.Connect()
+	for state := conn.GetState(); state != want; state = conn.GetState() {
+		if !conn.WaitForStateChange(ctx, state) {
+			t.Fatalf("%s stuck in %s, expected %s", name, state, want)
+		}
+	}
+	return &gatewayPeer{name: name, endpoint: addr, conn: conn}
+}
+
+// Test that calls skip unreachable peers and move on after peer failures
+func TestFailover(t *testing.T) {
+	var moves []string
+	c := &Client{
+		peers:      []*gatewayPeer{testPeer(t, "peer0", false), testPeer(t, "peer1", true), testPeer(t, "peer2", true)},
+		OnFailover: func(from, to string) { moves = append(moves, from+">"+to) },
+	}
+
+	if p := c.pick(); p.name != "peer1" {
+		t.Fatalf("Expected the first reachable peer, got %s", p.name)
+	}
+
+	c.failed(c.peers[1], &Error{Stage: StageEndorse, Code: codes.Aborted})
+	if c.Peer() != "peer1" {
+		t.Errorf("Expected chaincode errors not to cause failover, now on %s", c.Peer())
+	}
+
+	c.failed(c.peers[1], &Error{Stage: StageSubmit, Code: codes.Unavailable})
+	if c.Peer() != "peer2" {
+		t.Errorf("Expected failover to peer2, now on %s", c.Peer())
+	}
+
+	if len(moves) != 2 || moves[0] != "peer0>peer1" || moves[1] != "peer1>peer2" {
+		t.Errorf("Unexpected failovers %v", moves)
+	}
+
+	states := c.Peers()
+	if states[0].State != connectivity.TransientFailure || !states[2].Active {
+		t.Errorf("Unexpected peer states %+v", states)
+	}
+}
+
+// Test that the health check moves traffic back to a recovered preferred
+// peer
+func TestCheckPeers_FailBack(t *testing.T) {
+	c := &Client{peers: []*gatewayPeer{testPeer(t, "peer0", true), testPeer(t, "peer1", true)}}
+	c.active.Store(1)
+
+	c.checkPeers()
+	if c.Peer() != "peer0" {
+		t.Errorf("Expected fail back to peer0, still on %s", c.Peer())
+	}
+}
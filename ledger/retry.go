@@ -0,0 +1,62 @@
+package ledger
+
+import (
+	"context"
+	"time"
+)
+
+// RetryPolicy controls how transient gateway failures are retried.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of tries, including the first.
+	MaxAttempts int `yaml:"maxAttempts"`
+
+	InitialBackoff time.Duration `yaml:"initialBackoff"`
+	MaxBackoff     time.Duration `yaml:"maxBackoff"`
+	Multiplier     float64       `yaml:"multiplier"`
+}
+
+// DefaultRetryPolicy retries a few times over roughly half a minute.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:    5,
+		InitialBackoff: 1 * time.Second,
+		MaxBackoff:     15 * time.Second,
+		Multiplier:     2,
+	}
+}
+
+// Backoff returns the wait before the retry that follows attempt.
+func (p RetryPolicy) Backoff(attempt int) time.Duration {
+	wait := float64(p.InitialBackoff)
+	for i := 1; i < attempt; i++ {
+		wait *= p.Multiplier
+		if p.MaxBackoff > 0 && time.Duration(wait) >= p.MaxBackoff {
+			return p.MaxBackoff
+		}
+	}
+	return time.Duration(wait)
+}
+
+// Do calls fn until it succeeds, fails permanently or runs out of attempts.
+// onRetry, if set, is told about each failure that will be retried.
+func (p RetryPolicy) Do(ctx context.Context, fn func() error, onRetry func(err error, attempt int, wait time.Duration)) error {
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil || !IsTransient(err) || attempt >= p.MaxAttempts {
+			return err
+		}
+
+		wait := p.Backoff(attempt)
+		if onRetry != nil {
+			onRetry(err, attempt, wait)
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
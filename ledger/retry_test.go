@@ -0,0 +1,58 @@
+package ledger
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+)
+
+// Test exponential backoff capped at MaxBackoff
+func TestRetryPolicy_Backoff(t *testing.T) {
+	p := RetryPolicy{InitialBackoff: time.Second, MaxBackoff: 5 * time.Second, Multiplier: 2}
+	want := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}
+	for i, w := range want {
+		if got := p.Backoff(i + 1); got != w {
+			t.Errorf("Attempt %d: expected %s, got %s", i+1, w, got)
+		}
+	}
+}
+
+// Test that transient failures are retried and permanent ones are not
+func TestRetryPolicy_Do(t *testing.T) {
+	p := RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, Multiplier: 1}
+	transient := &Error{Stage: StageSubmit, Code: codes.Unavailable}
+	permanent := &Error{Stage: StageEndorse, Code: codes.PermissionDenied}
+
+	calls, retries := 0, 0
+	err := p.Do(context.Background(), func() error {
+		calls++
+		if calls < 3 {
+			return transient
+		}
+		return nil
+	}, func(error, int, time.Duration) { retries++ })
+	if err != nil || calls != 3 || retries != 2 {
+		t.Errorf("Expected success on third attempt, got err=%v calls=%d retries=%d", err, calls, retries)
+	}
+
+	calls = 0
+	err = p.Do(context.Background(), func() error {
+		calls++
+		return permanent
+	}, nil)
+	if !errors.Is(err, permanent) || calls != 1 {
+		t.Errorf("Expected a single attempt for permanent failure, got %d", calls)
+	}
+
+	calls = 0
+	err = p.Do(context.Background(), func() error {
+		calls++
+		return transient
+	}, nil)
+	if !errors.Is(err, transient) || calls != 3 {
+		t.Errorf("Expected %d attempts before giving up, got %d", p.MaxAttempts, calls)
+	}
+}
@@ -0,0 +1,87 @@
+package ledger
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+)
+
+// Verification outcomes.
+const (
+	StatusMatched         = "matched"
+	StatusMismatched      = "mismatched"
+	StatusRevoked         = "revoked"
+	StatusMissingOnLedger = "missing-on-ledger"
+)
+
+// Verification is the result of checking a digest against the ledger.
+type Verification struct {
+	McapID string `json:"mcapId"`
+	Hash   string `json:"hash"`
+	Status string `json:"status"`
+
+	// Algorithm is the digest algorithm of Hash, when the caller knows it.
+	Algorithm string `json:"algorithm,omitempty"`
+
+	// Asset is the anchored asset, unless it is missing.
+	Asset *Asset `json:"asset,omitempty"`
+}
+
+// ContentID is the asset ID derived from a digest by the content scheme,
+// which the daemon uses by default.
+func ContentID(digest string) string {
+	sum := sha256.Sum256([]byte(digest))
+	return hex.EncodeToString(sum[:])
+}
+
+// VerifyStatus compares a digest with an anchored asset.
+func VerifyStatus(digest string, asset *Asset) string {
+	switch {
+	case asset.Hash != digest:
+		return StatusMismatched
+	case asset.Revocation != nil:
+		return StatusRevoked
+	}
+	return StatusMatched
+}
+
+// Verify checks digest against asset mcapID. An empty mcapID is derived
+// from the digest with ContentID.
+func (c *Client) Verify(ctx context.Context, mcapID string, digest string) (*Verification, error) {
+	if mcapID == "" {
+		mcapID = ContentID(digest)
+	}
+	v := &Verification{McapID: mcapID, Hash: digest}
+
+	asset, err := c.ReadAsset(ctx, mcapID)
+	switch {
+	case errors.Is(err, ErrAssetNotFound):
+		v.Status = StatusMissingOnLedger
+	case err != nil:
+		return nil, err
+	default:
+		v.Asset = asset
+		v.Status = VerifyStatus(digest, asset)
+	}
+	return v, nil
+}
+
+// Recheck compares the content again when v.Hash, computed with algorithm,
+// cannot be compared with an asset anchored under another algorithm.
+// rehash returns the digest of the same content under the algorithm it is
+// given. Hashing is left to the caller so it can run outside any ledger
+// timeout.
+func (v *Verification) Recheck(algorithm string, rehash func(algorithm string) (string, error)) error {
+	v.Algorithm = algorithm
+	if v.Asset == nil || v.Asset.HashAlgorithm() == algorithm {
+		return nil
+	}
+	digest, err := rehash(v.Asset.HashAlgorithm())
+	if err != nil {
+		return err
+	}
+	v.Hash, v.Algorithm = digest, v.Asset.HashAlgorithm()
+	v.Status = VerifyStatus(digest, v.Asset)
+	return nil
+}
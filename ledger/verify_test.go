@@ -0,0 +1,56 @@
+package ledger
+
+import "testing"
+
+// Test verify outcomes against an anchored asset
+func TestVerifyStatus(t *testing.T) {
+	asset := &Asset{Hash: "abc"}
+	if got := VerifyStatus("abc", asset); got != StatusMatched {
+		t.Errorf("Expected %s, got %s", StatusMatched, got)
+	}
+	if got := VerifyStatus("abd", asset); got != StatusMismatched {
+		t.Errorf("Expected %s, got %s", StatusMismatched, got)
+	}
+	asset.Revocation = &Revocation{Reason: "sensor fault"}
+	if got := VerifyStatus("abc", asset); got != StatusRevoked {
+		t.Errorf("Expected %s, got %s", StatusRevoked, got)
+	}
+}
+
+// Test that content IDs match the daemon's content scheme
+func TestContentID(t *testing.T) {
+	// sha256("abc")
+	if got := ContentID("abc"); got != "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad" {
+		t.Errorf("Unexpected content ID %s", got)
+	}
+}
+
+// Test that assets without a recorded algorithm default to SHA-256
+func TestHashAlgorithm(t *testing.T) {
+	if got := (&Asset{}).HashAlgorithm(); got != "sha256" {
+		t.Errorf("Expected sha256, got %s", got)
+	}
+	if got := (&Asset{Algorithm: "blake3"}).HashAlgorithm(); got != "blake3" {
+		t.Errorf("Expected blake3, got %s", got)
+	}
+}
+
+// Test that a digest is checked again under the asset's algorithm
+func TestVerification_Recheck(t *testing.T) {
+	rehash := func(algorithm string) (string, error) { return algorithm + ":abc", nil }
+
+	v := &Verification{Hash: "abc", Status: StatusMatched, Asset: &Asset{Hash: "abc"}}
+	if err := v.Recheck("sha256", rehash); err != nil || v.Hash != "abc" || v.Algorithm != "sha256" || v.Status != StatusMatched {
+		t.Errorf("Expected a same-algorithm result to be kept, got %+v, %v", v, err)
+	}
+
+	v = &Verification{Hash: "abc", Status: StatusMismatched, Asset: &Asset{Hash: "blake3:abc", Algorithm: "blake3"}}
+	if err := v.Recheck("sha256", rehash); err != nil || v.Hash != "blake3:abc" || v.Algorithm != "blake3" || v.Status != StatusMatched {
+		t.Errorf("Expected a match under blake3, got %+v, %v", v, err)
+	}
+
+	v = &Verification{Hash: "abc", Status: StatusMissingOnLedger}
+	if err := v.Recheck("blake3", rehash); err != nil || v.Algorithm != "blake3" || v.Status != StatusMissingOnLedger {
+		t.Errorf("Expected a missing asset to stay missing, got %+v, %v", v, err)
+	}
+}
@@ -0,0 +1,168 @@
+package mcap
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+)
+
+// cdrReader decodes the classic (XCDR1) Common Data Representation used by
+// ROS 2. Primitives are aligned to their size relative to the end of the
+// 4 byte encapsulation header.
+type cdrReader struct {
+	buf   []byte
+	pos   int
+	order binary.ByteOrder
+	err   error
+}
+
+// decodeCDR decodes a CDR message of type t into a map of field names to
+// values. Integers and floats keep their ROS type, byte and uint8 arrays
+// become []byte, other arrays []any and nested messages map[string]any.
+func decodeCDR(data []byte, t *msgType) (map[string]any, error) {
+	if len(data) < 4 {
+		return nil, fmt.Errorf("mcap: CDR message is shorter than its header")
+	}
+	r := &cdrReader{buf: data[4:]}
+	switch kind := binary.BigEndian.Uint16(data[:2]); kind {
+	case 0x0000:
+		r.order = binary.BigEndian
+	case 0x0001:
+		r.order = binary.LittleEndian
+	default:
+		return nil, fmt.Errorf("mcap: unsupported CDR representation 0x%04x", kind)
+	}
+
+	v := r.message(t)
+	if r.err != nil {
+		return nil, fmt.Errorf("mcap: malformed %s message: %w", t.name, r.err)
+	}
+	return v, nil
+}
+
+func (r *cdrReader) message(t *msgType) map[string]any {
+	out := make(map[string]any, len(t.fields))
+	for _, f := range t.fields {
+		if r.err != nil {
+			return nil
+		}
+		if !f.array {
+			out[f.name] = r.value(f)
+			continue
+		}
+
+		n := f.length
+		if n == 0 {
+			n = int(r.u32())
+		}
+		if f.typ == "byte" || f.typ == "uint8" || f.typ == "char" {
+			out[f.name] = bytes.Clone(r.take(n))
+			continue
+		}
+		// Every element takes at least one byte, which bounds a corrupt count.
+		if n > len(r.buf)-r.pos {
+			r.err = io.ErrUnexpectedEOF
+			return nil
+		}
+		items := make([]any, n)
+		for i := range items {
+			items[i] = r.value(f)
+		}
+		out[f.name] = items
+	}
+	return out
+}
+
+// value decodes a single element of field f.
+func (r *cdrReader) value(f msgField) any {
+	if f.complex != nil {
+		return r.message(f.complex)
+	}
+	switch f.typ {
+	case "bool":
+		b := r.take(1)
+		return len(b) == 1 && b[0] != 0
+	case "byte", "char", "uint8":
+		if b := r.take(1); len(b) == 1 {
+			return b[0]
+		}
+		return uint8(0)
+	case "int8":
+		if b := r.take(1); len(b) == 1 {
+			return int8(b[0])
+		}
+		return int8(0)
+	case "int16":
+		return int16(r.u16())
+	case "uint16":
+		return r.u16()
+	case "int32":
+		return int32(r.u32())
+	case "uint32":
+		return r.u32()
+	case "int64":
+		return int64(r.u64())
+	case "uint64":
+		return r.u64()
+	case "float32":
+		return math.Float32frombits(r.u32())
+	case "float64":
+		return math.Float64frombits(r.u64())
+	case "string":
+		b := r.take(int(r.u32()))
+		if len(b) > 0 && b[len(b)-1] == 0 {
+			b = b[:len(b)-1]
+		}
+		return string(b)
+	default:
+		if r.err == nil {
+			r.err = fmt.Errorf("unsupported field type %s", f.typ)
+		}
+		return nil
+	}
+}
+
+func (r *cdrReader) align(n int) {
+	if rem := r.pos % n; rem != 0 {
+		r.pos += n - rem
+	}
+}
+
+func (r *cdrReader) take(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if n < 0 || r.pos > len(r.buf) || n > len(r.buf)-r.pos {
+		r.err = io.ErrUnexpectedEOF
+		return nil
+	}
+	out := r.buf[r.pos : r.pos+n]
+	r.pos += n
+	return out
+}
+
+func (r *cdrReader) u16() uint16 {
+	r.align(2)
+	if b := r.take(2); b != nil {
+		return r.order.Uint16(b)
+	}
+	return 0
+}
+
+func (r *cdrReader) u32() uint32 {
+	r.align(4)
+	if b := r.take(4); b != nil {
+		return r.order.Uint32(b)
+	}
+	return 0
+}
+
+func (r *cdrReader) u64() uint64 {
+	r.align(8)
+	if b := r.take(8); b != nil {
+		return r.order.Uint64(b)
+	}
+	return 0
+}
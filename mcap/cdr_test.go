@@ -0,0 +1,107 @@
+package mcap
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"testing"
+)
+
+// cdrWriter encodes little-endian CDR for tests.
+type cdrWriter struct {
+	bytes.Buffer
+}
+
+func newCDRWriter() *cdrWriter {
+	w := &cdrWriter{}
+	w.Write([]byte{0x00, 0x01, 0x00, 0x00})
+	return w
+}
+
+func (w *cdrWriter) align(n int) {
+	for (w.Len()-4)%n != 0 {
+		w.WriteByte(0)
+	}
+}
+
+func (w *cdrWriter) u32(v uint32) {
+	w.align(4)
+	binary.Write(w, binary.LittleEndian, v)
+}
+
+func (w *cdrWriter) f32(v float32) { w.u32(math.Float32bits(v)) }
+
+func (w *cdrWriter) f64(v float64) {
+	w.align(8)
+	binary.Write(w, binary.LittleEndian, math.Float64bits(v))
+}
+
+func (w *cdrWriter) str(s string) {
+	w.u32(uint32(len(s) + 1))
+	w.WriteString(s)
+	w.WriteByte(0)
+}
+
+const temperatureSchema = `# Single temperature reading.
+std_msgs/Header header # timestamp is the time the reading was taken
+float64 temperature
+float64 variance
+float32[] samples
+uint8[3] tag
+uint8 FLAG_OK=1
+================================================================================
+MSG: std_msgs/Header
+builtin_interfaces/Time stamp
+string<=64 frame_id
+`
+
+// Test decoding a ROS 2 CDR message against its ros2msg schema
+func TestDecodeCDR(t *testing.T) {
+	schema := &Schema{Name: "sensor_msgs/msg/Temperature", Encoding: "ros2msg", Data: []byte(temperatureSchema)}
+
+	w := newCDRWriter()
+	w.u32(1700000000)
+	w.u32(500)
+	w.str("base_link")
+	w.f64(21.5)
+	w.f64(0.01)
+	w.u32(2)
+	w.f32(1.5)
+	w.f32(2.5)
+	w.Write([]byte{7, 8, 9})
+
+	msg := Message{Channel: &Channel{Topic: "/temp", MessageEncoding: "cdr"}, Schema: schema, Data: w.Bytes()}
+	v, err := msg.Decode()
+	if err != nil {
+		t.Fatalf("Error decoding message: %v", err)
+	}
+	for path, want := range map[string]float64{
+		"temperature":          21.5,
+		"header.stamp.sec":     1700000000,
+		"header.stamp.nanosec": 500,
+		"samples.1":            2.5,
+		"tag.2":                9,
+	} {
+		if got, err := Number(v, path); err != nil || got != want {
+			t.Errorf("Expected %s = %g, got %g, %v", path, want, got, err)
+		}
+	}
+	if frame := v.(map[string]any)["header"].(map[string]any)["frame_id"]; frame != "base_link" {
+		t.Errorf("Expected frame_id base_link, got %v", frame)
+	}
+
+	msg.Data = msg.Data[:len(msg.Data)-2]
+	if _, err := msg.Decode(); err == nil {
+		t.Errorf("Expected a truncated message to fail")
+	}
+}
+
+// Test that schemas with missing or recursive definitions are rejected
+func TestParseROS2Msg_Errors(t *testing.T) {
+	if _, err := parseROS2Msg("pkg/msg/A", "pkg/B b\n"); err == nil {
+		t.Errorf("Expected a missing definition to fail")
+	}
+	if _, err := parseROS2Msg("pkg/A", "B b\n===\nMSG: pkg/B\nA a\n"); err == nil {
+		t.Errorf("Expected a recursive definition to fail")
+	}
+}
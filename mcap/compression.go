@@ -0,0 +1,59 @@
+package mcap
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/pierrec/lz4/v4"
+)
+
+// zstdWindow is the largest zstd window accepted for chunks declared
+// smaller than it, enough for the default window of common encoders.
+const zstdWindow = 8 << 20
+
+// readChunk reads the decompressed records of a chunk from r. It reads one
+// byte past size so an oversized chunk is noticed without expanding all of
+// it.
+func readChunk(r io.Reader, size uint64) ([]byte, error) {
+	var buf bytes.Buffer
+	if _, err := io.Copy(&buf, io.LimitReader(r, int64(min(size, math.MaxInt64-1))+1)); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// decompress returns the records of a chunk stored with compression, which
+// must expand to exactly size bytes.
+func decompress(compression string, data []byte, size uint64) ([]byte, error) {
+	var out []byte
+	switch compression {
+	case "":
+		out = data
+	case "zstd":
+		// The window is allocated up front, so it may only exceed the
+		// common 8 MiB when the chunk is declared that large.
+		dec, err := zstd.NewReader(bytes.NewReader(data), zstd.WithDecoderConcurrency(1), zstd.WithDecoderLowmem(true), zstd.WithDecoderMaxWindow(max(size, zstdWindow)))
+		if err != nil {
+			return nil, fmt.Errorf("mcap: failed to decompress zstd chunk: %w", err)
+		}
+		defer dec.Close()
+		if out, err = readChunk(dec, size); err != nil {
+			return nil, fmt.Errorf("mcap: failed to decompress zstd chunk: %w", err)
+		}
+	case "lz4":
+		var err error
+		if out, err = readChunk(lz4.NewReader(bytes.NewReader(data)), size); err != nil {
+			return nil, fmt.Errorf("mcap: failed to decompress lz4 chunk: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("mcap: unsupported chunk compression %q", compression)
+	}
+
+	if uint64(len(out)) != size {
+		return nil, fmt.Errorf("mcap: chunk expands to %d bytes, expected %d", len(out), size)
+	}
+	return out, nil
+}
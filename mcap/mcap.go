@@ -0,0 +1,181 @@
+// Package mcap reads the parts of the MCAP container format that the
+// anchoring tools need: the record framing, Metadata records, messages in
+// plain or zstd and lz4 compressed chunks, JSON and ROS 2 CDR message
+// decoding and, where required, the summary section.
+//
+// See https://mcap.dev/spec for the on-disk layout.
+package mcap
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Magic is the 8 byte sequence that starts and ends every MCAP file.
+var Magic = []byte{0x89, 'M', 'C', 'A', 'P', '0', '\r', '\n'}
+
+// Opcode identifies the type of a top-level record.
+type Opcode byte
+
+// Record opcodes defined by the MCAP specification.
+const (
+	OpHeader          Opcode = 0x01
+	OpFooter          Opcode = 0x02
+	OpSchema          Opcode = 0x03
+	OpChannel         Opcode = 0x04
+	OpMessage         Opcode = 0x05
+	OpChunk           Opcode = 0x06
+	OpMessageIndex    Opcode = 0x07
+	OpChunkIndex      Opcode = 0x08
+	OpAttachment      Opcode = 0x09
+	OpAttachmentIndex Opcode = 0x0A
+	OpStatistics      Opcode = 0x0B
+	OpMetadata        Opcode = 0x0C
+	OpMetadataIndex   Opcode = 0x0D
+	OpSummaryOffset   Opcode = 0x0E
+	OpDataEnd         Opcode = 0x0F
+)
+
+// ErrBadMagic is returned when a file does not start with the MCAP magic.
+var ErrBadMagic = errors.New("mcap: not an MCAP file")
+
+// Lexer walks the top-level records of an MCAP file. Record bodies are only
+// read when asked for, so large chunks can be skipped with a seek.
+type Lexer struct {
+	r       io.ReadSeeker
+	pending int64 // unread bytes of the current record body
+	offset  int64 // file offset of the current record
+	size    int64 // length of the input, which no record may run past
+}
+
+// NewLexer checks the leading magic of r and positions the lexer on the
+// first record.
+func NewLexer(r io.ReadSeeker) (*Lexer, error) {
+	magic := make([]byte, len(Magic))
+	if _, err := io.ReadFull(r, magic); err != nil {
+		return nil, fmt.Errorf("mcap: failed to read magic: %w", err)
+	}
+	if !bytes.Equal(magic, Magic) {
+		return nil, ErrBadMagic
+	}
+	size, err := r.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := r.Seek(int64(len(Magic)), io.SeekStart); err != nil {
+		return nil, err
+	}
+	return &Lexer{r: r, offset: int64(len(Magic)), size: size}, nil
+}
+
+// Next advances to the next record and returns its opcode and body length.
+// It returns io.EOF once the trailing magic or the end of input is reached,
+// and an error matching io.ErrUnexpectedEOF for a record whose length runs
+// past the end of the input.
+func (l *Lexer) Next() (Opcode, uint64, error) {
+	if l.pending > 0 {
+		if _, err := l.r.Seek(l.pending, io.SeekCurrent); err != nil {
+			return 0, 0, err
+		}
+		l.offset += l.pending
+		l.pending = 0
+	}
+
+	var header [9]byte
+	n, err := io.ReadFull(l.r, header[:])
+	if err == io.EOF || (err == io.ErrUnexpectedEOF && bytes.Equal(header[:n], Magic[:n])) {
+		return 0, 0, io.EOF
+	}
+	if err != nil {
+		return 0, 0, fmt.Errorf("mcap: failed to read record header: %w", err)
+	}
+	if bytes.Equal(header[:len(Magic)], Magic) {
+		return 0, 0, io.EOF
+	}
+
+	l.offset += int64(len(header))
+	length := binary.LittleEndian.Uint64(header[1:])
+	if remaining := l.size - l.offset; length > uint64(remaining) {
+		return 0, 0, fmt.Errorf("mcap: record at offset %d is %d bytes long but only %d remain: %w",
+			l.offset-int64(len(header)), length, remaining, io.ErrUnexpectedEOF)
+	}
+	l.pending = int64(length)
+	return Opcode(header[0]), length, nil
+}
+
+// Offset returns the file offset of the body of the current record.
+func (l *Lexer) Offset() int64 {
+	return l.offset
+}
+
+// Body reads the full body of the current record.
+func (l *Lexer) Body() ([]byte, error) {
+	body := make([]byte, l.pending)
+	if _, err := io.ReadFull(l.r, body); err != nil {
+		return nil, fmt.Errorf("mcap: truncated record: %w", err)
+	}
+	l.offset += l.pending
+	l.pending = 0
+	return body, nil
+}
+
+// parser decodes the primitive types used inside record bodies. The first
+// error is sticky so callers can check once at the end.
+type parser struct {
+	buf []byte
+	err error
+}
+
+func (p *parser) take(n uint64) []byte {
+	if p.err != nil {
+		return nil
+	}
+	if uint64(len(p.buf)) < n {
+		p.err = io.ErrUnexpectedEOF
+		return nil
+	}
+	out := p.buf[:n]
+	p.buf = p.buf[n:]
+	return out
+}
+
+func (p *parser) u16() uint16 {
+	if b := p.take(2); b != nil {
+		return binary.LittleEndian.Uint16(b)
+	}
+	return 0
+}
+
+func (p *parser) u32() uint32 {
+	if b := p.take(4); b != nil {
+		return binary.LittleEndian.Uint32(b)
+	}
+	return 0
+}
+
+func (p *parser) u64() uint64 {
+	if b := p.take(8); b != nil {
+		return binary.LittleEndian.Uint64(b)
+	}
+	return 0
+}
+
+func (p *parser) str() string {
+	return string(p.take(uint64(p.u32())))
+}
+
+func (p *parser) strMap() map[string]string {
+	sub := parser{buf: p.take(uint64(p.u32()))}
+	out := make(map[string]string)
+	for p.err == nil && len(sub.buf) > 0 {
+		key := sub.str()
+		out[key] = sub.str()
+		if sub.err != nil {
+			p.err = sub.err
+		}
+	}
+	return out
+}
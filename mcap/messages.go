@@ -0,0 +1,257 @@
+package mcap
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Schema describes the layout of the messages on one or more channels.
+type Schema struct {
+	ID       uint16
+	Name     string
+	Encoding string
+	Data     []byte
+
+	// The parsed ros2msg definition, built on first use.
+	once sync.Once
+	msg  *msgType
+	err  error
+}
+
+// ros2 returns the parsed ros2msg definition of s.
+func (s *Schema) ros2() (*msgType, error) {
+	s.once.Do(func() {
+		s.msg, s.err = parseROS2Msg(s.Name, string(s.Data))
+	})
+	return s.msg, s.err
+}
+
+// Channel is a stream of messages on one topic.
+type Channel struct {
+	ID              uint16
+	SchemaID        uint16
+	Topic           string
+	MessageEncoding string
+	Metadata        map[string]string
+}
+
+// Message is a single timestamped message. Channel and Schema are those it
+// was recorded with; Schema is nil for schemaless channels.
+type Message struct {
+	Channel     *Channel
+	Schema      *Schema
+	Sequence    uint32
+	LogTime     uint64
+	PublishTime uint64
+	Data        []byte
+}
+
+// ReadMessages calls fn for every message in the data section of r, in file
+// order, including messages stored in chunks. Iteration stops at the first
+// error returned by fn.
+func ReadMessages(r io.ReadSeeker, fn func(Message) error) error {
+	lexer, err := NewLexer(r)
+	if err != nil {
+		return err
+	}
+
+	s := &stream{
+		schemas:  make(map[uint16]*Schema),
+		channels: make(map[uint16]*Channel),
+		fn:       fn,
+	}
+	for {
+		op, _, err := lexer.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if op == OpDataEnd || op == OpFooter {
+			return nil
+		}
+		if op != OpSchema && op != OpChannel && op != OpMessage && op != OpChunk {
+			continue
+		}
+
+		body, err := lexer.Body()
+		if err != nil {
+			return err
+		}
+		if op == OpChunk {
+			err = s.chunk(body)
+		} else {
+			err = s.record(op, body)
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// stream tracks the schemas and channels seen so far while reading messages.
+type stream struct {
+	schemas  map[uint16]*Schema
+	channels map[uint16]*Channel
+	fn       func(Message) error
+}
+
+// record handles a Schema, Channel or Message record.
+func (s *stream) record(op Opcode, body []byte) error {
+	p := parser{buf: body}
+	switch op {
+	case OpSchema:
+		schema := &Schema{ID: p.u16(), Name: p.str(), Encoding: p.str()}
+		schema.Data = p.take(uint64(p.u32()))
+		if p.err != nil {
+			return fmt.Errorf("mcap: malformed schema record: %w", p.err)
+		}
+		s.schemas[schema.ID] = schema
+
+	case OpChannel:
+		channel := &Channel{ID: p.u16(), SchemaID: p.u16(), Topic: p.str(), MessageEncoding: p.str(), Metadata: p.strMap()}
+		if p.err != nil {
+			return fmt.Errorf("mcap: malformed channel record: %w", p.err)
+		}
+		s.channels[channel.ID] = channel
+
+	case OpMessage:
+		channelID := p.u16()
+		msg := Message{Sequence: p.u32(), LogTime: p.u64(), PublishTime: p.u64()}
+		if p.err != nil {
+			return fmt.Errorf("mcap: malformed message record: %w", p.err)
+		}
+		msg.Data = p.buf
+		msg.Channel = s.channels[channelID]
+		if msg.Channel == nil {
+			return fmt.Errorf("mcap: message on unknown channel %d", channelID)
+		}
+		msg.Schema = s.schemas[msg.Channel.SchemaID]
+		return s.fn(msg)
+	}
+	return nil
+}
+
+// chunk handles the records inside a Chunk record, decompressing them and
+// checking their CRC when the writer stored one.
+func (s *stream) chunk(body []byte) error {
+	p := parser{buf: body}
+	p.u64() // message start time
+	p.u64() // message end time
+	size := p.u64()
+	crc := p.u32()
+	compression := p.str()
+	records := p.take(p.u64())
+	if p.err != nil {
+		return fmt.Errorf("mcap: malformed chunk record: %w", p.err)
+	}
+	records, err := decompress(compression, records, size)
+	if err != nil {
+		return err
+	}
+	if crc != 0 && crc32.ChecksumIEEE(records) != crc {
+		return fmt.Errorf("mcap: chunk CRC mismatch")
+	}
+
+	inner := parser{buf: records}
+	for len(inner.buf) > 0 {
+		op := Opcode(inner.take(1)[0])
+		body := inner.take(inner.u64())
+		if inner.err != nil {
+			return fmt.Errorf("mcap: malformed record in chunk: %w", inner.err)
+		}
+		if err := s.record(op, body); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Decode returns the content of m as generic values. JSON messages decode
+// to maps, slices, strings, float64s and bools. ROS 2 CDR messages with a
+// ros2msg schema decode to maps keyed by field name, with integers and
+// floats of their ROS type, []byte for byte arrays and []any for other
+// arrays.
+func (m Message) Decode() (any, error) {
+	switch m.Channel.MessageEncoding {
+	case "json":
+		var v any
+		if err := json.Unmarshal(m.Data, &v); err != nil {
+			return nil, fmt.Errorf("mcap: malformed JSON message on %s: %w", m.Channel.Topic, err)
+		}
+		return v, nil
+	case "cdr":
+		if m.Schema == nil || m.Schema.Encoding != "ros2msg" {
+			return nil, fmt.Errorf("mcap: CDR message on %s has no ros2msg schema", m.Channel.Topic)
+		}
+		t, err := m.Schema.ros2()
+		if err != nil {
+			return nil, err
+		}
+		return decodeCDR(m.Data, t)
+	default:
+		return nil, fmt.Errorf("mcap: unsupported message encoding %q on %s", m.Channel.MessageEncoding, m.Channel.Topic)
+	}
+}
+
+// Number returns the numeric field at path in a decoded message. Path is a
+// dotted list of field names and array indexes, such as "pose.position.z"
+// or "ranges.0".
+func Number(v any, path string) (float64, error) {
+	if path != "" {
+		for _, field := range strings.Split(path, ".") {
+			switch node := v.(type) {
+			case map[string]any:
+				var ok bool
+				if v, ok = node[field]; !ok {
+					return 0, fmt.Errorf("mcap: no field %q in %q", field, path)
+				}
+			case []any:
+				i, err := strconv.Atoi(field)
+				if err != nil || i < 0 || i >= len(node) {
+					return 0, fmt.Errorf("mcap: no element %q in %q", field, path)
+				}
+				v = node[i]
+			case []byte:
+				i, err := strconv.Atoi(field)
+				if err != nil || i < 0 || i >= len(node) {
+					return 0, fmt.Errorf("mcap: no element %q in %q", field, path)
+				}
+				v = node[i]
+			default:
+				return 0, fmt.Errorf("mcap: %q does not lead to a field", path)
+			}
+		}
+	}
+
+	switch n := v.(type) {
+	case float64:
+		return n, nil
+	case float32:
+		return float64(n), nil
+	case int8:
+		return float64(n), nil
+	case int16:
+		return float64(n), nil
+	case int32:
+		return float64(n), nil
+	case int64:
+		return float64(n), nil
+	case uint8:
+		return float64(n), nil
+	case uint16:
+		return float64(n), nil
+	case uint32:
+		return float64(n), nil
+	case uint64:
+		return float64(n), nil
+	default:
+		return 0, fmt.Errorf("mcap: %q is not a number", path)
+	}
+}
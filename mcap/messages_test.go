@@ -0,0 +1,157 @@
+package mcap
+
+import (
+	"bytes"
+	"fmt"
+	"hash/crc32"
+	"runtime"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/pierrec/lz4/v4"
+)
+
+func channelRecord(id uint16, topic string, encoding string) []byte {
+	var b recordBuilder
+	b.u16(id)
+	b.u16(0)
+	b.str(topic)
+	b.str(encoding)
+	b.strMap(nil)
+	return record(OpChannel, b.Bytes())
+}
+
+func messageRecord(channel uint16, sequence uint32, data string) []byte {
+	var b recordBuilder
+	b.u16(channel)
+	b.u32(sequence)
+	b.u64(uint64(sequence) * 1000)
+	b.u64(uint64(sequence) * 1000)
+	b.WriteString(data)
+	return record(OpMessage, b.Bytes())
+}
+
+// chunkRecord wraps records in a Chunk record compressed with compression.
+func chunkRecord(t *testing.T, compression string, records ...[]byte) []byte {
+	t.Helper()
+	raw := bytes.Join(records, nil)
+	var compressed bytes.Buffer
+	switch compression {
+	case "":
+		compressed.Write(raw)
+	case "zstd":
+		w, err := zstd.NewWriter(&compressed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write(raw)
+		w.Close()
+	case "lz4":
+		w := lz4.NewWriter(&compressed)
+		w.Write(raw)
+		w.Close()
+	default:
+		compressed.WriteString("opaque")
+	}
+
+	var b recordBuilder
+	b.u64(0)
+	b.u64(0)
+	b.u64(uint64(len(raw)))
+	b.u32(crc32.ChecksumIEEE(raw))
+	b.str(compression)
+	b.u64(uint64(compressed.Len()))
+	b.Write(compressed.Bytes())
+	return record(OpChunk, b.Bytes())
+}
+
+// Test reading messages from the data section and from plain, zstd and lz4
+// chunks
+func TestReadMessages(t *testing.T) {
+	data := testFile(
+		channelRecord(1, "/env", "json"),
+		messageRecord(1, 1, `{"temperature": 21.5}`),
+		chunkRecord(t, "zstd",
+			channelRecord(2, "/imu", "json"),
+			messageRecord(2, 2, `{"accel": [0.1, 9.8, 0.2]}`),
+			messageRecord(1, 3, `{"temperature": 22}`),
+		),
+		chunkRecord(t, "lz4", messageRecord(1, 4, `{"temperature": 23}`)),
+		chunkRecord(t, "", messageRecord(1, 5, `{"temperature": 24}`)),
+		record(OpDataEnd, []byte{0, 0, 0, 0}),
+		messageRecord(1, 6, `{"temperature": 99}`),
+	)
+
+	var values []float64
+	err := ReadMessages(bytes.NewReader(data), func(m Message) error {
+		v, err := m.Decode()
+		if err != nil {
+			return err
+		}
+		path := "temperature"
+		if m.Channel.Topic == "/imu" {
+			path = "accel.1"
+		}
+		n, err := Number(v, path)
+		values = append(values, n)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("Error reading messages: %v", err)
+	}
+	if fmt.Sprint(values) != "[21.5 9.8 22 23 24]" {
+		t.Errorf("Unexpected values %v", values)
+	}
+}
+
+// Test that a chunk expanding far past its declared size is refused
+// without expanding it
+func TestReadMessages_ZstdBomb(t *testing.T) {
+	var compressed bytes.Buffer
+	w, err := zstd.NewWriter(&compressed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write(make([]byte, 64<<20))
+	w.Close()
+
+	var b recordBuilder
+	b.u64(0)
+	b.u64(0)
+	b.u64(16)
+	b.u32(0)
+	b.str("zstd")
+	b.u64(uint64(compressed.Len()))
+	b.Write(compressed.Bytes())
+	data := testFile(record(OpChunk, b.Bytes()))
+
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	err = ReadMessages(bytes.NewReader(data), func(Message) error { return nil })
+	runtime.ReadMemStats(&after)
+	if err == nil {
+		t.Fatal("Expected an oversized chunk to be refused")
+	}
+	if allocated := after.TotalAlloc - before.TotalAlloc; allocated > 16<<20 {
+		t.Errorf("Expected the chunk not to be expanded, allocated %d bytes", allocated)
+	}
+}
+
+// Test that unreadable chunks and unknown fields are reported
+func TestReadMessages_Errors(t *testing.T) {
+	for name, chunk := range map[string][]byte{
+		"unknown compression": chunkRecord(t, "bz2", channelRecord(1, "/env", "json")),
+		"bad CRC":             bytes.Replace(chunkRecord(t, "", channelRecord(1, "/env", "json")), []byte("/env"), []byte("/eny"), 1),
+	} {
+		if err := ReadMessages(bytes.NewReader(testFile(chunk)), func(Message) error { return nil }); err == nil {
+			t.Errorf("Expected an error for %s", name)
+		}
+	}
+
+	if _, err := Number(map[string]any{"a": "text"}, "a"); err == nil {
+		t.Errorf("Expected an error for a non-numeric field")
+	}
+	if _, err := Number(map[string]any{"a": []any{1.0}}, "a.1"); err == nil {
+		t.Errorf("Expected an error for an index out of range")
+	}
+}
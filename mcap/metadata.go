@@ -0,0 +1,78 @@
+package mcap
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// Metadata is a named set of key/value pairs stored in a Metadata record.
+type Metadata struct {
+	Name     string
+	Metadata map[string]string
+}
+
+// ReadMetadata returns every Metadata record in the data section of r, in
+// file order.
+func ReadMetadata(r io.ReadSeeker) ([]Metadata, error) {
+	lexer, err := NewLexer(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var records []Metadata
+	for {
+		op, _, err := lexer.Next()
+		if err == io.EOF {
+			return records, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		// The summary section only repeats indexes, so stop at the end of data.
+		if op == OpDataEnd || op == OpFooter {
+			return records, nil
+		}
+		if op != OpMetadata {
+			continue
+		}
+
+		body, err := lexer.Body()
+		if err != nil {
+			return nil, err
+		}
+		p := parser{buf: body}
+		md := Metadata{Name: p.str(), Metadata: p.strMap()}
+		if p.err != nil {
+			return nil, fmt.Errorf("mcap: malformed metadata record: %w", p.err)
+		}
+		records = append(records, md)
+	}
+}
+
+// ReadMetadataFile opens filePath and returns its Metadata records merged by
+// name. Later records with the same name override earlier keys.
+func ReadMetadataFile(filePath string) (map[string]map[string]string, error) {
+	file, err := os.Open(filepath.Clean(filePath))
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	records, err := ReadMetadata(file)
+	if err != nil {
+		return nil, err
+	}
+
+	merged := make(map[string]map[string]string, len(records))
+	for _, md := range records {
+		if merged[md.Name] == nil {
+			merged[md.Name] = make(map[string]string, len(md.Metadata))
+		}
+		for k, v := range md.Metadata {
+			merged[md.Name][k] = v
+		}
+	}
+	return merged, nil
+}
@@ -0,0 +1,132 @@
+package mcap
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// recordBuilder assembles MCAP records for tests.
+type recordBuilder struct {
+	bytes.Buffer
+}
+
+func (b *recordBuilder) u16(v uint16) { binary.Write(&b.Buffer, binary.LittleEndian, v) }
+func (b *recordBuilder) u32(v uint32) { binary.Write(&b.Buffer, binary.LittleEndian, v) }
+func (b *recordBuilder) u64(v uint64) { binary.Write(&b.Buffer, binary.LittleEndian, v) }
+
+func (b *recordBuilder) str(s string) {
+	b.u32(uint32(len(s)))
+	b.WriteString(s)
+}
+
+func (b *recordBuilder) strMap(m map[string]string, keys ...string) {
+	var inner recordBuilder
+	for _, k := range keys {
+		inner.str(k)
+		inner.str(m[k])
+	}
+	b.u32(uint32(inner.Len()))
+	b.Write(inner.Bytes())
+}
+
+// record frames body as a top-level record with the given opcode.
+func record(op Opcode, body []byte) []byte {
+	var out recordBuilder
+	out.WriteByte(byte(op))
+	out.u64(uint64(len(body)))
+	out.Write(body)
+	return out.Bytes()
+}
+
+func metadataRecord(name string, m map[string]string, keys ...string) []byte {
+	var b recordBuilder
+	b.str(name)
+	b.strMap(m, keys...)
+	return record(OpMetadata, b.Bytes())
+}
+
+func testFile(records ...[]byte) []byte {
+	var out bytes.Buffer
+	out.Write(Magic)
+	for _, r := range records {
+		out.Write(r)
+	}
+	out.Write(Magic)
+	return out.Bytes()
+}
+
+// Test reading metadata records while skipping unrelated records
+func TestReadMetadata(t *testing.T) {
+	data := testFile(
+		record(OpHeader, []byte("\x00\x00\x00\x00\x00\x00\x00\x00")),
+		record(OpChunk, bytes.Repeat([]byte{0xff}, 64)),
+		metadataRecord("recording", map[string]string{"robot": "arm-2", "cell": "7"}, "robot", "cell"),
+		record(OpDataEnd, []byte{0, 0, 0, 0}),
+		metadataRecord("summary-only", map[string]string{"ignored": "yes"}, "ignored"),
+	)
+
+	records, err := ReadMetadata(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Error reading metadata: %v", err)
+	}
+	if len(records) != 1 {
+		t.Fatalf("Expected 1 metadata record, got %d", len(records))
+	}
+	if records[0].Name != "recording" || records[0].Metadata["robot"] != "arm-2" || records[0].Metadata["cell"] != "7" {
+		t.Errorf("Unexpected metadata record: %+v", records[0])
+	}
+}
+
+// Test that non-MCAP input is rejected
+func TestReadMetadata_BadMagic(t *testing.T) {
+	_, err := ReadMetadata(bytes.NewReader([]byte("definitely not an mcap file")))
+	if err != ErrBadMagic {
+		t.Errorf("Expected ErrBadMagic, got %v", err)
+	}
+}
+
+// Test that records claiming more bytes than the file holds are rejected
+// rather than allocated
+func TestReadMetadata_BadLength(t *testing.T) {
+	for _, length := range []uint64{0xFFFFFFFFFFFFFFF0, 1 << 40, 100} {
+		var header recordBuilder
+		header.WriteByte(byte(OpMetadata))
+		header.u64(length)
+		data := testFile(
+			metadataRecord("recording", map[string]string{"robot": "arm-1"}, "robot"),
+			append(header.Bytes(), "short body"...),
+		)
+
+		if _, err := ReadMetadata(bytes.NewReader(data)); !errors.Is(err, io.ErrUnexpectedEOF) {
+			t.Errorf("Expected a record of %d bytes to fail, got %v", length, err)
+		}
+		if err := ReadMessages(bytes.NewReader(data), func(Message) error { return nil }); !errors.Is(err, io.ErrUnexpectedEOF) {
+			t.Errorf("Expected a record of %d bytes to fail reading messages, got %v", length, err)
+		}
+	}
+}
+
+// Test merging of repeated metadata names from a file on disk
+func TestReadMetadataFile(t *testing.T) {
+	testPath := filepath.Join(t.TempDir(), "run.mcap")
+	data := testFile(
+		metadataRecord("recording", map[string]string{"robot": "arm-1"}, "robot"),
+		metadataRecord("recording", map[string]string{"robot": "arm-2", "shift": "B"}, "robot", "shift"),
+	)
+	if err := os.WriteFile(testPath, data, 0o600); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	merged, err := ReadMetadataFile(testPath)
+	if err != nil {
+		t.Fatalf("Error reading metadata: %v", err)
+	}
+	if merged["recording"]["robot"] != "arm-2" || merged["recording"]["shift"] != "B" {
+		t.Errorf("Unexpected merged metadata: %v", merged)
+	}
+}
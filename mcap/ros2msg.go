@@ -0,0 +1,172 @@
+package mcap
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// msgType is a parsed ROS 2 message definition.
+type msgType struct {
+	name   string
+	fields []msgField
+}
+
+// msgField is one field of a message definition. Complex is set for
+// fields holding nested messages; otherwise Type is a primitive type.
+type msgField struct {
+	name    string
+	typ     string
+	complex *msgType
+
+	// array is set for sequences and fixed arrays; length is the element
+	// count of a fixed array and 0 for sequences, which carry their own.
+	array  bool
+	length int
+}
+
+// primitiveTypes are the ROS 2 built-in field types.
+var primitiveTypes = map[string]bool{
+	"bool": true, "byte": true, "char": true,
+	"int8": true, "uint8": true, "int16": true, "uint16": true,
+	"int32": true, "uint32": true, "int64": true, "uint64": true,
+	"float32": true, "float64": true, "string": true, "wstring": true,
+}
+
+// builtinDefinitions cover types recorders commonly leave out of the
+// concatenated schema.
+var builtinDefinitions = map[string]string{
+	"builtin_interfaces/Time":     "int32 sec\nuint32 nanosec\n",
+	"builtin_interfaces/Duration": "int32 sec\nuint32 nanosec\n",
+}
+
+// parseROS2Msg parses a ros2msg schema: the definition of name followed by
+// the definitions it depends on, each introduced by a line of "=" and a
+// "MSG: package/Type" line.
+func parseROS2Msg(name string, text string) (*msgType, error) {
+	sections := map[string][]string{}
+	current := normalizeType(name)
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if len(line) >= 3 && strings.Trim(line, "=") == "" {
+			current = ""
+			continue
+		}
+		if current == "" {
+			if dep, ok := strings.CutPrefix(line, "MSG:"); ok {
+				current = normalizeType(strings.TrimSpace(dep))
+			}
+			continue
+		}
+		sections[current] = append(sections[current], line)
+	}
+	for dep, def := range builtinDefinitions {
+		if _, ok := sections[dep]; !ok {
+			sections[dep] = strings.Split(def, "\n")
+		}
+	}
+
+	r := &msgResolver{sections: sections, types: map[string]*msgType{}}
+	return r.resolve(normalizeType(name), nil)
+}
+
+// msgResolver turns the sections of a schema into linked message types.
+type msgResolver struct {
+	sections map[string][]string
+	types    map[string]*msgType
+}
+
+func (r *msgResolver) resolve(name string, seen []string) (*msgType, error) {
+	if t, ok := r.types[name]; ok {
+		return t, nil
+	}
+	for _, s := range seen {
+		if s == name {
+			return nil, fmt.Errorf("mcap: message type %s contains itself", name)
+		}
+	}
+	lines, ok := r.sections[name]
+	if !ok {
+		return nil, fmt.Errorf("mcap: schema has no definition of %s", name)
+	}
+	pkg, _, _ := strings.Cut(name, "/")
+
+	t := &msgType{name: name}
+	for _, line := range lines {
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) < 2 {
+			return nil, fmt.Errorf("mcap: malformed field %q in %s", line, name)
+		}
+		// Constants take no space in the message.
+		if strings.Contains(fields[1], "=") || (len(fields) > 2 && strings.HasPrefix(fields[2], "=")) {
+			continue
+		}
+
+		f, err := parseFieldType(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("mcap: %s.%s: %w", name, fields[1], err)
+		}
+		f.name = fields[1]
+		if !primitiveTypes[f.typ] {
+			f.typ = qualifyType(f.typ, pkg)
+			if f.complex, err = r.resolve(f.typ, append(seen, name)); err != nil {
+				return nil, err
+			}
+		}
+		t.fields = append(t.fields, f)
+	}
+	r.types[name] = t
+	return t, nil
+}
+
+// parseFieldType splits a field type such as "float64[3]", "string<=10"
+// or "geometry_msgs/Point[]" into its element type and array shape.
+func parseFieldType(s string) (msgField, error) {
+	var f msgField
+	if i := strings.IndexByte(s, '['); i >= 0 {
+		if !strings.HasSuffix(s, "]") {
+			return f, fmt.Errorf("malformed array type %q", s)
+		}
+		f.array = true
+		bound := s[i+1 : len(s)-1]
+		s = s[:i]
+		if bound != "" && !strings.HasPrefix(bound, "<=") {
+			n, err := strconv.Atoi(bound)
+			if err != nil || n < 0 {
+				return f, fmt.Errorf("malformed array length %q", bound)
+			}
+			f.length = n
+		}
+	}
+	// Bounded strings are encoded like unbounded ones.
+	s, _, _ = strings.Cut(s, "<=")
+	f.typ = s
+	return f, nil
+}
+
+// normalizeType drops the "msg" part of "package/msg/Type".
+func normalizeType(name string) string {
+	if parts := strings.Split(name, "/"); len(parts) == 3 {
+		return parts[0] + "/" + parts[2]
+	}
+	return name
+}
+
+// qualifyType resolves a nested type name used in a definition of pkg.
+func qualifyType(name string, pkg string) string {
+	name = normalizeType(name)
+	switch {
+	case strings.Contains(name, "/"):
+		return name
+	case name == "Header":
+		return "std_msgs/Header"
+	default:
+		return pkg + "/" + name
+	}
+}
@@ -0,0 +1,100 @@
+package mcap
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// footerSize is the length of the Footer record, including its opcode and
+// length prefix, that precedes the trailing magic.
+const footerSize = 1 + 8 + 20
+
+// ErrNoSummary is returned for files written without a summary section,
+// such as recordings cut short by a crash.
+var ErrNoSummary = errors.New("mcap: file has no summary section")
+
+// ChunkIndex locates one Chunk record and the time range of its messages.
+// Times are nanoseconds since the epoch.
+type ChunkIndex struct {
+	MessageStartTime uint64
+	MessageEndTime   uint64
+
+	// ChunkStartOffset is the file offset of the Chunk record's opcode and
+	// ChunkLength the length of the whole record.
+	ChunkStartOffset uint64
+	ChunkLength      uint64
+
+	Compression      string
+	CompressedSize   uint64
+	UncompressedSize uint64
+}
+
+// ReadChunkIndexes returns the ChunkIndex records of the summary section
+// of r, in file order.
+func ReadChunkIndexes(r io.ReadSeeker) ([]ChunkIndex, error) {
+	if _, err := NewLexer(r); err != nil {
+		return nil, err
+	}
+	end, err := r.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil, err
+	}
+	if end < int64(len(Magic))+footerSize+int64(len(Magic)) {
+		return nil, ErrNoSummary
+	}
+	if _, err := r.Seek(end-int64(len(Magic))-footerSize, io.SeekStart); err != nil {
+		return nil, err
+	}
+	footer := make([]byte, footerSize)
+	if _, err := io.ReadFull(r, footer); err != nil {
+		return nil, fmt.Errorf("mcap: failed to read footer: %w", err)
+	}
+	if Opcode(footer[0]) != OpFooter {
+		return nil, ErrNoSummary
+	}
+	p := parser{buf: footer[9:]}
+	summaryStart := p.u64()
+	if summaryStart == 0 {
+		return nil, ErrNoSummary
+	}
+	if _, err := r.Seek(int64(summaryStart), io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	lexer := &Lexer{r: r, offset: int64(summaryStart), size: end}
+	var indexes []ChunkIndex
+	for {
+		op, _, err := lexer.Next()
+		if err == io.EOF || op == OpFooter {
+			return indexes, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if op != OpChunkIndex {
+			continue
+		}
+
+		body, err := lexer.Body()
+		if err != nil {
+			return nil, err
+		}
+		p := parser{buf: body}
+		ci := ChunkIndex{
+			MessageStartTime: p.u64(),
+			MessageEndTime:   p.u64(),
+			ChunkStartOffset: p.u64(),
+			ChunkLength:      p.u64(),
+		}
+		p.take(uint64(p.u32())) // message index offsets
+		p.u64()                 // message index length
+		ci.Compression = p.str()
+		ci.CompressedSize = p.u64()
+		ci.UncompressedSize = p.u64()
+		if p.err != nil {
+			return nil, fmt.Errorf("mcap: malformed chunk index record: %w", p.err)
+		}
+		indexes = append(indexes, ci)
+	}
+}
@@ -0,0 +1,53 @@
+package mcap
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func chunkIndexRecord(start, end, offset, length uint64) []byte {
+	var b recordBuilder
+	b.u64(start)
+	b.u64(end)
+	b.u64(offset)
+	b.u64(length)
+	b.u32(0)
+	b.u64(0)
+	b.str("")
+	b.u64(0)
+	b.u64(0)
+	return record(OpChunkIndex, b.Bytes())
+}
+
+func footerRecord(summaryStart uint64) []byte {
+	var b recordBuilder
+	b.u64(summaryStart)
+	b.u64(0)
+	b.u32(0)
+	return record(OpFooter, b.Bytes())
+}
+
+// Test reading chunk indexes through the footer, and files without a summary
+func TestReadChunkIndexes(t *testing.T) {
+	data := testFile(record(OpDataEnd, []byte{0, 0, 0, 0}))
+	data = data[:len(data)-len(Magic)]
+	summaryStart := uint64(len(data))
+	data = append(data, chunkIndexRecord(10, 20, 8, 100)...)
+	data = append(data, chunkIndexRecord(30, 40, 108, 50)...)
+	data = append(data, footerRecord(summaryStart)...)
+	data = append(data, Magic...)
+
+	indexes, err := ReadChunkIndexes(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Error reading chunk indexes: %v", err)
+	}
+	if len(indexes) != 2 || indexes[1].MessageStartTime != 30 || indexes[1].ChunkStartOffset != 108 || indexes[1].ChunkLength != 50 {
+		t.Errorf("Unexpected chunk indexes %+v", indexes)
+	}
+
+	noSummary := testFile(record(OpDataEnd, []byte{0, 0, 0, 0}), footerRecord(0))
+	if _, err := ReadChunkIndexes(bytes.NewReader(noSummary)); !errors.Is(err, ErrNoSummary) {
+		t.Errorf("Expected ErrNoSummary, got %v", err)
+	}
+}
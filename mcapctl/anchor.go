@@ -0,0 +1,152 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/Octavian-Anghel/Capstone-Project/hashlib"
+	"github.com/Octavian-Anghel/Capstone-Project/ledger"
+	"github.com/Octavian-Anghel/Capstone-Project/mcap"
+	"github.com/spf13/cobra"
+)
+
+// errVerifyFailed is returned once a verify result has been printed, so
+// the command exits non-zero without repeating it.
+var errVerifyFailed = errors.New("verification failed")
+
+// Verify outcomes. verify-tree adds files missing on disk and files it
+// could not read to the ledger statuses.
+const (
+	statusMatched         = ledger.StatusMatched
+	statusMismatched      = ledger.StatusMismatched
+	statusRevoked         = ledger.StatusRevoked
+	statusMissingOnLedger = ledger.StatusMissingOnLedger
+	statusMissingOnDisk   = "missing-on-disk"
+	statusUnreadable      = "unreadable"
+)
+
+type anchorResult struct {
+	McapID      string `json:"mcapId"`
+	Path        string `json:"path"`
+	Hash        string `json:"hash"`
+	Algorithm   string `json:"algorithm"`
+	TxID        string `json:"txId"`
+	BlockNumber uint64 `json:"blockNumber"`
+	Peer        string `json:"peer"`
+}
+
+func newAnchorCmd(a *app) *cobra.Command {
+	var asset ledger.NewAsset
+	cmd := &cobra.Command{
+		Use:   "anchor <file>",
+		Short: "Hash a recording and anchor it on the ledger",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			h, err := hashFile(cmd.Context(), args[0], asset.Algorithm)
+			if err != nil {
+				return err
+			}
+			asset.Hash, asset.Algorithm = h.Hash, h.Algorithm
+			if asset.Path, err = filepath.Abs(h.Path); err != nil {
+				return err
+			}
+			asset.CaptureTime = time.Now()
+			tree, err := hashlib.HashChunks(cmd.Context(), args[0], nil)
+			switch {
+			case err == nil:
+				asset.ChunkRoot = tree.Root
+			case !errors.Is(err, mcap.ErrNoSummary) && !errors.Is(err, hashlib.ErrNoChunks):
+				return fmt.Errorf("failed to hash chunks of %s: %w", args[0], err)
+			}
+			if asset.McapID == "" {
+				asset.McapID = ledger.ContentID(h.Hash)
+			}
+
+			return a.withClient(func(ctx context.Context, client *ledger.Client) error {
+				txStatus, err := client.CreateAsset(ctx, asset)
+				if err != nil {
+					return fmt.Errorf("failed to anchor %s: %w", h.Path, err)
+				}
+
+				result := anchorResult{
+					McapID:      asset.McapID,
+					Path:        asset.Path,
+					Hash:        asset.Hash,
+					Algorithm:   asset.Algorithm,
+					TxID:        txStatus.TransactionID,
+					BlockNumber: txStatus.BlockNumber,
+					Peer:        txStatus.Peer,
+				}
+				t := table{header: []string{"MCAP ID", "HASH", "TX ID", "BLOCK"}}
+				t.add(result.McapID, result.Hash, result.TxID, strconv.FormatUint(result.BlockNumber, 10))
+				return a.render(cmd.OutOrStdout(), result, t)
+			})
+		},
+	}
+	cmd.Flags().StringVar(&asset.McapID, "id", "", "asset ID (default: derived from the hash like the daemon's content scheme)")
+	cmd.Flags().StringVar(&asset.Operation, "operation", "", "operation ID of the recording")
+	cmd.Flags().StringVar(&asset.Project, "project", "", "project of the recording")
+	cmd.Flags().StringVarP(&asset.Algorithm, "algorithm", "a", hashlib.DefaultAlgorithm, algorithmUsage)
+	return cmd
+}
+
+type verifyResult struct {
+	Path string `json:"path"`
+	ledger.Verification
+}
+
+func newVerifyCmd(a *app) *cobra.Command {
+	var mcapID, algorithm string
+	cmd := &cobra.Command{
+		Use:   "verify <file>",
+		Short: "Check a recording against the hash anchored on the ledger",
+		Long: "Check a recording against the hash anchored on the ledger. The recording\n" +
+			"is hashed with --algorithm, which names the content asset ID unless --id\n" +
+			"is given, and again with the algorithm the asset was anchored with if\n" +
+			"that differs. The command exits non-zero unless the hashes match and the\n" +
+			"asset is not revoked.",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			h, err := hashFile(cmd.Context(), args[0], algorithm)
+			if err != nil {
+				return err
+			}
+			result := verifyResult{Path: h.Path}
+			err = a.withClient(func(ctx context.Context, client *ledger.Client) error {
+				v, err := client.Verify(ctx, mcapID, h.Hash)
+				if err != nil {
+					return fmt.Errorf("failed to verify %s: %w", h.Path, err)
+				}
+				result.Verification = *v
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			err = result.Recheck(h.Algorithm, func(algorithm string) (string, error) {
+				h, err := hashFile(cmd.Context(), args[0], algorithm)
+				return h.Hash, err
+			})
+			if err != nil {
+				return fmt.Errorf("failed to verify %s: %w", args[0], err)
+			}
+
+			t := table{header: []string{"PATH", "MCAP ID", "STATUS"}}
+			t.add(result.Path, result.McapID, result.Status)
+			if err := a.render(cmd.OutOrStdout(), result, t); err != nil {
+				return err
+			}
+			if result.Status != statusMatched {
+				return errVerifyFailed
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&mcapID, "id", "", "asset ID (default: derived from the hash like the daemon's content scheme)")
+	cmd.Flags().StringVarP(&algorithm, "algorithm", "a", hashlib.DefaultAlgorithm, algorithmUsage)
+	return cmd
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/Octavian-Anghel/Capstone-Project/ledger"
+	"github.com/spf13/cobra"
+)
+
+func newShowCmd(a *app) *cobra.Command {
+	return &cobra.Command{
+		Use:   "show <mcap-id>",
+		Short: "Show an anchored asset",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return a.withClient(func(ctx context.Context, client *ledger.Client) error {
+				asset, err := client.ReadAsset(ctx, args[0])
+				if err != nil {
+					return fmt.Errorf("failed to read asset %s: %w", args[0], err)
+				}
+
+				t := table{}
+				t.add("MCAP ID", asset.McapID)
+				t.add("HASH", asset.Hash)
+				t.add("ALGORITHM", asset.HashAlgorithm())
+				t.add("ANCHORED", asset.Datetime)
+				t.add("CAPTURED", asset.CaptureTime)
+				t.add("OPERATION", asset.Operation)
+				t.add("PROJECT", asset.Project)
+				t.add("PATH", asset.Path)
+				if asset.ChunkRoot != "" {
+					t.add("CHUNK ROOT", asset.ChunkRoot)
+				}
+				if r := asset.Revocation; r != nil {
+					t.add("REVOKED", fmt.Sprintf("%s by %s: %s", r.RevokedAt, r.RevokedBy, r.Reason))
+				}
+				if s := asset.Sensors; s != nil {
+					for _, r := range s.Readings {
+						t.add("SENSOR", fmt.Sprintf("%s mean %g %s, min %g, max %g, %d samples (%s)", r.Name, r.Mean, r.Unit, r.Min, r.Max, r.Count, s.Source))
+					}
+				}
+				return a.render(cmd.OutOrStdout(), asset, t)
+			})
+		},
+	}
+}
+
+func newHistoryCmd(a *app) *cobra.Command {
+	return &cobra.Command{
+		Use:   "history <mcap-id>",
+		Short: "List every committed change to an asset, newest first",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return a.withClient(func(ctx context.Context, client *ledger.Client) error {
+				history, err := client.AssetHistory(ctx, args[0])
+				if err != nil {
+					return fmt.Errorf("failed to read history of %s: %w", args[0], err)
+				}
+
+				t := table{header: []string{"TIMESTAMP", "TX ID", "CHANGE", "HASH"}}
+				changes := ledger.Changes(history)
+				for i, entry := range history {
+					var hash string
+					if entry.Asset != nil {
+						hash = entry.Asset.Hash
+					}
+					t.add(entry.Timestamp, entry.TxID, changes[i], hash)
+				}
+				return a.render(cmd.OutOrStdout(), history, t)
+			})
+		},
+	}
+}
+
+func newListCmd(a *app) *cobra.Command {
+	var q ledger.AssetQuery
+	var from, to string
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List anchored assets by project, operation and anchor date",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var err error
+			if q.From, err = parseDate(from); err != nil {
+				return fmt.Errorf("invalid --from: %w", err)
+			}
+			if q.To, err = parseDate(to); err != nil {
+				return fmt.Errorf("invalid --to: %w", err)
+			}
+
+			return a.withClient(func(ctx context.Context, client *ledger.Client) error {
+				assets, err := client.QueryAssets(ctx, q)
+				if err != nil {
+					return fmt.Errorf("failed to list assets: %w", err)
+				}
+
+				t := table{header: []string{"MCAP ID", "ANCHORED", "PROJECT", "OPERATION", "REVOKED", "HASH"}}
+				for _, asset := range assets {
+					t.add(asset.McapID, asset.Datetime, asset.Project, asset.Operation, strconv.FormatBool(asset.Revocation != nil), asset.Hash)
+				}
+				if assets == nil {
+					assets = []ledger.Asset{}
+				}
+				return a.render(cmd.OutOrStdout(), assets, t)
+			})
+		},
+	}
+	cmd.Flags().StringVar(&q.Project, "project", "", "only assets of this project")
+	cmd.Flags().StringVar(&q.Operation, "operation", "", "only assets of this operation")
+	cmd.Flags().StringVar(&from, "from", "", "only assets anchored at or after this date or RFC3339 time")
+	cmd.Flags().StringVar(&to, "to", "", "only assets anchored before this date or RFC3339 time")
+	return cmd
+}
+
+// parseDate parses an RFC3339 time or a YYYY-MM-DD date in UTC. An empty
+// string is the zero time.
+func parseDate(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.DateOnly, s)
+}
+
+func newRevokeCmd(a *app) *cobra.Command {
+	var reason string
+	cmd := &cobra.Command{
+		Use:   "revoke <mcap-id>",
+		Short: "Revoke an anchored asset (requires an admin identity)",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return a.withClient(func(ctx context.Context, client *ledger.Client) error {
+				txStatus, err := client.RevokeAsset(ctx, args[0], reason)
+				if err != nil {
+					return fmt.Errorf("failed to revoke %s: %w", args[0], err)
+				}
+
+				result := struct {
+					McapID      string `json:"mcapId"`
+					TxID        string `json:"txId"`
+					BlockNumber uint64 `json:"blockNumber"`
+				}{args[0], txStatus.TransactionID, txStatus.BlockNumber}
+				t := table{header: []string{"MCAP ID", "TX ID", "BLOCK"}}
+				t.add(result.McapID, result.TxID, strconv.FormatUint(result.BlockNumber, 10))
+				return a.render(cmd.OutOrStdout(), result, t)
+			})
+		},
+	}
+	cmd.Flags().StringVar(&reason, "reason", "", "why the asset is revoked")
+	cmd.MarkFlagRequired("reason")
+	return cmd
+}
@@ -0,0 +1,232 @@
+package main
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// auditStatuses lists the verify-tree outcomes in report order.
+var auditStatuses = []string{
+	statusMatched,
+	statusMismatched,
+	statusRevoked,
+	statusMissingOnLedger,
+	statusMissingOnDisk,
+	statusUnreadable,
+}
+
+// auditEntry is the outcome for one local file or ledger asset.
+type auditEntry struct {
+	// Path is relative to the tree root for local files and to the ledger
+	// root for assets missing on disk.
+	Path       string `json:"path"`
+	McapID     string `json:"mcapId,omitempty"`
+	Hash       string `json:"hash,omitempty"`
+	Algorithm  string `json:"algorithm,omitempty"`
+	LedgerHash string `json:"ledgerHash,omitempty"`
+	LedgerPath string `json:"ledgerPath,omitempty"`
+	Revoked    bool   `json:"revoked,omitempty"`
+	Status     string `json:"status"`
+	Error      string `json:"error,omitempty"`
+}
+
+// auditReport is the result of verify-tree.
+type auditReport struct {
+	GeneratedAt time.Time      `json:"generatedAt"`
+	Root        string         `json:"root"`
+	LedgerRoot  string         `json:"ledgerRoot"`
+	Channel     string         `json:"channel"`
+	Chaincode   string         `json:"chaincode"`
+	Summary     map[string]int `json:"summary"`
+	Entries     []auditEntry   `json:"entries"`
+}
+
+func (r *auditReport) setEntries(entries []auditEntry) {
+	r.Entries = entries
+	r.Summary = make(map[string]int, len(auditStatuses))
+	for _, status := range auditStatuses {
+		r.Summary[status] = 0
+	}
+	for _, e := range entries {
+		r.Summary[e.Status]++
+	}
+}
+
+// signedReport is the JSON audit report file. The signature covers the
+// compact JSON encoding of Report.
+type signedReport struct {
+	Report    json.RawMessage `json:"report"`
+	Signature reportSignature `json:"signature"`
+}
+
+type reportSignature struct {
+	MSPID       string `json:"mspId"`
+	Certificate string `json:"certificate"`
+	Algorithm   string `json:"algorithm"`
+	Value       []byte `json:"value"`
+}
+
+const signatureAlgorithm = "ECDSA-SHA256"
+
+// reportSigner signs audit reports. *wallet.Identity implements it.
+type reportSigner interface {
+	MspID() string
+	Certificate() *x509.Certificate
+	Sign(digest []byte) ([]byte, error)
+}
+
+// signReport signs report with the client identity and returns the report
+// file contents.
+func signReport(report auditReport, signer reportSigner) ([]byte, error) {
+	body, err := json.Marshal(report)
+	if err != nil {
+		return nil, err
+	}
+	digest := sha256.Sum256(body)
+	signature, err := signer.Sign(digest[:])
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign report: %w", err)
+	}
+
+	return json.MarshalIndent(signedReport{
+		Report: body,
+		Signature: reportSignature{
+			MSPID:       signer.MspID(),
+			Certificate: string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: signer.Certificate().Raw})),
+			Algorithm:   signatureAlgorithm,
+			Value:       signature,
+		},
+	}, "", "  ")
+}
+
+// checkReport verifies the signature of a report file and returns the
+// report with the certificate that signed it. Whether that certificate is
+// trusted is up to the caller.
+func checkReport(data []byte) (*auditReport, *x509.Certificate, error) {
+	var signed signedReport
+	if err := json.Unmarshal(data, &signed); err != nil {
+		return nil, nil, fmt.Errorf("invalid report: %w", err)
+	}
+	if signed.Signature.Algorithm != signatureAlgorithm {
+		return nil, nil, fmt.Errorf("unsupported signature algorithm %q", signed.Signature.Algorithm)
+	}
+
+	block, _ := pem.Decode([]byte(signed.Signature.Certificate))
+	if block == nil {
+		return nil, nil, errors.New("report has no signing certificate")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid signing certificate: %w", err)
+	}
+	public, ok := cert.PublicKey.(*ecdsa.PublicKey)
+	if !ok {
+		return nil, nil, fmt.Errorf("unsupported %T signing key", cert.PublicKey)
+	}
+
+	var body bytes.Buffer
+	if err := json.Compact(&body, signed.Report); err != nil {
+		return nil, nil, fmt.Errorf("invalid report: %w", err)
+	}
+	digest := sha256.Sum256(body.Bytes())
+	if !ecdsa.VerifyASN1(public, digest[:], signed.Signature.Value) {
+		return nil, nil, errors.New("report signature is invalid")
+	}
+
+	var report auditReport
+	if err := json.Unmarshal(signed.Report, &report); err != nil {
+		return nil, nil, fmt.Errorf("invalid report: %w", err)
+	}
+	return &report, cert, nil
+}
+
+func newVerifyReportCmd(a *app) *cobra.Command {
+	return &cobra.Command{
+		Use:   "verify-report <report.json>",
+		Short: "Check the signature of a verify-tree audit report",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			data, err := os.ReadFile(filepath.Clean(args[0]))
+			if err != nil {
+				return err
+			}
+			report, cert, err := checkReport(data)
+			if err != nil {
+				return err
+			}
+
+			fingerprint := sha256.Sum256(cert.Raw)
+			result := struct {
+				Valid       bool           `json:"valid"`
+				Subject     string         `json:"subject"`
+				Fingerprint string         `json:"fingerprint"`
+				GeneratedAt time.Time      `json:"generatedAt"`
+				Summary     map[string]int `json:"summary"`
+			}{true, cert.Subject.String(), hex.EncodeToString(fingerprint[:]), report.GeneratedAt, report.Summary}
+
+			t := table{}
+			t.add("SIGNATURE", "valid")
+			t.add("SIGNED BY", result.Subject)
+			t.add("FINGERPRINT", result.Fingerprint)
+			t.add("GENERATED", report.GeneratedAt.Format(time.RFC3339))
+			t.add("SUMMARY", report.summaryLine())
+			return a.render(cmd.OutOrStdout(), result, t)
+		},
+	}
+}
+
+var reportTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>MCAP audit report {{.GeneratedAt.Format "2006-01-02 15:04:05Z07:00"}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #ccc; padding: 0.3em 0.6em; text-align: left; }
+td.hash { font-family: monospace; font-size: 0.85em; }
+tr.matched td.status { color: #1a7f37; }
+tr.mismatched td.status, tr.revoked td.status, tr.missing-on-ledger td.status,
+tr.missing-on-disk td.status, tr.unreadable td.status { color: #cf222e; font-weight: bold; }
+</style>
+</head>
+<body>
+<h1>MCAP audit report</h1>
+<p>Generated {{.GeneratedAt.Format "2006-01-02 15:04:05Z07:00"}} for <code>{{.Root}}</code>
+against assets anchored from <code>{{.LedgerRoot}}</code> on channel
+<code>{{.Channel}}</code>, chaincode <code>{{.Chaincode}}</code>.</p>
+<p>{{.SummaryLine}}</p>
+<p>This page is a rendering of the signed JSON report, which is the authoritative
+record; check it with <code>mcapctl verify-report</code>.</p>
+<table>
+<tr><th>Path</th><th>Asset ID</th><th>Status</th><th>Local hash</th><th>Ledger hash</th></tr>
+{{- range .Entries}}
+<tr class="{{.Status}}"><td>{{.Path}}</td><td>{{.McapID}}</td><td class="status">{{.Status}}{{if .Error}}: {{.Error}}{{end}}</td>
+<td class="hash">{{.Hash}}</td><td class="hash">{{.LedgerHash}}</td></tr>
+{{- end}}
+</table>
+</body>
+</html>
+`))
+
+// renderHTML writes a human-readable rendering of report.
+func renderHTML(w io.Writer, report auditReport) error {
+	return reportTemplate.Execute(w, struct {
+		auditReport
+		SummaryLine string
+	}{report, report.summaryLine()})
+}
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/Octavian-Anghel/Capstone-Project/hashlib"
+	"github.com/Octavian-Anghel/Capstone-Project/ledger"
+	"github.com/spf13/cobra"
+)
+
+// chunkProofFile is what mcapctl chunks --proof writes: the chunks holding
+// a time slice of a recording, with the proofs tying them to the root.
+type chunkProofFile struct {
+	File   string               `json:"file"`
+	Root   string               `json:"root"`
+	From   time.Time            `json:"from"`
+	To     time.Time            `json:"to"`
+	Proofs []hashlib.ChunkProof `json:"proofs"`
+}
+
+// chunkResult is the outcome of verifying one chunk of a proof file.
+type chunkResult struct {
+	Index  int       `json:"index"`
+	Start  time.Time `json:"start"`
+	End    time.Time `json:"end"`
+	Status string    `json:"status"`
+	Error  string    `json:"error,omitempty"`
+}
+
+func newChunksCmd(a *app) *cobra.Command {
+	var from, to, proofPath string
+	cmd := &cobra.Command{
+		Use:   "chunks <file.mcap>",
+		Short: "Hash a recording per chunk into a Merkle tree, or write proofs for a time slice",
+		Long: "Hash a recording per Chunk record, using the chunk index in its summary\n" +
+			"section, into a Merkle tree whose leaves cover the time range of each\n" +
+			"chunk's messages. With --proof, the chunks overlapping --from and --to are\n" +
+			"written with their proofs, so the slice can be checked against the root\n" +
+			"anchored with the recording using verify-chunks, without the rest of the\n" +
+			"file.",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			start, err := parseDate(from)
+			if err != nil {
+				return fmt.Errorf("invalid --from: %w", err)
+			}
+			end, err := parseDate(to)
+			if err != nil {
+				return fmt.Errorf("invalid --to: %w", err)
+			}
+
+			tree, err := hashlib.HashChunks(cmd.Context(), args[0], nil)
+			if err != nil {
+				return fmt.Errorf("failed to hash chunks of %s: %w", args[0], err)
+			}
+
+			if proofPath == "" {
+				t := table{header: []string{"INDEX", "START", "END", "OFFSET", "LENGTH", "HASH"}}
+				for _, l := range tree.Leaves {
+					t.add(strconv.Itoa(l.Index), formatLogTime(logTime(l.StartTime)), formatLogTime(logTime(l.EndTime)),
+						strconv.FormatUint(l.Offset, 10), strconv.FormatUint(l.Length, 10), l.Hash)
+				}
+				t.add("ROOT", "", "", "", "", tree.Root)
+				return a.render(cmd.OutOrStdout(), tree, t)
+			}
+
+			out := chunkProofFile{File: filepath.Base(args[0]), Root: tree.Root, From: start, To: end}
+			last := uint64(math.MaxUint64)
+			if !end.IsZero() {
+				last = uint64(end.UnixNano())
+			}
+			var first uint64
+			if !start.IsZero() {
+				first = uint64(start.UnixNano())
+			}
+			if out.Proofs, err = tree.ProveRange(first, last); err != nil {
+				return err
+			}
+			if len(out.Proofs) == 0 {
+				return fmt.Errorf("no chunk of %s holds messages in that time range", args[0])
+			}
+			if err := readChunks(args[0], out.Proofs); err != nil {
+				return err
+			}
+			data, err := json.MarshalIndent(out, "", "  ")
+			if err != nil {
+				return err
+			}
+			if err := os.WriteFile(proofPath, append(data, '\n'), 0o644); err != nil {
+				return fmt.Errorf("failed to write proof: %w", err)
+			}
+
+			t := table{header: []string{"INDEX", "START", "END", "LENGTH"}}
+			for _, p := range out.Proofs {
+				t.add(strconv.Itoa(p.Leaf.Index), formatLogTime(logTime(p.Leaf.StartTime)), formatLogTime(logTime(p.Leaf.EndTime)), strconv.FormatUint(p.Leaf.Length, 10))
+			}
+			return a.render(cmd.OutOrStdout(), out.Proofs, t)
+		},
+	}
+	cmd.Flags().StringVar(&from, "from", "", "start of the time slice to prove (RFC 3339 or YYYY-MM-DD)")
+	cmd.Flags().StringVar(&to, "to", "", "end of the time slice to prove (RFC 3339 or YYYY-MM-DD)")
+	cmd.Flags().StringVar(&proofPath, "proof", "", "write proofs for the chunks in the time slice to this file")
+	return cmd
+}
+
+// readChunks fills in the chunk records of proofs from the recording.
+func readChunks(filePath string, proofs []hashlib.ChunkProof) error {
+	file, err := os.Open(filepath.Clean(filePath))
+	if err != nil {
+		return fmt.Errorf("failed to open recording: %w", err)
+	}
+	defer file.Close()
+
+	for i := range proofs {
+		leaf := proofs[i].Leaf
+		proofs[i].Chunk = make([]byte, leaf.Length)
+		if _, err := io.ReadFull(io.NewSectionReader(file, int64(leaf.Offset), int64(leaf.Length)), proofs[i].Chunk); err != nil {
+			return fmt.Errorf("failed to read chunk %d: %w", leaf.Index, err)
+		}
+	}
+	return nil
+}
+
+func newVerifyChunksCmd(a *app) *cobra.Command {
+	return &cobra.Command{
+		Use:   "verify-chunks <mcap-id> <proof.json>",
+		Short: "Check the chunks in a proof file written by chunks --proof against the anchored root",
+		Long: "Check the chunks in a proof file written by chunks --proof against the\n" +
+			"chunk tree root anchored with the asset. The root in the proof file itself\n" +
+			"is not used. The command exits non-zero unless every chunk verifies and the\n" +
+			"asset is not revoked.",
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			data, err := os.ReadFile(filepath.Clean(args[1]))
+			if err != nil {
+				return fmt.Errorf("failed to read proof: %w", err)
+			}
+			var in chunkProofFile
+			if err := json.Unmarshal(data, &in); err != nil {
+				return fmt.Errorf("failed to parse proof %s: %w", args[1], err)
+			}
+
+			var asset *ledger.Asset
+			err = a.withClient(func(ctx context.Context, client *ledger.Client) error {
+				asset, err = client.ReadAsset(ctx, args[0])
+				if err != nil {
+					return fmt.Errorf("failed to read asset %s: %w", args[0], err)
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			if asset.ChunkRoot == "" {
+				return fmt.Errorf("asset %s was anchored without a chunk root", args[0])
+			}
+
+			results := verifyChunks(asset.ChunkRoot, in.Proofs)
+			if r := asset.Revocation; r != nil {
+				for i := range results {
+					results[i].Status, results[i].Error = statusRevoked, r.Reason
+				}
+			}
+			t := table{header: []string{"INDEX", "START", "END", "STATUS"}}
+			failed := len(results) == 0
+			for _, r := range results {
+				status := r.Status
+				if r.Error != "" {
+					status += ": " + r.Error
+					failed = true
+				}
+				t.add(strconv.Itoa(r.Index), formatLogTime(r.Start), formatLogTime(r.End), status)
+			}
+			if err := a.render(cmd.OutOrStdout(), results, t); err != nil {
+				return err
+			}
+			if failed {
+				return errVerifyFailed
+			}
+			return nil
+		},
+	}
+}
+
+// verifyChunks checks every proof against root.
+func verifyChunks(root string, proofs []hashlib.ChunkProof) []chunkResult {
+	results := make([]chunkResult, 0, len(proofs))
+	for _, p := range proofs {
+		r := chunkResult{Index: p.Leaf.Index, Start: logTime(p.Leaf.StartTime), End: logTime(p.Leaf.EndTime), Status: statusMatched}
+		if err := hashlib.VerifyChunkProof(root, p, p.Chunk); err != nil {
+			r.Status, r.Error = statusMismatched, err.Error()
+		}
+		results = append(results, r)
+	}
+	return results
+}
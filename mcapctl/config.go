@@ -0,0 +1,27 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Octavian-Anghel/Capstone-Project/ledger"
+)
+
+// withClient loads the config, connects to the gateway and calls fn with a
+// context bounded by --timeout.
+func (a *app) withClient(fn func(ctx context.Context, client *ledger.Client) error) error {
+	cfg, err := ledger.LoadConfig(a.configPath)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), a.timeout)
+	defer cancel()
+	client, err := ledger.Connect(ctx, cfg)
+	if err != nil {
+		return fmt.Errorf("failed to connect to gateway: %w", err)
+	}
+	defer client.Close()
+
+	return fn(ctx, client)
+}
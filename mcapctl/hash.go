@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/Octavian-Anghel/Capstone-Project/hashlib"
+	"github.com/spf13/cobra"
+)
+
+// fileHash is the digest of one file.
+type fileHash struct {
+	Path      string `json:"path"`
+	Algorithm string `json:"algorithm"`
+	Hash      string `json:"hash"`
+	Size      int64  `json:"size"`
+}
+
+func newHashCmd(a *app) *cobra.Command {
+	var algorithm, pattern string
+	cmd := &cobra.Command{
+		Use:   "hash <file|dir>...",
+		Short: "Hash files, or the files under directories matching --pattern",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			files, err := collectFiles(args, pattern)
+			if err != nil {
+				return err
+			}
+
+			results := make([]fileHash, 0, len(files))
+			t := table{header: []string{"PATH", "ALGORITHM", "SIZE", "HASH"}}
+			for _, file := range files {
+				h, err := hashFile(cmd.Context(), file, algorithm)
+				if err != nil {
+					return err
+				}
+				results = append(results, h)
+				t.add(h.Path, h.Algorithm, strconv.FormatInt(h.Size, 10), h.Hash)
+			}
+			return a.render(cmd.OutOrStdout(), results, t)
+		},
+	}
+	cmd.Flags().StringVarP(&algorithm, "algorithm", "a", hashlib.DefaultAlgorithm, algorithmUsage)
+	cmd.Flags().StringVar(&pattern, "pattern", "*.mcap", "file name pattern for files found in directories")
+	return cmd
+}
+
+// algorithmUsage describes the --algorithm flags.
+var algorithmUsage = "digest algorithm: " + strings.Join(hashlib.Algorithms(), ", ")
+
+// hashFile computes the algorithm digest of a file.
+func hashFile(ctx context.Context, filePath string, algorithm string) (fileHash, error) {
+	h, err := hashlib.Lookup(algorithm)
+	if err != nil {
+		return fileHash{}, err
+	}
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return fileHash{}, fmt.Errorf("failed to open file: %w", err)
+	}
+	digest, err := hashlib.HashFileWith(ctx, h, filePath, nil)
+	if err != nil {
+		return fileHash{}, fmt.Errorf("failed to hash %s: %w", filePath, err)
+	}
+	return fileHash{Path: filePath, Algorithm: h.ID(), Hash: digest, Size: info.Size()}, nil
+}
+
+// collectFiles expands directories in paths to the regular files beneath
+// them whose names match pattern. Files named directly are always kept.
+func collectFiles(paths []string, pattern string) ([]string, error) {
+	if _, err := filepath.Match(pattern, ""); err != nil {
+		return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+	}
+
+	var files []string
+	for _, p := range paths {
+		info, err := os.Stat(p)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			files = append(files, p)
+			continue
+		}
+
+		err = filepath.WalkDir(p, func(walkPath string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !d.Type().IsRegular() {
+				return nil
+			}
+			if ok, _ := filepath.Match(pattern, d.Name()); ok {
+				files = append(files, walkPath)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
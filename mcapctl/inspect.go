@@ -0,0 +1,170 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/Octavian-Anghel/Capstone-Project/mcap"
+	"github.com/spf13/cobra"
+)
+
+// channelSummary describes the messages of one channel in a recording.
+type channelSummary struct {
+	Topic           string    `json:"topic"`
+	MessageEncoding string    `json:"messageEncoding"`
+	Schema          string    `json:"schema,omitempty"`
+	SchemaEncoding  string    `json:"schemaEncoding,omitempty"`
+	Messages        int64     `json:"messages"`
+	Start           time.Time `json:"start"`
+	End             time.Time `json:"end"`
+}
+
+// decodedMessage is one message printed by inspect --topic.
+type decodedMessage struct {
+	Topic    string    `json:"topic"`
+	Sequence uint32    `json:"sequence"`
+	LogTime  time.Time `json:"logTime"`
+	Message  any       `json:"message"`
+}
+
+// errLimit stops reading messages once enough have been printed.
+var errLimit = errors.New("message limit reached")
+
+func newInspectCmd(a *app) *cobra.Command {
+	var topics []string
+	var limit int
+	cmd := &cobra.Command{
+		Use:   "inspect <file.mcap>",
+		Short: "List the channels of a recording, or decode the messages on --topic",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			file, err := os.Open(filepath.Clean(args[0]))
+			if err != nil {
+				return fmt.Errorf("failed to open recording: %w", err)
+			}
+			defer file.Close()
+
+			if len(topics) == 0 {
+				channels, err := summarizeChannels(file)
+				if err != nil {
+					return err
+				}
+				t := table{header: []string{"TOPIC", "MESSAGES", "ENCODING", "SCHEMA", "START", "END"}}
+				for _, c := range channels {
+					t.add(c.Topic, strconv.FormatInt(c.Messages, 10), c.MessageEncoding, c.Schema, formatLogTime(c.Start), formatLogTime(c.End))
+				}
+				return a.render(cmd.OutOrStdout(), channels, t)
+			}
+
+			messages := []decodedMessage{}
+			err = mcap.ReadMessages(file, func(m mcap.Message) error {
+				if !slices.Contains(topics, m.Channel.Topic) {
+					return nil
+				}
+				if limit > 0 && len(messages) == limit {
+					return errLimit
+				}
+				v, err := m.Decode()
+				if err != nil {
+					return err
+				}
+				messages = append(messages, decodedMessage{
+					Topic:    m.Channel.Topic,
+					Sequence: m.Sequence,
+					LogTime:  logTime(m.LogTime),
+					Message:  jsonSafe(v),
+				})
+				return nil
+			})
+			if err != nil && !errors.Is(err, errLimit) {
+				return fmt.Errorf("failed to read %s: %w", args[0], err)
+			}
+
+			t := table{header: []string{"TOPIC", "LOG TIME", "MESSAGE"}}
+			for _, m := range messages {
+				body, err := json.Marshal(m.Message)
+				if err != nil {
+					return err
+				}
+				t.add(m.Topic, formatLogTime(m.LogTime), string(body))
+			}
+			return a.render(cmd.OutOrStdout(), messages, t)
+		},
+	}
+	cmd.Flags().StringSliceVarP(&topics, "topic", "t", nil, "decode and print the messages on these topics")
+	cmd.Flags().IntVarP(&limit, "limit", "n", 10, "print at most this many messages, 0 for all")
+	return cmd
+}
+
+// summarizeChannels counts the messages on each channel of a recording, in
+// the order channels are first used.
+func summarizeChannels(file *os.File) ([]channelSummary, error) {
+	var channels []channelSummary
+	index := map[*mcap.Channel]int{}
+	err := mcap.ReadMessages(file, func(m mcap.Message) error {
+		i, ok := index[m.Channel]
+		if !ok {
+			i = len(channels)
+			index[m.Channel] = i
+			c := channelSummary{Topic: m.Channel.Topic, MessageEncoding: m.Channel.MessageEncoding, Start: logTime(m.LogTime), End: logTime(m.LogTime)}
+			if m.Schema != nil {
+				c.Schema, c.SchemaEncoding = m.Schema.Name, m.Schema.Encoding
+			}
+			channels = append(channels, c)
+		}
+		c := &channels[i]
+		c.Messages++
+		t := logTime(m.LogTime)
+		if t.Before(c.Start) {
+			c.Start = t
+		}
+		if t.After(c.End) {
+			c.End = t
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", file.Name(), err)
+	}
+	return channels, nil
+}
+
+// logTime converts an MCAP timestamp in nanoseconds since the epoch.
+func logTime(ns uint64) time.Time {
+	return time.Unix(0, int64(ns)).UTC()
+}
+
+func formatLogTime(t time.Time) string {
+	return t.Format("2006-01-02T15:04:05.000Z07:00")
+}
+
+// jsonSafe replaces the NaN and infinite floats JSON cannot hold with
+// their names.
+func jsonSafe(v any) any {
+	switch n := v.(type) {
+	case map[string]any:
+		for k, item := range n {
+			n[k] = jsonSafe(item)
+		}
+	case []any:
+		for i, item := range n {
+			n[i] = jsonSafe(item)
+		}
+	case float64:
+		if math.IsNaN(n) || math.IsInf(n, 0) {
+			return strconv.FormatFloat(n, 'g', -1, 64)
+		}
+	case float32:
+		if f := float64(n); math.IsNaN(f) || math.IsInf(f, 0) {
+			return strconv.FormatFloat(f, 'g', -1, 32)
+		}
+	}
+	return v
+}
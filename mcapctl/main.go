@@ -0,0 +1,66 @@
+// Command mcapctl hashes MCAP recordings and anchors, verifies and
+// inspects them on the ledger.
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// app holds the global flags shared by every subcommand.
+type app struct {
+	configPath string
+	output     string
+	timeout    time.Duration
+}
+
+func newRootCmd() *cobra.Command {
+	a := &app{}
+	root := &cobra.Command{
+		Use:           "mcapctl",
+		Short:         "Hash, anchor and verify MCAP recordings on Hyperledger Fabric",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			switch a.output {
+			case outputTable, outputJSON:
+				return nil
+			}
+			return fmt.Errorf("invalid output format %q, expected %s or %s", a.output, outputTable, outputJSON)
+		},
+	}
+
+	flags := root.PersistentFlags()
+	flags.StringVarP(&a.configPath, "config", "c", os.Getenv("MCAPCTL_CONFIG"), "path to a YAML config with a gateway section, such as the daemon's (default $MCAPCTL_CONFIG)")
+	flags.StringVarP(&a.output, "output", "o", outputTable, "output format: table or json")
+	flags.DurationVar(&a.timeout, "timeout", time.Minute, "timeout for ledger calls")
+
+	root.AddCommand(
+		newHashCmd(a),
+		newAnchorCmd(a),
+		newVerifyCmd(a),
+		newVerifyTreeCmd(a),
+		newVerifyReportCmd(a),
+		newShowCmd(a),
+		newHistoryCmd(a),
+		newListCmd(a),
+		newRevokeCmd(a),
+		newInspectCmd(a),
+		newChunksCmd(a),
+		newVerifyChunksCmd(a),
+	)
+	return root
+}
+
+func main() {
+	if err := newRootCmd().Execute(); err != nil {
+		if !errors.Is(err, errVerifyFailed) {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+		}
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Octavian-Anghel/Capstone-Project/mcap"
+)
+
+// run executes mcapctl with args and returns its output.
+func run(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+	var out bytes.Buffer
+	cmd := newRootCmd()
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	cmd.SetArgs(args)
+	err := cmd.Execute()
+	return out.String(), err
+}
+
+// Test hashing a directory with both output formats
+func TestHashCmd(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "day1"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range map[string]string{"run1.mcap": "abc", "day1/run2.mcap": "", "notes.txt": "skip"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	out, err := run(t, "hash", "-o", "json", dir)
+	if err != nil {
+		t.Fatalf("hash failed: %v", err)
+	}
+	var results []fileHash
+	if err := json.Unmarshal([]byte(out), &results); err != nil {
+		t.Fatalf("Invalid JSON output %q: %v", out, err)
+	}
+	want := map[string]string{
+		filepath.Join(dir, "run1.mcap"):      "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad",
+		filepath.Join(dir, "day1/run2.mcap"): "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+	}
+	if len(results) != len(want) {
+		t.Fatalf("Expected %d files, got %+v", len(want), results)
+	}
+	for _, r := range results {
+		if want[r.Path] != r.Hash || r.Algorithm != "sha256" {
+			t.Errorf("Unexpected hash for %s: %+v", r.Path, r)
+		}
+	}
+
+	out, err = run(t, "hash", "--algorithm", "sha512", filepath.Join(dir, "notes.txt"))
+	if err != nil {
+		t.Fatalf("hash failed: %v", err)
+	}
+	if lines := strings.Split(strings.TrimSpace(out), "\n"); len(lines) != 2 || !strings.HasPrefix(lines[0], "PATH") || !strings.Contains(lines[1], "sha512") {
+		t.Errorf("Unexpected table output:\n%s", out)
+	}
+
+	if _, err := run(t, "hash", "--algorithm", "md5", dir); err == nil {
+		t.Errorf("Expected unknown algorithm to fail")
+	}
+	if _, err := run(t, "hash", "-o", "yaml", dir); err == nil {
+		t.Errorf("Expected unknown output format to fail")
+	}
+}
+
+// Test list date parsing
+func TestParseDate(t *testing.T) {
+	if got, err := parseDate("2025-03-01"); err != nil || !got.Equal(time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected date %v, %v", got, err)
+	}
+	if got, err := parseDate("2025-03-01T12:00:00+01:00"); err != nil || !got.Equal(time.Date(2025, 3, 1, 11, 0, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected time %v, %v", got, err)
+	}
+	if _, err := parseDate("yesterday"); err == nil {
+		t.Errorf("Expected invalid date to fail")
+	}
+}
+
+// writeRecording writes an MCAP file with JSON messages on /env and /log.
+func writeRecording(t *testing.T, filePath string) {
+	t.Helper()
+	var out bytes.Buffer
+	le := binary.LittleEndian
+	record := func(op mcap.Opcode, fields ...any) {
+		var body bytes.Buffer
+		for _, f := range fields {
+			if s, ok := f.(string); ok {
+				binary.Write(&body, le, uint32(len(s)))
+				body.WriteString(s)
+				continue
+			}
+			binary.Write(&body, le, f)
+		}
+		out.WriteByte(byte(op))
+		binary.Write(&out, le, uint64(body.Len()))
+		out.Write(body.Bytes())
+	}
+
+	out.Write(mcap.Magic)
+	record(mcap.OpChannel, uint16(1), uint16(0), "/env", "json", uint32(0))
+	record(mcap.OpChannel, uint16(2), uint16(0), "/log", "json", uint32(0))
+	for i, payload := range []string{`{"temperature":20.5}`, `{"temperature":21}`, `{"temperature":22}`} {
+		ns := uint64(1_700_000_000+i) * 1e9
+		record(mcap.OpMessage, uint16(1), uint32(i), ns, ns, []byte(payload))
+	}
+	record(mcap.OpMessage, uint16(2), uint32(0), uint64(1_700_000_001e9), uint64(1_700_000_001e9), []byte(`{"text":"started"}`))
+	out.Write(mcap.Magic)
+
+	if err := os.WriteFile(filePath, out.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// Test listing channels and decoding messages of a recording
+func TestInspectCmd(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "run1.mcap")
+	writeRecording(t, filePath)
+
+	out, err := run(t, "inspect", "-o", "json", filePath)
+	if err != nil {
+		t.Fatalf("inspect failed: %v", err)
+	}
+	var channels []channelSummary
+	if err := json.Unmarshal([]byte(out), &channels); err != nil {
+		t.Fatalf("Invalid JSON output %q: %v", out, err)
+	}
+	if len(channels) != 2 || channels[0].Topic != "/env" || channels[0].Messages != 3 || channels[1].Messages != 1 {
+		t.Fatalf("Unexpected channels %+v", channels)
+	}
+	if got := channels[0].End.Sub(channels[0].Start); got != 2*time.Second {
+		t.Errorf("Expected /env to span 2s, got %s", got)
+	}
+
+	out, err = run(t, "inspect", "--topic", "/env", "--limit", "2", filePath)
+	if err != nil {
+		t.Fatalf("inspect failed: %v", err)
+	}
+	if lines := strings.Split(strings.TrimSpace(out), "\n"); len(lines) != 3 || !strings.Contains(lines[2], `{"temperature":21}`) {
+		t.Errorf("Expected two /env messages:\n%s", out)
+	}
+}
@@ -0,0 +1,418 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Octavian-Anghel/Capstone-Project/hashlib"
+	"github.com/Octavian-Anghel/Capstone-Project/ledger"
+	"github.com/spf13/cobra"
+)
+
+// treeFile is a local recording found by verify-tree.
+type treeFile struct {
+	Path    string
+	RelPath string // relative to the tree root, slash separated
+	Hash    string // hashlib.DefaultAlgorithm digest
+	Err     error
+
+	// Digests holds digests under other algorithms, by identifier, for
+	// files whose assets were anchored with them.
+	Digests map[string]string
+}
+
+// contentAsset returns the asset in byID named by the content ID of one of
+// f's digests under the algorithm the asset was anchored with.
+func (f treeFile) contentAsset(byID map[string]ledger.Asset) (ledger.Asset, bool) {
+	if asset, ok := byID[ledger.ContentID(f.Hash)]; ok {
+		return asset, true
+	}
+	for id, digest := range f.Digests {
+		if asset, ok := byID[ledger.ContentID(digest)]; ok && asset.HashAlgorithm() == id {
+			return asset, true
+		}
+	}
+	return ledger.Asset{}, false
+}
+
+// digest returns the digest of f under algorithm, or "" if it was not
+// computed.
+func (f treeFile) digest(algorithm string) string {
+	if algorithm == hashlib.DefaultAlgorithm {
+		return f.Hash
+	}
+	return f.Digests[algorithm]
+}
+
+func newVerifyTreeCmd(a *app) *cobra.Command {
+	var (
+		q          ledger.AssetQuery
+		ledgerRoot string
+		pattern    string
+		reportPath string
+		workers    int
+		batchSize  int
+	)
+	cmd := &cobra.Command{
+		Use:   "verify-tree <dir>",
+		Short: "Verify every recording under a directory and write a signed audit report",
+		Long: "Verify every recording under a directory against the ledger and write a\n" +
+			"signed audit report as JSON and HTML.\n\n" +
+			"Assets anchored under --ledger-root are matched to local files by their\n" +
+			"path relative to it; other files are looked up by their content asset ID.\n" +
+			"That ID depends on the algorithm a file was anchored with, so files not\n" +
+			"found are hashed again with each other algorithm.\n" +
+			"Assets under --ledger-root with no local file are reported missing on\n" +
+			"disk. The command exits non-zero unless every entry matched.",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			root, err := filepath.Abs(args[0])
+			if err != nil {
+				return err
+			}
+			if ledgerRoot == "" {
+				ledgerRoot = filepath.ToSlash(root)
+			}
+			if workers < 1 || batchSize < 1 {
+				return fmt.Errorf("--workers and --batch must be at least 1")
+			}
+
+			paths, err := collectFiles([]string{root}, pattern)
+			if err != nil {
+				return err
+			}
+			files := hashTree(root, paths, workers)
+
+			cfg, err := ledger.LoadConfig(a.configPath)
+			if err != nil {
+				return err
+			}
+			report := auditReport{
+				GeneratedAt: time.Now().UTC(),
+				Root:        root,
+				LedgerRoot:  ledgerRoot,
+				Channel:     cfg.Channel,
+				Chaincode:   cfg.Chaincode,
+			}
+
+			var signed []byte
+			err = a.withClient(func(ctx context.Context, client *ledger.Client) error {
+				expected, err := client.QueryAssets(ctx, q)
+				if err != nil {
+					return fmt.Errorf("failed to list assets: %w", err)
+				}
+				byID, err := lookupTree(ctx, cmd.Context(), client, files, expected, ledgerRoot, batchSize)
+				if err != nil {
+					return err
+				}
+				rehashTree(cmd.Context(), files, expected, ledgerRoot)
+
+				report.setEntries(compareTree(files, expected, byID, ledgerRoot))
+				signed, err = signReport(report, client.Identity())
+				return err
+			})
+			if err != nil {
+				return err
+			}
+
+			if err := writeReport(reportPath, signed, report); err != nil {
+				return err
+			}
+
+			t := table{header: []string{"PATH", "MCAP ID", "STATUS"}}
+			for _, e := range report.Entries {
+				t.add(e.Path, e.McapID, e.Status)
+			}
+			if err := a.render(cmd.OutOrStdout(), report, t); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.ErrOrStderr(), "%s\nWrote %s.json and %s.html\n", report.summaryLine(), reportPath, reportPath)
+			if report.Summary[statusMatched] != len(report.Entries) {
+				return errVerifyFailed
+			}
+			return nil
+		},
+	}
+	flags := cmd.Flags()
+	flags.StringVar(&q.Project, "project", "", "only expect assets of this project")
+	flags.StringVar(&q.Operation, "operation", "", "only expect assets of this operation")
+	flags.StringVar(&ledgerRoot, "ledger-root", "", "directory the recordings were anchored from, as recorded on the ledger (default: the tree root)")
+	flags.StringVar(&pattern, "pattern", "*.mcap", "file name pattern of recordings")
+	flags.StringVar(&reportPath, "report", "audit-report", "report path without extension; .json and .html are written")
+	flags.IntVar(&workers, "workers", runtime.NumCPU(), "files hashed in parallel")
+	flags.IntVar(&batchSize, "batch", 100, "assets looked up per evaluate call")
+	return cmd
+}
+
+// hashTree hashes paths with workers goroutines. Files that cannot be read
+// are returned with Err set.
+func hashTree(root string, paths []string, workers int) []treeFile {
+	files := make([]treeFile, len(paths))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				f := &files[i]
+				f.Path = paths[i]
+				if rel, err := filepath.Rel(root, paths[i]); err == nil {
+					f.RelPath = filepath.ToSlash(rel)
+				}
+				f.Hash, f.Err = hashlib.HashFile(paths[i])
+			}
+		}()
+	}
+	for i := range paths {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+	return files
+}
+
+// rehashTree hashes files again with the algorithms of the assets anchored
+// from their paths, where those are not the default. Files that cannot be
+// read again are marked with Err.
+func rehashTree(ctx context.Context, files []treeFile, expected []ledger.Asset, ledgerRoot string) {
+	algorithms := make(map[string][]string)
+	for _, asset := range expected {
+		if rel, ok := ledgerRel(asset, ledgerRoot); ok && asset.HashAlgorithm() != hashlib.DefaultAlgorithm {
+			algorithms[rel] = append(algorithms[rel], asset.HashAlgorithm())
+		}
+	}
+
+	for i := range files {
+		f := &files[i]
+		for _, id := range algorithms[f.RelPath] {
+			if f.Err != nil || f.digest(id) != "" {
+				continue
+			}
+			// Assets with an unknown algorithm are left to mismatch
+			h, err := hashlib.Lookup(id)
+			if err != nil {
+				continue
+			}
+			digest, err := hashlib.HashFileWith(ctx, h, f.Path, nil)
+			if err != nil {
+				f.Err = err
+				continue
+			}
+			if f.Digests == nil {
+				f.Digests = make(map[string]string)
+			}
+			f.Digests[id] = digest
+		}
+	}
+}
+
+// ledgerRel returns the path of asset relative to ledgerRoot, or false if
+// it was not anchored from beneath it.
+func ledgerRel(asset ledger.Asset, ledgerRoot string) (string, bool) {
+	rel, ok := strings.CutPrefix(path.Clean(asset.Path), strings.TrimSuffix(path.Clean(ledgerRoot), "/")+"/")
+	return rel, ok && asset.Path != ""
+}
+
+// unmatched returns the files that no asset under ledgerRoot was anchored
+// from and that none of byID names by content ID.
+func unmatched(files []treeFile, expected []ledger.Asset, byID map[string]ledger.Asset, ledgerRoot string) []*treeFile {
+	anchored := make(map[string]bool)
+	for _, asset := range expected {
+		if rel, ok := ledgerRel(asset, ledgerRoot); ok {
+			anchored[rel] = true
+		}
+	}
+
+	var out []*treeFile
+	for i := range files {
+		f := &files[i]
+		if f.Err != nil || anchored[f.RelPath] {
+			continue
+		}
+		if _, ok := f.contentAsset(byID); !ok {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// unmatchedIDs returns the content asset IDs under algorithm of the
+// unmatched files, hashing them with algorithm first where needed. Files
+// that cannot be read again are marked with Err.
+func unmatchedIDs(ctx context.Context, files []treeFile, expected []ledger.Asset, byID map[string]ledger.Asset, ledgerRoot string, algorithm string) []string {
+	var ids []string
+	for _, f := range unmatched(files, expected, byID, ledgerRoot) {
+		if f.digest(algorithm) == "" {
+			h, err := hashlib.Lookup(algorithm)
+			if err != nil {
+				continue
+			}
+			digest, err := hashlib.HashFileWith(ctx, h, f.Path, nil)
+			if err != nil {
+				f.Err = err
+				continue
+			}
+			if f.Digests == nil {
+				f.Digests = make(map[string]string)
+			}
+			f.Digests[algorithm] = digest
+		}
+		ids = append(ids, ledger.ContentID(f.digest(algorithm)))
+	}
+	return ids
+}
+
+// lookupTree reads the assets named by the content IDs of the unmatched
+// files. Content IDs depend on the algorithm a file was anchored with, so
+// files not found under the default algorithm are hashed with hashCtx and
+// looked up again under each other registered algorithm.
+func lookupTree(ctx, hashCtx context.Context, client *ledger.Client, files []treeFile, expected []ledger.Asset, ledgerRoot string, batchSize int) (map[string]ledger.Asset, error) {
+	algorithms := []string{hashlib.DefaultAlgorithm}
+	for _, id := range hashlib.Algorithms() {
+		if id != hashlib.DefaultAlgorithm {
+			algorithms = append(algorithms, id)
+		}
+	}
+
+	byID := make(map[string]ledger.Asset)
+	for _, id := range algorithms {
+		ids := unmatchedIDs(hashCtx, files, expected, byID, ledgerRoot, id)
+		if len(ids) == 0 {
+			break
+		}
+		found, err := lookupAssets(ctx, client, ids, batchSize)
+		if err != nil {
+			return nil, err
+		}
+		maps.Copy(byID, found)
+	}
+	return byID, nil
+}
+
+// lookupAssets reads the given assets in batches and returns those that
+// exist by ID.
+func lookupAssets(ctx context.Context, client *ledger.Client, ids []string, batchSize int) (map[string]ledger.Asset, error) {
+	found := make(map[string]ledger.Asset)
+	for start := 0; start < len(ids); start += batchSize {
+		batch := ids[start:min(start+batchSize, len(ids))]
+		assets, err := client.ReadAssets(ctx, batch)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read assets: %w", err)
+		}
+		for _, asset := range assets {
+			found[asset.McapID] = asset
+		}
+	}
+	return found, nil
+}
+
+// compareTree matches local files with ledger assets. Files are compared
+// with the assets anchored from the same relative path, or else with the
+// asset their content ID names in byID. Assets under ledgerRoot that no
+// file accounts for are missing on disk.
+func compareTree(files []treeFile, expected []ledger.Asset, byID map[string]ledger.Asset, ledgerRoot string) []auditEntry {
+	byPath := make(map[string][]ledger.Asset)
+	for _, asset := range expected {
+		if rel, ok := ledgerRel(asset, ledgerRoot); ok {
+			byPath[rel] = append(byPath[rel], asset)
+		}
+	}
+
+	seen := make(map[string]bool)
+	var entries []auditEntry
+	for _, f := range files {
+		e := auditEntry{Path: f.RelPath, Hash: f.Hash}
+		switch candidates := byPath[f.RelPath]; {
+		case f.Err != nil:
+			e.Status = statusUnreadable
+			e.Error = f.Err.Error()
+		case len(candidates) > 0:
+			e.setAsset(candidates[0], f)
+			for _, asset := range candidates {
+				seen[asset.McapID] = true
+				if asset.Hash == f.digest(asset.HashAlgorithm()) {
+					e.setAsset(asset, f)
+				}
+			}
+		default:
+			asset, ok := f.contentAsset(byID)
+			if !ok {
+				e.McapID = ledger.ContentID(f.Hash)
+				e.Status = statusMissingOnLedger
+				break
+			}
+			seen[asset.McapID] = true
+			e.setAsset(asset, f)
+		}
+		entries = append(entries, e)
+	}
+
+	for _, asset := range expected {
+		rel, ok := ledgerRel(asset, ledgerRoot)
+		if !ok || seen[asset.McapID] {
+			continue
+		}
+		entries = append(entries, auditEntry{
+			McapID:     asset.McapID,
+			LedgerPath: asset.Path,
+			LedgerHash: asset.Hash,
+			Path:       rel,
+			Status:     statusMissingOnDisk,
+			Revoked:    asset.Revocation != nil,
+		})
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Path < entries[j].Path
+	})
+	return entries
+}
+
+func (e *auditEntry) setAsset(asset ledger.Asset, f treeFile) {
+	digest := f.digest(asset.HashAlgorithm())
+	e.Hash = digest
+	e.Algorithm = asset.HashAlgorithm()
+	e.McapID = asset.McapID
+	e.LedgerHash = asset.Hash
+	e.LedgerPath = asset.Path
+	e.Revoked = asset.Revocation != nil
+	e.Status = ledger.VerifyStatus(digest, &asset)
+}
+
+// writeReport writes the signed JSON report and its HTML rendering next
+// to each other.
+func writeReport(reportPath string, signed []byte, report auditReport) error {
+	if err := os.WriteFile(reportPath+".json", signed, 0o644); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+
+	html, err := os.Create(reportPath + ".html")
+	if err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+	defer html.Close()
+	if err := renderHTML(html, report); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+	return html.Close()
+}
+
+// summaryLine formats the report counts for humans.
+func (r auditReport) summaryLine() string {
+	var parts []string
+	for _, status := range auditStatuses {
+		parts = append(parts, strconv.Itoa(r.Summary[status])+" "+status)
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Octavian-Anghel/Capstone-Project/hashlib"
+	"github.com/Octavian-Anghel/Capstone-Project/ledger"
+)
+
+// testSigner signs reports with a throwaway key.
+type testSigner struct {
+	key  *ecdsa.PrivateKey
+	cert *x509.Certificate
+}
+
+func newTestSigner(t *testing.T) *testSigner {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "auditor"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testSigner{key: key, cert: cert}
+}
+
+func (s *testSigner) MspID() string                  { return "Org1MSP" }
+func (s *testSigner) Certificate() *x509.Certificate { return s.cert }
+func (s *testSigner) Sign(digest []byte) ([]byte, error) {
+	return ecdsa.SignASN1(rand.Reader, s.key, digest)
+}
+
+// Test matching local files with anchored assets
+func TestCompareTree(t *testing.T) {
+	files := []treeFile{
+		{RelPath: "a.mcap", Hash: "aa"},
+		{RelPath: "b.mcap", Hash: "bx"},
+		{RelPath: "moved/c.mcap", Hash: "cc"},
+		{RelPath: "d.mcap", Hash: "dd"},
+		{RelPath: "e.mcap", Err: errors.New("permission denied")},
+		{RelPath: "h.mcap", Hash: "hh", Digests: map[string]string{"blake3": "hb"}},
+	}
+	expected := []ledger.Asset{
+		{McapID: "a", Hash: "aa", Path: "/shared/a.mcap"},
+		{McapID: "b", Hash: "bb", Path: "/shared/b.mcap"},
+		{McapID: ledger.ContentID("cc"), Hash: "cc", Path: "/shared/c.mcap"},
+		{McapID: "f", Hash: "ff", Path: "/shared/f.mcap", Revocation: &ledger.Revocation{}},
+		{McapID: "g", Hash: "gg", Path: "/elsewhere/g.mcap"},
+		{McapID: "h", Hash: "hb", Algorithm: "blake3", Path: "/shared/h.mcap"},
+	}
+	if ids := unmatchedIDs(context.Background(), files, expected, nil, "/shared/", hashlib.DefaultAlgorithm); len(ids) != 2 || ids[0] != ledger.ContentID("cc") || ids[1] != ledger.ContentID("dd") {
+		t.Errorf("Unexpected lookups %v", ids)
+	}
+	byID := map[string]ledger.Asset{ledger.ContentID("cc"): expected[2]}
+
+	var report auditReport
+	report.setEntries(compareTree(files, expected, byID, "/shared/"))
+	got := make(map[string]string)
+	for _, e := range report.Entries {
+		got[e.Path] = e.Status
+	}
+	want := map[string]string{
+		"a.mcap":       statusMatched,
+		"b.mcap":       statusMismatched,
+		"moved/c.mcap": statusMatched,
+		"d.mcap":       statusMissingOnLedger,
+		"e.mcap":       statusUnreadable,
+		"f.mcap":       statusMissingOnDisk,
+		"h.mcap":       statusMatched,
+	}
+	if len(got) != len(want) {
+		t.Errorf("Expected %d entries, got %+v", len(want), report.Entries)
+	}
+	for path, status := range want {
+		if got[path] != status {
+			t.Errorf("Expected %s to be %s, got %q", path, status, got[path])
+		}
+	}
+	if report.Summary[statusMatched] != 3 || report.Summary[statusRevoked] != 0 {
+		t.Errorf("Unexpected summary %v", report.Summary)
+	}
+}
+
+// Test that files anchored with another algorithm are found by their
+// content ID under it
+func TestUnmatchedIDs_Algorithm(t *testing.T) {
+	root := t.TempDir()
+	filePath := filepath.Join(root, "a.mcap")
+	if err := os.WriteFile(filePath, []byte("abc"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	files := hashTree(root, []string{filePath}, 1)
+
+	blake3 := "6437b3ac38465133ffb63b75273a8db548c558465d79db03fd359c6cd5bd9d85"
+	ids := unmatchedIDs(context.Background(), files, nil, nil, "/shared", "blake3")
+	if len(ids) != 1 || ids[0] != ledger.ContentID(blake3) || files[0].digest("blake3") != blake3 {
+		t.Fatalf("Expected the BLAKE3 content ID, got %v for %+v", ids, files[0])
+	}
+
+	asset := ledger.Asset{McapID: ids[0], Hash: blake3, Algorithm: "blake3", Path: "/elsewhere/a.mcap"}
+	byID := map[string]ledger.Asset{asset.McapID: asset}
+	if ids := unmatchedIDs(context.Background(), files, nil, byID, "/shared", "sha3-256"); len(ids) != 0 {
+		t.Errorf("Expected no lookups once the file is found, got %v", ids)
+	}
+	entries := compareTree(files, nil, byID, "/shared")
+	if len(entries) != 1 || entries[0].Status != statusMatched || entries[0].Algorithm != "blake3" {
+		t.Errorf("Expected a BLAKE3 match, got %+v", entries)
+	}
+}
+
+// Test parallel hashing of a tree
+func TestHashTree(t *testing.T) {
+	root := t.TempDir()
+	var paths []string
+	for _, name := range []string{"a.mcap", "b.mcap", "c.mcap"} {
+		p := filepath.Join(root, name)
+		if err := os.WriteFile(p, []byte("abc"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, p)
+	}
+	paths = append(paths, filepath.Join(root, "gone.mcap"))
+
+	files := hashTree(root, paths, 2)
+	for _, f := range files[:3] {
+		if f.Err != nil || f.Hash != "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad" {
+			t.Errorf("Unexpected result %+v", f)
+		}
+	}
+	if files[3].RelPath != "gone.mcap" || files[3].Err == nil {
+		t.Errorf("Expected an error for a missing file, got %+v", files[3])
+	}
+
+	rehashTree(context.Background(), files, []ledger.Asset{{Path: "/shared/b.mcap", Algorithm: "blake3"}}, "/shared")
+	if got := files[1].digest("blake3"); got != "6437b3ac38465133ffb63b75273a8db548c558465d79db03fd359c6cd5bd9d85" {
+		t.Errorf("Unexpected BLAKE3 digest %q", got)
+	}
+	if files[0].Digests != nil {
+		t.Errorf("Expected only b.mcap to be hashed again, got %+v", files[0])
+	}
+}
+
+// Test that signed reports verify and edited reports do not
+func TestSignReport(t *testing.T) {
+	report := auditReport{GeneratedAt: time.Now().UTC().Truncate(time.Second), Root: "/archive"}
+	report.setEntries([]auditEntry{{Path: "a.mcap", McapID: "a", Status: statusMatched}})
+
+	signed, err := signReport(report, newTestSigner(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	checked, cert, err := checkReport(signed)
+	if err != nil {
+		t.Fatalf("Expected a valid report: %v", err)
+	}
+	if cert.Subject.CommonName != "auditor" || checked.Entries[0].McapID != "a" || !checked.GeneratedAt.Equal(report.GeneratedAt) {
+		t.Errorf("Unexpected report %+v signed by %s", checked, cert.Subject)
+	}
+
+	tampered := bytes.Replace(signed, []byte(`"status": "matched"`), []byte(`"status": "mismatched"`), 1)
+	if bytes.Equal(tampered, signed) {
+		t.Fatal("Report does not contain the expected entry")
+	}
+	if _, _, err := checkReport(tampered); err == nil {
+		t.Errorf("Expected an edited report to fail verification")
+	}
+
+	var html strings.Builder
+	if err := renderHTML(&html, report); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(html.String(), `<tr class="matched"><td>a.mcap</td>`) || !strings.Contains(html.String(), "1 matched") {
+		t.Errorf("Unexpected HTML report:\n%s", html.String())
+	}
+}
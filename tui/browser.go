@@ -0,0 +1,299 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Octavian-Anghel/Capstone-Project/ledger"
+	"github.com/charmbracelet/bubbles/table"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// pageSize is the number of assets requested per GetAssetsPage call.
+const pageSize = 50
+
+// browser is the asset table of the View Hash option with its filter,
+// sort order and detail pane.
+type browser struct {
+	table       table.Model
+	filter      textinput.Model
+	filtering   bool
+	oldestFirst bool
+
+	assets   []ledger.Asset // every asset loaded so far
+	rows     []ledger.Asset // the assets shown, in table row order
+	bookmark string
+	loaded   bool // the first page has arrived
+	more     bool // the ledger has assets beyond the loaded pages
+
+	// Details of the selected asset, loaded once per asset. A nil entry
+	// means the call is still running.
+	history  map[string]*historyMsg
+	metadata map[string]*metadataMsg
+}
+
+var detailStyle = lipgloss.NewStyle().
+	Border(lipgloss.RoundedBorder()).
+	Padding(0, 1).
+	Width(60)
+
+func newBrowser() browser {
+	filter := textinput.New()
+	filter.Prompt = "Filter: "
+	filter.Placeholder = "asset ID, project or operation"
+
+	return browser{
+		table: table.New(
+			table.WithColumns([]table.Column{
+				{Title: "Asset", Width: 20},
+				{Title: "Anchored", Width: 20},
+				{Title: "Project", Width: 12},
+				{Title: "Operation", Width: 12},
+				{Title: "Status", Width: 8},
+			}),
+			table.WithHeight(10),
+		),
+		filter:   filter,
+		history:  make(map[string]*historyMsg),
+		metadata: make(map[string]*metadataMsg),
+	}
+}
+
+// addPage adds a page of assets, replacing those loaded so far when reset
+// is set.
+func (b *browser) addPage(page *ledger.AssetPage, reset bool) {
+	if reset {
+		b.assets = nil
+		b.history = make(map[string]*historyMsg)
+		b.metadata = make(map[string]*metadataMsg)
+	}
+	b.assets = append(b.assets, page.Assets...)
+	b.bookmark = page.Bookmark
+	b.more = page.Bookmark != ""
+	b.loaded = true
+	b.refresh()
+}
+
+// refresh filters and sorts the loaded assets into the table, keeping the
+// selected asset selected when it is still shown.
+func (b *browser) refresh() {
+	selected, _ := b.selected()
+
+	query := strings.ToLower(strings.TrimSpace(b.filter.Value()))
+	b.rows = b.rows[:0]
+	for _, a := range b.assets {
+		if matches(a, query) {
+			b.rows = append(b.rows, a)
+		}
+	}
+	sort.SliceStable(b.rows, func(i, j int) bool {
+		if b.oldestFirst {
+			return b.rows[i].Datetime < b.rows[j].Datetime
+		}
+		return b.rows[i].Datetime > b.rows[j].Datetime
+	})
+
+	rows := make([]table.Row, len(b.rows))
+	cursor := 0
+	for i, a := range b.rows {
+		status := ""
+		if a.Revocation != nil {
+			status = "revoked"
+		}
+		rows[i] = table.Row{a.McapID, a.Datetime, a.Project, a.Operation, status}
+		if a.McapID == selected.McapID {
+			cursor = i
+		}
+	}
+	b.table.SetRows(rows)
+	b.table.SetCursor(cursor)
+}
+
+// matches reports whether the asset ID, project or operation contains the
+// lower-cased query.
+func matches(a ledger.Asset, query string) bool {
+	if query == "" {
+		return true
+	}
+	for _, field := range []string{a.McapID, a.Project, a.Operation} {
+		if strings.Contains(strings.ToLower(field), query) {
+			return true
+		}
+	}
+	return false
+}
+
+// selected returns the asset under the table cursor.
+func (b browser) selected() (ledger.Asset, bool) {
+	cursor := b.table.Cursor()
+	if cursor < 0 || cursor >= len(b.rows) {
+		return ledger.Asset{}, false
+	}
+	return b.rows[cursor], true
+}
+
+// Update handles keys while the browser has focus. Loading further pages
+// and leaving the browser are handled by the model.
+func (b browser) Update(msg tea.KeyMsg) (browser, tea.Cmd) {
+	if b.filtering {
+		switch msg.String() {
+		case "enter":
+			b.filtering = false
+			b.filter.Blur()
+			return b, nil
+		case "esc":
+			b.filtering = false
+			b.filter.Blur()
+			b.filter.Reset()
+			b.refresh()
+			return b, nil
+		}
+		var cmd tea.Cmd
+		b.filter, cmd = b.filter.Update(msg)
+		b.refresh()
+		return b, cmd
+	}
+
+	switch msg.String() {
+	case "/":
+		b.filtering = true
+		return b, b.filter.Focus()
+	case "s":
+		b.oldestFirst = !b.oldestFirst
+		b.refresh()
+		return b, nil
+	}
+
+	var cmd tea.Cmd
+	b.table, cmd = b.table.Update(msg)
+	return b, cmd
+}
+
+// detailsCmd starts loading the history and MCAP metadata of the selected
+// asset if they have not been requested yet.
+func (b *browser) detailsCmd(client *ledger.Client, timeout time.Duration) tea.Cmd {
+	a, ok := b.selected()
+	if !ok {
+		return nil
+	}
+	var cmds []tea.Cmd
+	if _, ok := b.history[a.McapID]; !ok {
+		b.history[a.McapID] = nil
+		cmds = append(cmds, loadHistory(client, timeout, a.McapID))
+	}
+	if _, ok := b.metadata[a.McapID]; !ok {
+		b.metadata[a.McapID] = nil
+		cmds = append(cmds, readMetadata(a.McapID, a.Path))
+	}
+	return tea.Batch(cmds...)
+}
+
+func (b browser) View(focused bool) string {
+	if !b.loaded {
+		return "Press enter to load assets from the ledger."
+	}
+
+	var header string
+	switch {
+	case b.filtering:
+		header = b.filter.View()
+	case b.filter.Value() != "":
+		header = "Filter: " + b.filter.Value()
+	}
+
+	order := "newest first"
+	if b.oldestFirst {
+		order = "oldest first"
+	}
+	status := fmt.Sprintf("%d of %d loaded assets, %s", len(b.rows), len(b.assets), order)
+	if b.more {
+		status += ", more on the ledger (n to load)"
+	}
+	help := "↑/↓ select  / filter  s sort  n next page  r reload  esc back"
+	if !focused {
+		help = "enter to browse"
+	}
+
+	left := lipgloss.JoinVertical(lipgloss.Left,
+		header,
+		b.table.View(),
+		dimStyle.Render(status),
+		dimStyle.Render(help),
+	)
+	return lipgloss.JoinHorizontal(lipgloss.Top, left, "  ", detailStyle.Render(b.detailView()))
+}
+
+// detailView shows the selected asset with its sensor readings, MCAP
+// metadata and history.
+func (b browser) detailView() string {
+	a, ok := b.selected()
+	if !ok {
+		return "No asset selected."
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Asset: %s\nHash: %s\nAlgorithm: %s\nAnchored: %s\nCaptured: %s\nProject: %s\nOperation: %s\nPath: %s\n",
+		a.McapID, a.Hash, a.HashAlgorithm(), a.Datetime, a.CaptureTime, a.Project, a.Operation, a.Path)
+	if r := a.Revocation; r != nil {
+		sb.WriteString(errorStyle.Render(fmt.Sprintf("Revoked %s by %s: %s", r.RevokedAt, r.RevokedBy, r.Reason)) + "\n")
+	}
+
+	if s := a.Sensors; s != nil {
+		fmt.Fprintf(&sb, "\nSensors (%s)\n", s.Source)
+		for _, r := range s.Readings {
+			unit := ""
+			if r.Unit != "" {
+				unit = " " + r.Unit
+			}
+			fmt.Fprintf(&sb, "  %s: mean %g%s, %g to %g over %d samples\n", r.Name, r.Mean, unit, r.Min, r.Max, r.Count)
+		}
+	}
+
+	sb.WriteString("\nMCAP metadata\n")
+	switch md := b.metadata[a.McapID]; {
+	case md == nil:
+		sb.WriteString(dimStyle.Render("Reading file...") + "\n")
+	case md.err != nil:
+		sb.WriteString(dimStyle.Render("Not available: "+md.err.Error()) + "\n")
+	case len(md.metadata) == 0:
+		sb.WriteString(dimStyle.Render("None") + "\n")
+	default:
+		names := make([]string, 0, len(md.metadata))
+		for name := range md.metadata {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			keys := make([]string, 0, len(md.metadata[name]))
+			for k := range md.metadata[name] {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			fmt.Fprintf(&sb, "%s:\n", name)
+			for _, k := range keys {
+				fmt.Fprintf(&sb, "  %s = %s\n", k, md.metadata[name][k])
+			}
+		}
+	}
+
+	sb.WriteString("\nHistory\n")
+	switch h := b.history[a.McapID]; {
+	case h == nil:
+		sb.WriteString(dimStyle.Render("Loading...") + "\n")
+	case h.err != nil:
+		sb.WriteString(errorStyle.Render("Error: "+h.err.Error()) + "\n")
+	default:
+		if n := len(h.history); n > 0 {
+			fmt.Fprintf(&sb, "Anchored in tx %s\n", h.history[n-1].TxID)
+		}
+		changes := ledger.Changes(h.history)
+		for i, entry := range h.history {
+			fmt.Fprintf(&sb, "%s  %-7s  tx %s\n", entry.Timestamp, changes[i], entry.TxID)
+		}
+	}
+	return strings.TrimSuffix(sb.String(), "\n")
+}
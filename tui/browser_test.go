@@ -0,0 +1,115 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Octavian-Anghel/Capstone-Project/ledger"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func testBrowser() browser {
+	b := newBrowser()
+	b.table.Focus()
+	b.addPage(&ledger.AssetPage{Assets: []ledger.Asset{
+		{McapID: "run1", Datetime: "2025-03-01T12:00:00Z", Project: "line-7", Operation: "weld"},
+		{McapID: "run2", Datetime: "2025-03-03T12:00:00Z", Project: "ARP", Operation: "survey", Sensors: &ledger.SensorSummary{
+			Source:   "messages",
+			Readings: []ledger.SensorReading{{Name: "temperature", Unit: "degC", Count: 3, Min: 20.5, Max: 22.5, Mean: 21.5}},
+		}},
+		{McapID: "run3", Datetime: "2025-03-02T12:00:00Z", Project: "line-7", Operation: "paint"},
+	}}, true)
+	return b
+}
+
+func rowIDs(b browser) []string {
+	ids := make([]string, len(b.rows))
+	for i, a := range b.rows {
+		ids[i] = a.McapID
+	}
+	return ids
+}
+
+func typeKeys(b browser, keys ...string) browser {
+	for _, key := range keys {
+		msg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)}
+		switch key {
+		case "enter":
+			msg = tea.KeyMsg{Type: tea.KeyEnter}
+		case "esc":
+			msg = tea.KeyMsg{Type: tea.KeyEsc}
+		case "down":
+			msg = tea.KeyMsg{Type: tea.KeyDown}
+		}
+		b, _ = b.Update(msg)
+	}
+	return b
+}
+
+// Test sorting by anchor time in both directions
+func TestBrowser_Sort(t *testing.T) {
+	b := testBrowser()
+	if got := strings.Join(rowIDs(b), ","); got != "run2,run3,run1" {
+		t.Errorf("Expected newest first, got %s", got)
+	}
+
+	b = typeKeys(b, "down", "s")
+	if got := strings.Join(rowIDs(b), ","); got != "run1,run3,run2" {
+		t.Errorf("Expected oldest first, got %s", got)
+	}
+	if a, _ := b.selected(); a.McapID != "run3" {
+		t.Errorf("Expected the selection to follow run3, got %s", a.McapID)
+	}
+}
+
+// Test the incremental filter over ID, project and operation
+func TestBrowser_Filter(t *testing.T) {
+	b := typeKeys(testBrowser(), "/", "L", "i", "n", "e")
+	if got := strings.Join(rowIDs(b), ","); got != "run3,run1" {
+		t.Errorf("Expected line-7 assets, got %s", got)
+	}
+
+	b = typeKeys(b, "enter")
+	if b.filtering || b.filter.Value() != "Line" {
+		t.Errorf("Expected enter to keep the filter, got %q", b.filter.Value())
+	}
+
+	b = typeKeys(b, "/", "esc")
+	if len(b.rows) != 3 {
+		t.Errorf("Expected esc to clear the filter, got %d rows", len(b.rows))
+	}
+
+	b = typeKeys(b, "/", "s", "u", "r")
+	if got := strings.Join(rowIDs(b), ","); got != "run2" {
+		t.Errorf("Expected the survey operation, got %s", got)
+	}
+}
+
+// Test the detail pane while and after its details load
+func TestBrowser_Detail(t *testing.T) {
+	b := testBrowser()
+	if cmd := b.detailsCmd(&ledger.Client{}, 0); cmd == nil {
+		t.Fatal("Expected details of run2 to be loaded")
+	}
+	if cmd := b.detailsCmd(&ledger.Client{}, 0); cmd != nil {
+		t.Error("Expected details of run2 to be loaded once")
+	}
+	if view := b.detailView(); !strings.Contains(view, "Loading...") {
+		t.Errorf("Expected history to be loading:\n%s", view)
+	}
+
+	b.history["run2"] = &historyMsg{mcapID: "run2", history: []ledger.HistoryEntry{
+		{TxID: "tx2", Timestamp: "2025-03-04T12:00:00Z", Asset: &ledger.Asset{Revocation: &ledger.Revocation{}}},
+		{TxID: "tx1", Timestamp: "2025-03-03T12:00:00Z", Asset: &ledger.Asset{}},
+	}}
+	b.metadata["run2"] = &metadataMsg{mcapID: "run2", err: errors.New("no such file")}
+
+	view := b.detailView()
+	for _, want := range []string{"Asset: run2", "Anchored in tx tx1", "revoke   tx tx2", "create   tx tx1", "Not available: no such file",
+		"Sensors (messages)", "temperature: mean 21.5 degC, 20.5 to 22.5 over 3 samples"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("Expected %q in the detail pane:\n%s", want, view)
+		}
+	}
+}
@@ -0,0 +1,225 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Octavian-Anghel/Capstone-Project/hashlib"
+	"github.com/Octavian-Anghel/Capstone-Project/ledger"
+	"github.com/Octavian-Anghel/Capstone-Project/mcap"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Messages delivered when ledger calls finish.
+type (
+	connectedMsg struct {
+		client *ledger.Client
+		err    error
+	}
+
+	pageMsg struct {
+		page  *ledger.AssetPage
+		reset bool // the page starts a reload rather than continuing one
+		err   error
+	}
+
+	historyMsg struct {
+		mcapID  string
+		history []ledger.HistoryEntry
+		err     error
+	}
+
+	metadataMsg struct {
+		mcapID   string
+		metadata map[string]map[string]string
+		err      error
+	}
+
+	anchoredMsg struct {
+		asset  ledger.NewAsset
+		status *ledger.TxStatus
+		err    error
+	}
+
+	checkedMsg struct {
+		path         string
+		verification *ledger.Verification
+		err          error
+	}
+
+	// progressMsg reports how far hashing of the index-th picked file is.
+	progressMsg struct {
+		index   int
+		done    int64
+		total   int64
+		elapsed time.Duration
+	}
+
+	jobDoneMsg struct {
+		err error // context.Canceled when the user cancelled the job
+	}
+
+	subscribedMsg struct {
+		events <-chan ledger.Event
+		err    error
+	}
+
+	eventMsg struct {
+		event ledger.Event
+	}
+
+	// feedClosedMsg is sent when the peer ends the event stream.
+	feedClosedMsg struct{}
+
+	resubscribeMsg struct{}
+)
+
+// connect dials the gateway peers and loads the client identity.
+func connect(cfg ledger.Config, timeout time.Duration) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		client, err := ledger.Connect(ctx, cfg)
+		return connectedMsg{client: client, err: err}
+	}
+}
+
+// subscribe starts streaming chaincode events from startBlock, or from the
+// next block if it is zero. The stream lasts as long as the TUI.
+func subscribe(client *ledger.Client, startBlock uint64) tea.Cmd {
+	return func() tea.Msg {
+		events, err := client.Events(context.Background(), startBlock)
+		return subscribedMsg{events: events, err: err}
+	}
+}
+
+// waitForEvent delivers the next chaincode event.
+func waitForEvent(events <-chan ledger.Event) tea.Cmd {
+	return func() tea.Msg {
+		event, ok := <-events
+		if !ok {
+			return feedClosedMsg{}
+		}
+		return eventMsg{event: event}
+	}
+}
+
+// loadPage reads the page of assets after bookmark. An empty bookmark
+// reads the first page.
+func loadPage(client *ledger.Client, timeout time.Duration, bookmark string, reset bool) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		page, err := client.AssetsPage(ctx, pageSize, bookmark)
+		return pageMsg{page: page, reset: reset, err: err}
+	}
+}
+
+// loadHistory reads every transaction that wrote an asset.
+func loadHistory(client *ledger.Client, timeout time.Duration, mcapID string) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		history, err := client.AssetHistory(ctx, mcapID)
+		return historyMsg{mcapID: mcapID, history: history, err: err}
+	}
+}
+
+// readMetadata reads the MCAP Metadata records of an anchored recording,
+// which is only possible where its path is mounted.
+func readMetadata(mcapID string, filePath string) tea.Cmd {
+	return func() tea.Msg {
+		metadata, err := mcap.ReadMetadataFile(filePath)
+		return metadataMsg{mcapID: mcapID, metadata: metadata, err: err}
+	}
+}
+
+// hashJob hashes the picked files one after another and anchors or
+// verifies each of them, sending progress and results on updates. It stops
+// when ctx is cancelled and always finishes with a jobDoneMsg.
+func hashJob(ctx context.Context, client *ledger.Client, timeout time.Duration, anchor bool, files []string, updates chan<- tea.Msg) tea.Cmd {
+	return func() tea.Msg {
+		defer close(updates)
+		for i, filePath := range files {
+			updates <- progressMsg{index: i}
+			started := time.Now()
+			var last time.Time
+			digest, err := hashlib.HashFileContext(ctx, filePath, func(done, total int64) {
+				// Redraw at most ten times a second
+				if now := time.Now(); done == total || now.Sub(last) >= 100*time.Millisecond {
+					last = now
+					updates <- progressMsg{index: i, done: done, total: total, elapsed: now.Sub(started)}
+				}
+			})
+			if ctx.Err() != nil {
+				updates <- jobDoneMsg{err: ctx.Err()}
+				return nil
+			}
+			if anchor {
+				updates <- anchorFile(ctx, client, timeout, filePath, digest, err)
+			} else {
+				updates <- checkFile(ctx, client, timeout, filePath, digest, err)
+			}
+		}
+		updates <- jobDoneMsg{}
+		return nil
+	}
+}
+
+// waitForJob delivers the next message of a running hash job.
+func waitForJob(updates <-chan tea.Msg) tea.Cmd {
+	return func() tea.Msg {
+		msg, ok := <-updates
+		if !ok {
+			return nil
+		}
+		return msg
+	}
+}
+
+// anchorFile submits a hashed recording with CreateAsset under its content
+// asset ID.
+func anchorFile(ctx context.Context, client *ledger.Client, timeout time.Duration, filePath string, digest string, err error) anchoredMsg {
+	if err != nil {
+		return anchoredMsg{asset: ledger.NewAsset{Path: filePath}, err: err}
+	}
+	asset := ledger.NewAsset{
+		Algorithm:   hashlib.DefaultAlgorithm,
+		CaptureTime: time.Now(),
+		Hash:        digest,
+		McapID:      ledger.ContentID(digest),
+		Path:        filePath,
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	status, err := client.CreateAsset(ctx, asset)
+	if err != nil {
+		err = fmt.Errorf("failed to anchor: %w", err)
+	}
+	return anchoredMsg{asset: asset, status: status, err: err}
+}
+
+// checkFile verifies a hashed recording against the ledger, hashing it
+// again when its asset was anchored with another algorithm.
+func checkFile(ctx context.Context, client *ledger.Client, timeout time.Duration, filePath string, digest string, err error) checkedMsg {
+	if err != nil {
+		return checkedMsg{path: filePath, err: err}
+	}
+	verifyCtx, cancel := context.WithTimeout(ctx, timeout)
+	v, err := client.Verify(verifyCtx, "", digest)
+	cancel()
+	if err == nil {
+		err = v.Recheck(hashlib.DefaultAlgorithm, func(algorithm string) (string, error) {
+			h, err := hashlib.Lookup(algorithm)
+			if err != nil {
+				return "", err
+			}
+			return hashlib.HashFileWith(ctx, h, filePath, nil)
+		})
+	}
+	if err != nil {
+		return checkedMsg{path: filePath, err: fmt.Errorf("failed to verify: %w", err)}
+	}
+	return checkedMsg{path: filePath, verification: v}
+}
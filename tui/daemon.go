@@ -0,0 +1,206 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Octavian-Anghel/Capstone-Project/adminapi"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// daemonPollInterval is how often the Daemon tab refreshes while focused.
+const daemonPollInterval = 2 * time.Second
+
+// Messages delivered when admin API calls finish.
+type (
+	daemonStatusMsg struct {
+		status *adminapi.Status
+		files  []adminapi.File
+		err    error
+	}
+
+	daemonActionMsg struct {
+		notice string
+		err    error
+	}
+
+	daemonTickMsg struct{}
+)
+
+// daemonPanel is the Daemon tab, which drives MCAPDaemon through its admin
+// API.
+type daemonPanel struct {
+	client       *adminapi.Client
+	status       *adminapi.Status
+	files        []adminapi.File
+	failuresOnly bool
+	cursor       int
+	polling      bool
+	notice       string // outcome of the last action
+	err          error
+}
+
+func newDaemonPanel(socket string) daemonPanel {
+	return daemonPanel{client: adminapi.NewClient(socket)}
+}
+
+// fetchDaemon reads the daemon status and its recent files or failures.
+func fetchDaemon(client *adminapi.Client, timeout time.Duration, failuresOnly bool) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		status, err := client.Status(ctx)
+		if err != nil {
+			return daemonStatusMsg{err: err}
+		}
+		var files []adminapi.File
+		if failuresOnly {
+			files, err = client.Failures(ctx)
+		} else {
+			files, err = client.Files(ctx)
+		}
+		return daemonStatusMsg{status: status, files: files, err: err}
+	}
+}
+
+// daemonAction runs an admin API call and reports notice when it succeeds.
+func daemonAction(timeout time.Duration, notice string, call func(ctx context.Context) error) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		if err := call(ctx); err != nil {
+			return daemonActionMsg{err: err}
+		}
+		return daemonActionMsg{notice: notice}
+	}
+}
+
+func pollDaemon() tea.Cmd {
+	return tea.Tick(daemonPollInterval, func(time.Time) tea.Msg { return daemonTickMsg{} })
+}
+
+// selected returns the file under the cursor.
+func (p daemonPanel) selected() (adminapi.File, bool) {
+	if p.cursor < 0 || p.cursor >= len(p.files) {
+		return adminapi.File{}, false
+	}
+	return p.files[p.cursor], true
+}
+
+// Update handles keys while the Daemon tab has focus. Leaving the tab is
+// handled by the model.
+func (p daemonPanel) Update(msg tea.KeyMsg, timeout time.Duration) (daemonPanel, tea.Cmd) {
+	switch msg.String() {
+	case "up", "k":
+		if p.cursor > 0 {
+			p.cursor--
+		}
+	case "down", "j":
+		if p.cursor < len(p.files)-1 {
+			p.cursor++
+		}
+	case "r":
+		return p, fetchDaemon(p.client, timeout, p.failuresOnly)
+	case "f":
+		p.failuresOnly = !p.failuresOnly
+		p.cursor = 0
+		return p, fetchDaemon(p.client, timeout, p.failuresOnly)
+	case "p":
+		if p.status == nil {
+			return p, nil
+		}
+		if p.status.Paused {
+			return p, daemonAction(timeout, "Resumed the daemon", p.client.Resume)
+		}
+		return p, daemonAction(timeout, "Paused the daemon; files being processed will finish", p.client.Pause)
+	case "a":
+		file, ok := p.selected()
+		if !ok {
+			return p, nil
+		}
+		return p, daemonAction(timeout, "Queued "+filepath.Base(file.Path)+" again", func(ctx context.Context) error {
+			return p.client.Reanchor(ctx, file.Path)
+		})
+	case "l":
+		client := p.client
+		return p, func() tea.Msg {
+			ctx, cancel := context.WithTimeout(context.Background(), timeout)
+			defer cancel()
+			result, err := client.Reload(ctx)
+			if err != nil {
+				return daemonActionMsg{err: err}
+			}
+			notice := "Reloaded " + strings.Join(result.Applied, ", ")
+			if len(result.RestartRequired) > 0 {
+				notice += "; restart to apply " + strings.Join(result.RestartRequired, ", ")
+			}
+			return daemonActionMsg{notice: notice}
+		}
+	}
+	return p, nil
+}
+
+func (p daemonPanel) View(focused bool) string {
+	var sb strings.Builder
+	switch {
+	case p.err != nil:
+		sb.WriteString(errorStyle.Render("Error: "+p.err.Error()) + "\n")
+	case p.notice != "":
+		sb.WriteString(okStyle.Render(p.notice) + "\n")
+	}
+
+	if s := p.status; s == nil {
+		sb.WriteString(fmt.Sprintf("Daemon socket: %s\n", p.client.Socket()))
+	} else {
+		state := okStyle.Render("running")
+		if s.Paused {
+			state = errorStyle.Render("paused")
+		}
+		fmt.Fprintf(&sb, "Daemon %s, watching %s since %s\n", state, s.WatchDir, s.Started.Format(time.DateTime))
+		fmt.Fprintf(&sb, "Workers %d/%d busy  Queue %d/%d  Backlog %d  Journal %d\n",
+			s.Busy, s.Workers, s.Queued, s.QueueSize, s.Backlog, s.Journal)
+		for _, peer := range s.Peers {
+			active := ""
+			if peer.Active {
+				active = " (active)"
+			}
+			fmt.Fprintf(&sb, "Peer %s: %s%s\n", peer.Name, peer.State, active)
+		}
+		if !s.Reloaded.IsZero() {
+			fmt.Fprintf(&sb, "Config %s reloaded %s\n", s.ConfigPath, s.Reloaded.Format(time.DateTime))
+		}
+	}
+
+	title := "Recent files"
+	if p.failuresOnly {
+		title = "Recent failures"
+	}
+	fmt.Fprintf(&sb, "\n%s\n", title)
+	if len(p.files) == 0 {
+		sb.WriteString(dimStyle.Render("None") + "\n")
+	}
+	for i, f := range p.files {
+		cursor := "  "
+		if focused && i == p.cursor {
+			cursor = "→ "
+		}
+		line := fmt.Sprintf("%s%-8s  %-10s  %-24s  %s", cursor, f.Time.Local().Format(time.TimeOnly), f.Status, prefix(filepath.Base(f.Path), 24), prefix(f.McapID, 16))
+		if f.Error != "" {
+			line += "  " + f.Error
+		}
+		if f.Failed() {
+			line = errorStyle.Render(line)
+		}
+		sb.WriteString(line + "\n")
+	}
+
+	help := "enter to open the daemon controls"
+	if focused {
+		help = "↑/↓ select  p pause/resume  a re-anchor  l reload config  f failures only  r refresh  esc back"
+	}
+	sb.WriteString(dimStyle.Render(help))
+	return sb.String()
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Octavian-Anghel/Capstone-Project/ledger"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// feedSize is the number of entries the event feed keeps.
+const feedSize = 200
+
+// resubscribeDelay is how long the feed waits before resuming a stream the
+// peer ended.
+const resubscribeDelay = 5 * time.Second
+
+// feedEntry is one line of the event feed: a chaincode event or a failed
+// verification by this TUI.
+type feedEntry struct {
+	Time      string
+	Kind      string
+	McapID    string
+	Project   string
+	Hash      string
+	Submitter string
+	Alert     bool
+}
+
+// feed is the live event feed with the alert it last raised.
+type feed struct {
+	entries    []feedEntry
+	height     int // number of entries shown
+	events     <-chan ledger.Event
+	lastBlock  uint64 // block of the last event, to resume from
+	subscribed bool
+	err        error // why the last subscription ended
+	alert      string
+}
+
+var alertStyle = lipgloss.NewStyle().
+	Bold(true).
+	Foreground(lipgloss.Color("15")).
+	Background(lipgloss.Color("9")).
+	Padding(0, 1)
+
+func newFeed() feed {
+	return feed{height: 15}
+}
+
+// resumeBlock is the block to resubscribe from after the stream ended.
+func (f feed) resumeBlock() uint64 {
+	if f.lastBlock == 0 {
+		return 0
+	}
+	return f.lastBlock + 1
+}
+
+func (f *feed) add(e feedEntry) {
+	f.entries = append(f.entries, e)
+	if len(f.entries) > feedSize {
+		f.entries = f.entries[len(f.entries)-feedSize:]
+	}
+}
+
+// addEvent adds a chaincode event and raises an alert for revocations.
+func (f *feed) addEvent(e ledger.Event) {
+	f.lastBlock = e.BlockNumber
+	entry := feedEntry{
+		Time:      e.Asset.Datetime,
+		Kind:      "anchored",
+		McapID:    e.Asset.McapID,
+		Project:   e.Asset.Project,
+		Hash:      e.Asset.Hash,
+		Submitter: ledger.SubmitterName(e.Submitter),
+	}
+	if e.Name == ledger.EventAssetRevoked {
+		entry.Kind = "revoked"
+		entry.Alert = true
+		if r := e.Asset.Revocation; r != nil {
+			entry.Time = r.RevokedAt
+			f.alert = fmt.Sprintf("Asset %s was revoked by %s: %s", e.Asset.McapID, entry.Submitter, r.Reason)
+		}
+	}
+	f.add(entry)
+}
+
+// addCheck adds the result of a Check Hash and raises an alert unless the
+// recording matched its anchored hash.
+func (f *feed) addCheck(c checkedMsg) {
+	if c.err != nil || c.verification.Status == ledger.StatusMatched {
+		return
+	}
+	v := c.verification
+	entry := feedEntry{
+		Time:   time.Now().UTC().Format(time.RFC3339),
+		Kind:   v.Status,
+		McapID: v.McapID,
+		Hash:   v.Hash,
+		Alert:  true,
+	}
+	if v.Asset != nil {
+		entry.Project = v.Asset.Project
+	}
+	f.add(entry)
+	f.alert = fmt.Sprintf("Verification of %s failed: %s", c.path, v.Status)
+}
+
+func (f feed) View() string {
+	var sb strings.Builder
+	switch {
+	case f.subscribed:
+		sb.WriteString(okStyle.Render("Live") + dimStyle.Render(" - new events appear at the bottom") + "\n\n")
+	case f.err != nil:
+		sb.WriteString(errorStyle.Render("Disconnected: "+f.err.Error()) + dimStyle.Render(", reconnecting") + "\n\n")
+	default:
+		sb.WriteString(dimStyle.Render("Not subscribed") + "\n\n")
+	}
+
+	fmt.Fprintf(&sb, "%-20s  %-17s  %-16s  %-12s  %-12s  %s\n", "TIME", "EVENT", "ASSET", "PROJECT", "HASH", "SUBMITTER")
+	if len(f.entries) == 0 {
+		sb.WriteString(dimStyle.Render("Waiting for events..."))
+		return sb.String()
+	}
+	entries := f.entries[max(len(f.entries)-f.height, 0):]
+	for _, e := range entries {
+		line := fmt.Sprintf("%-20s  %-17s  %-16s  %-12s  %-12s  %s",
+			e.Time, e.Kind, prefix(e.McapID, 16), prefix(e.Project, 12), prefix(e.Hash, 12), e.Submitter)
+		if e.Alert {
+			line = errorStyle.Render(line)
+		}
+		sb.WriteString(line + "\n")
+	}
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+// prefix truncates s to n characters for narrow columns.
+func prefix(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n-1] + "…"
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Octavian-Anghel/Capstone-Project/ledger"
+)
+
+// Test the feed columns, its size limit and resuming after the last block
+func TestFeed_Events(t *testing.T) {
+	f := newFeed()
+	for i := range feedSize + 5 {
+		f.addEvent(ledger.Event{
+			Name:        ledger.EventAssetCreated,
+			BlockNumber: uint64(i + 1),
+			Asset:       ledger.Asset{McapID: "run1", Project: "line-7", Hash: "0123456789abcdef", Datetime: "2025-03-01T12:00:00Z"},
+			Submitter:   "x509::CN=recorder-01,OU=client::CN=ca",
+		})
+	}
+	if len(f.entries) != feedSize || f.resumeBlock() != feedSize+6 {
+		t.Errorf("Expected %d entries resuming at block %d, got %d at %d", feedSize, feedSize+6, len(f.entries), f.resumeBlock())
+	}
+	if f.alert != "" {
+		t.Errorf("Expected no alert for anchored assets, got %q", f.alert)
+	}
+
+	view := f.View()
+	for _, want := range []string{"2025-03-01T12:00:00Z", "anchored", "run1", "line-7", "0123456789a…", "recorder-01"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("Expected %q in the feed:\n%s", want, view)
+		}
+	}
+	if lines := strings.Count(view, "\n"); lines != f.height+2 {
+		t.Errorf("Expected %d entries shown, got %d lines", f.height, lines)
+	}
+}
+
+// Test alerts for revocations and failed verifications
+func TestFeed_Alerts(t *testing.T) {
+	f := newFeed()
+	f.addEvent(ledger.Event{
+		Name:      ledger.EventAssetRevoked,
+		Asset:     ledger.Asset{McapID: "run1", Revocation: &ledger.Revocation{Reason: "sensor fault", RevokedAt: "2025-03-02T08:00:00Z"}},
+		Submitter: "x509::CN=Admin@org1.example.com::CN=ca",
+	})
+	if f.alert != "Asset run1 was revoked by Admin@org1.example.com: sensor fault" || !f.entries[0].Alert {
+		t.Errorf("Expected a revocation alert, got %q", f.alert)
+	}
+
+	f = newFeed()
+	f.addCheck(checkedMsg{path: "run1.mcap", verification: &ledger.Verification{Status: ledger.StatusMatched}})
+	if f.alert != "" || len(f.entries) != 0 {
+		t.Errorf("Expected a match to be quiet, got %q", f.alert)
+	}
+	f.addCheck(checkedMsg{path: "run2.mcap", verification: &ledger.Verification{McapID: "run2", Status: ledger.StatusMismatched}})
+	if f.alert != "Verification of run2.mcap failed: mismatched" || len(f.entries) != 1 {
+		t.Errorf("Expected a verification alert, got %q", f.alert)
+	}
+}
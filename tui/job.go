@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/charmbracelet/bubbles/progress"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// job is a running hashJob over the files picked for Add Hash or Check
+// Hash.
+type job struct {
+	files   []string
+	anchor  bool
+	cancel  context.CancelFunc
+	updates chan tea.Msg
+
+	progress progressMsg // the latest progress report
+	bar      progress.Model
+}
+
+func newJob(files []string, anchor bool, cancel context.CancelFunc) *job {
+	return &job{
+		files:   files,
+		anchor:  anchor,
+		cancel:  cancel,
+		updates: make(chan tea.Msg),
+		bar:     progress.New(progress.WithDefaultGradient(), progress.WithWidth(50)),
+	}
+}
+
+func (j *job) View() string {
+	p := j.progress
+	verb := "Hashing"
+	if p.total > 0 && p.done == p.total {
+		verb = "Verifying"
+		if j.anchor {
+			verb = "Anchoring"
+		}
+	}
+
+	var percent float64
+	if p.total > 0 {
+		percent = float64(p.done) / float64(p.total)
+	}
+	stats := fmt.Sprintf("%s of %s", formatBytes(p.done), formatBytes(p.total))
+	if secs := p.elapsed.Seconds(); secs > 0 && p.done > 0 {
+		rate := float64(p.done) / secs
+		eta := time.Duration(float64(p.total-p.done) / rate * float64(time.Second))
+		stats += fmt.Sprintf("  %s/s  ETA %s", formatBytes(int64(rate)), eta.Round(time.Second))
+	}
+
+	return fmt.Sprintf("%s %s (%d of %d)\n%s\n%s\n%s",
+		verb, filepath.Base(j.files[p.index]), p.index+1, len(j.files),
+		j.bar.ViewAs(percent), stats, dimStyle.Render("esc to cancel"))
+}
+
+// formatBytes formats n with a binary unit, such as 1.5 MiB.
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,61 @@
+// Command tui is a terminal front end for browsing, anchoring and checking
+// MCAP recordings on the ledger.
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/Octavian-Anghel/Capstone-Project/ledger"
+	tea "github.com/charmbracelet/bubbletea"
+	"gopkg.in/yaml.v3"
+)
+
+// defaultDaemonSocket is the admin socket in the example daemon config.
+const defaultDaemonSocket = "/run/mcapdaemon/admin.sock"
+
+func main() {
+	configPath := flag.String("config", "", "path to a YAML config with a gateway section, such as the daemon's")
+	daemonSocket := flag.String("daemon-socket", "", "MCAPDaemon admin socket (default: admin.socket from -config, or "+defaultDaemonSocket+")")
+	timeout := flag.Duration("timeout", time.Minute, "timeout for ledger calls")
+	flag.Parse()
+
+	cfg, err := ledger.LoadConfig(*configPath)
+	if err != nil {
+		log.Fatalf("Error loading config: %v", err)
+	}
+
+	if *daemonSocket == "" {
+		*daemonSocket = adminSocket(*configPath)
+	}
+
+	final, err := tea.NewProgram(newModel(cfg, *daemonSocket, *timeout), tea.WithAltScreen()).Run()
+	if err != nil {
+		log.Fatalf("Error running program: %v", err)
+	}
+	if m, ok := final.(model); ok && m.client != nil {
+		m.client.Close()
+	}
+}
+
+// adminSocket returns the admin socket configured in a daemon config file,
+// or the default one.
+func adminSocket(configPath string) string {
+	file := struct {
+		Admin struct {
+			Socket string `yaml:"socket"`
+		} `yaml:"admin"`
+	}{}
+	if configPath != "" {
+		if data, err := os.ReadFile(filepath.Clean(configPath)); err == nil {
+			_ = yaml.Unmarshal(data, &file)
+		}
+	}
+	if file.Admin.Socket == "" {
+		return defaultDaemonSocket
+	}
+	return file.Admin.Socket
+}
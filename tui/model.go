@@ -0,0 +1,484 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Octavian-Anghel/Capstone-Project/ledger"
+	"github.com/charmbracelet/bubbles/filepicker"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+type model struct {
+	cfg     ledger.Config
+	timeout time.Duration
+	client  *ledger.Client
+	subject string // common name of the client identity
+
+	// Panel 1 - Options
+	selectedOption int
+	options        []string
+
+	// Panel 2 - File picker and the state of the running ledger call or
+	// hash job
+	picker  filepicker.Model
+	picking bool
+	picked  []string // files marked in the picker, in the order marked
+	spinner spinner.Model
+	busy    string // what the running call is doing, empty when idle
+	job     *job
+	err     error
+
+	// Panel 3 - Results. While browsing, keys go to the asset browser
+	// instead of the options.
+	browser  browser
+	browsing bool
+	anchored []anchoredMsg // results of the last Add Hash job
+	checked  []checkedMsg  // results of the last Check Hash job
+	feed     feed
+	daemon   daemonPanel
+	// focusDaemon sends keys to the Daemon tab instead of the options.
+	focusDaemon bool
+}
+
+const (
+	viewHash = iota
+	addHash
+	checkHash
+	eventFeed
+	daemonTab
+)
+
+var (
+	errorStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("9"))
+	okStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("10"))
+	dimStyle   = lipgloss.NewStyle().Faint(true)
+)
+
+func newModel(cfg ledger.Config, daemonSocket string, timeout time.Duration) model {
+	picker := filepicker.New()
+	picker.AllowedTypes = []string{".mcap"}
+	picker.AutoHeight = false
+	picker.Height = 10
+	// Leave esc to close the picker
+	picker.KeyMap.Back = key.NewBinding(key.WithKeys("h", "backspace", "left"), key.WithHelp("h", "back"))
+	if wd, err := os.Getwd(); err == nil {
+		picker.CurrentDirectory = wd
+	}
+
+	return model{
+		cfg:     cfg,
+		timeout: timeout,
+		options: []string{"View Hash", "Add Hash", "Check Hash", "Event Feed", "Daemon"},
+		picker:  picker,
+		spinner: spinner.New(spinner.WithSpinner(spinner.Dot)),
+		busy:    "Connecting to the gateway",
+		browser: newBrowser(),
+		feed:    newFeed(),
+		daemon:  newDaemonPanel(daemonSocket),
+	}
+}
+
+func (m model) Init() tea.Cmd {
+	return tea.Batch(m.spinner.Tick, connect(m.cfg, m.timeout))
+}
+
+// run starts a ledger call and shows the spinner until its message
+// arrives.
+func (m model) run(busy string, cmd tea.Cmd) (model, tea.Cmd) {
+	m.busy = busy
+	m.err = nil
+	return m, tea.Batch(m.spinner.Tick, cmd)
+}
+
+func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case spinner.TickMsg:
+		if m.busy == "" {
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.spinner, cmd = m.spinner.Update(msg)
+		return m, cmd
+
+	case connectedMsg:
+		m.busy = ""
+		m.client, m.err = msg.client, msg.err
+		if m.err != nil {
+			return m, nil
+		}
+		id := m.client.Identity()
+		m.subject = id.Certificate().Subject.CommonName + " (" + id.MspID() + ")"
+		m, cmd := m.run("Loading assets", loadPage(m.client, m.timeout, "", true))
+		return m, tea.Batch(cmd, subscribe(m.client, m.feed.resumeBlock()))
+
+	case subscribedMsg:
+		if msg.err != nil {
+			m.feed.subscribed, m.feed.err = false, msg.err
+			return m, tea.Tick(resubscribeDelay, func(time.Time) tea.Msg { return resubscribeMsg{} })
+		}
+		m.feed.subscribed, m.feed.err = true, nil
+		m.feed.events = msg.events
+		return m, waitForEvent(msg.events)
+
+	case eventMsg:
+		m.feed.addEvent(msg.event)
+		return m, waitForEvent(m.feed.events)
+
+	case feedClosedMsg:
+		m.feed.subscribed = false
+		m.feed.err = errors.New("the peer ended the event stream")
+		return m, tea.Tick(resubscribeDelay, func(time.Time) tea.Msg { return resubscribeMsg{} })
+
+	case resubscribeMsg:
+		if m.client != nil {
+			return m, subscribe(m.client, m.feed.resumeBlock())
+		}
+
+	case pageMsg:
+		m.busy, m.err = "", msg.err
+		if msg.err == nil {
+			m.browser.addPage(msg.page, msg.reset)
+			return m, m.browser.detailsCmd(m.client, m.timeout)
+		}
+
+	case historyMsg:
+		// Drop details requested before the assets were reloaded
+		if _, ok := m.browser.history[msg.mcapID]; ok {
+			m.browser.history[msg.mcapID] = &msg
+		}
+
+	case metadataMsg:
+		if _, ok := m.browser.metadata[msg.mcapID]; ok {
+			m.browser.metadata[msg.mcapID] = &msg
+		}
+
+	case daemonStatusMsg:
+		m.daemon.err = msg.err
+		if msg.err == nil {
+			m.daemon.status, m.daemon.files = msg.status, msg.files
+			m.daemon.cursor = min(m.daemon.cursor, max(len(msg.files)-1, 0))
+		}
+
+	case daemonActionMsg:
+		m.daemon.notice, m.daemon.err = msg.notice, msg.err
+		return m, fetchDaemon(m.daemon.client, m.timeout, m.daemon.failuresOnly)
+
+	case daemonTickMsg:
+		if !m.focusDaemon {
+			m.daemon.polling = false
+			return m, nil
+		}
+		return m, tea.Batch(fetchDaemon(m.daemon.client, m.timeout, m.daemon.failuresOnly), pollDaemon())
+
+	case tea.WindowSizeMsg:
+		// Leave room for the options, the status panel and the table header
+		m.browser.table.SetHeight(max(msg.Height-20, 5))
+		m.picker.SetHeight(max(msg.Height-20, 5))
+		m.feed.height = max(msg.Height-22, 5)
+
+	case progressMsg:
+		if m.job != nil {
+			m.job.progress = msg
+			return m, waitForJob(m.job.updates)
+		}
+
+	case anchoredMsg:
+		if m.job != nil {
+			m.anchored = append(m.anchored, msg)
+			return m, waitForJob(m.job.updates)
+		}
+
+	case checkedMsg:
+		if m.job != nil {
+			m.checked = append(m.checked, msg)
+			m.feed.addCheck(msg)
+			return m, waitForJob(m.job.updates)
+		}
+
+	case jobDoneMsg:
+		m.busy, m.job = "", nil
+		if errors.Is(msg.err, context.Canceled) {
+			m.err = errors.New("hashing cancelled")
+		}
+
+	case tea.KeyMsg:
+		if msg.String() == "ctrl+c" {
+			if m.job != nil {
+				m.job.cancel()
+			}
+			return m, tea.Quit
+		}
+		if m.job != nil {
+			// The job finishes with a jobDoneMsg once it notices
+			if msg.String() == "esc" {
+				m.job.cancel()
+			}
+			return m, nil
+		}
+		if m.picking {
+			return m.updatePicker(msg)
+		}
+		if m.browsing {
+			return m.updateBrowser(msg)
+		}
+		if m.focusDaemon {
+			if msg.String() == "esc" {
+				m.focusDaemon = false
+				return m, nil
+			}
+			var cmd tea.Cmd
+			m.daemon, cmd = m.daemon.Update(msg, m.timeout)
+			return m, cmd
+		}
+
+		switch msg.String() {
+		case "up":
+			if m.selectedOption > 0 {
+				m.selectedOption--
+			}
+		case "down":
+			if m.selectedOption < len(m.options)-1 {
+				m.selectedOption++
+			}
+		case "enter":
+			// The daemon is reached without the gateway
+			if m.selectedOption == daemonTab {
+				m.focusDaemon = true
+				cmds := []tea.Cmd{fetchDaemon(m.daemon.client, m.timeout, m.daemon.failuresOnly)}
+				if !m.daemon.polling {
+					m.daemon.polling = true
+					cmds = append(cmds, pollDaemon())
+				}
+				return m, tea.Batch(cmds...)
+			}
+			// Run one ledger call at a time
+			if m.busy != "" {
+				return m, nil
+			}
+			if m.client == nil {
+				return m.run("Connecting to the gateway", connect(m.cfg, m.timeout))
+			}
+			switch m.selectedOption {
+			case viewHash:
+				m.browsing = true
+				m.browser.table.Focus()
+				if !m.browser.loaded {
+					return m.run("Loading assets", loadPage(m.client, m.timeout, "", true))
+				}
+			case addHash, checkHash:
+				m.picking = true
+				m.picked = nil
+				return m, m.picker.Init()
+			}
+		case "x":
+			m.feed.alert = ""
+		case "esc", "q":
+			return m, tea.Quit
+		}
+
+	default:
+		// The picker reads directories in the background
+		if m.picking {
+			var cmd tea.Cmd
+			m.picker, cmd = m.picker.Update(msg)
+			return m, cmd
+		}
+	}
+	return m, nil
+}
+
+// updatePicker handles keys while the user is picking files to hash.
+func (m model) updatePicker(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.picking = false
+		return m, nil
+	case "s":
+		if len(m.picked) == 0 {
+			return m, nil
+		}
+		m.picking = false
+		return m.startJob()
+	}
+
+	var cmd tea.Cmd
+	m.picker, cmd = m.picker.Update(msg)
+	if ok, filePath := m.picker.DidSelectFile(msg); ok {
+		if i := slices.Index(m.picked, filePath); i >= 0 {
+			m.picked = slices.Delete(m.picked, i, i+1)
+		} else {
+			m.picked = append(m.picked, filePath)
+		}
+	}
+	return m, cmd
+}
+
+// startJob hashes the picked files and anchors or verifies them.
+func (m model) startJob() (tea.Model, tea.Cmd) {
+	anchor := m.selectedOption == addHash
+	if anchor {
+		m.anchored = nil
+	} else {
+		m.checked = nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	m.job = newJob(m.picked, anchor, cancel)
+	m.busy = fmt.Sprintf("Hashing %d files", len(m.picked))
+	m.err = nil
+	return m, tea.Batch(
+		hashJob(ctx, m.client, m.timeout, anchor, m.picked, m.job.updates),
+		waitForJob(m.job.updates),
+	)
+}
+
+// updateBrowser handles keys while the asset browser has focus.
+func (m model) updateBrowser(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if !m.browser.filtering {
+		switch msg.String() {
+		case "esc":
+			m.browsing = false
+			m.browser.table.Blur()
+			return m, nil
+		case "n":
+			if m.busy != "" || !m.browser.more {
+				return m, nil
+			}
+			return m.run("Loading more assets", loadPage(m.client, m.timeout, m.browser.bookmark, false))
+		case "r":
+			if m.busy != "" {
+				return m, nil
+			}
+			return m.run("Reloading assets", loadPage(m.client, m.timeout, "", true))
+		}
+	}
+
+	var cmd tea.Cmd
+	m.browser, cmd = m.browser.Update(msg)
+	return m, tea.Batch(cmd, m.browser.detailsCmd(m.client, m.timeout))
+}
+
+func (m model) View() string {
+	// Panel 1 - Options (with the current selection)
+	panel1Style := lipgloss.NewStyle().Padding(1)
+	panel1Content := "Select an option:\n"
+	for i, option := range m.options {
+		if i == m.selectedOption {
+			panel1Content += "  → " + option + "\n"
+		} else {
+			panel1Content += "    " + option + "\n"
+		}
+	}
+
+	// Panel 2 - User input, progress of the running call or its error
+	panel2Style := lipgloss.NewStyle().Padding(1)
+	var panel2Content string
+	switch {
+	case m.picking:
+		panel2Content = m.picker.View() + "\n" + m.pickedView() + "\n" +
+			dimStyle.Render("enter to mark or unmark a file, s to start, esc to cancel")
+	case m.job != nil:
+		panel2Content = m.job.View()
+	case m.busy != "":
+		panel2Content = m.spinner.View() + " " + m.busy + "..."
+	case m.err != nil:
+		panel2Content = errorStyle.Render("Error: "+m.err.Error()) + "\n" + dimStyle.Render("enter to retry")
+	case m.client == nil:
+		panel2Content = "Not connected."
+	default:
+		panel2Content = fmt.Sprintf("Connected to %s as %s.", m.cfg.Channel, m.subject)
+	}
+
+	// Panel 3 - Results of the selected option
+	panel3Style := lipgloss.NewStyle().Padding(1)
+	var panel3Content string
+	switch m.selectedOption {
+	case viewHash:
+		panel3Content = m.browser.View(m.browsing)
+	case addHash:
+		panel3Content = m.anchoredView()
+	case checkHash:
+		panel3Content = m.checkedView()
+	case eventFeed:
+		panel3Content = m.feed.View()
+	case daemonTab:
+		panel3Content = m.daemon.View(m.focusDaemon)
+	}
+
+	// Combine the panels into a single view
+	layout := lipgloss.NewStyle().
+		Align(lipgloss.Center).
+		Render(fmt.Sprintf(
+			"%s\n\n%s\n\n%s",
+			panel1Style.Render(panel1Content),
+			panel2Style.Render(panel2Content),
+			panel3Style.Render(panel3Content),
+		))
+
+	// Alerts stay on top of every view until dismissed
+	if m.feed.alert != "" {
+		layout = alertStyle.Render("⚠ "+m.feed.alert) + dimStyle.Render("  x to dismiss") + "\n" + layout
+	}
+	return layout
+}
+
+func (m model) pickedView() string {
+	if len(m.picked) == 0 {
+		return "No files marked."
+	}
+	names := make([]string, len(m.picked))
+	for i, filePath := range m.picked {
+		names[i] = filepath.Base(filePath)
+	}
+	return "Marked: " + strings.Join(names, ", ")
+}
+
+func (m model) anchoredView() string {
+	if len(m.anchored) == 0 {
+		return "Press enter to pick files to hash and anchor on the ledger."
+	}
+	var sb strings.Builder
+	for _, a := range m.anchored {
+		if a.err != nil {
+			fmt.Fprintf(&sb, "%s %s\n%s\n\n", errorStyle.Render("Failed"), a.asset.Path, a.err)
+			continue
+		}
+		fmt.Fprintf(&sb, "%s %s\nAsset: %s\nHash: %s\nTransaction: %s (block %d)\n\n",
+			okStyle.Render("Anchored"), a.asset.Path, a.asset.McapID, a.asset.Hash, a.status.TransactionID, a.status.BlockNumber)
+	}
+	return strings.TrimSuffix(sb.String(), "\n\n")
+}
+
+func (m model) checkedView() string {
+	if len(m.checked) == 0 {
+		return "Press enter to pick files to hash and check against the ledger."
+	}
+	var sb strings.Builder
+	for _, c := range m.checked {
+		if c.err != nil {
+			fmt.Fprintf(&sb, "%s %s\n%s\n\n", errorStyle.Render("Failed"), c.path, c.err)
+			continue
+		}
+		v := c.verification
+		status := errorStyle.Render(v.Status)
+		if v.Status == ledger.StatusMatched {
+			status = okStyle.Render(v.Status)
+		}
+		fmt.Fprintf(&sb, "%s %s\nHash: %s\n", status, c.path, v.Hash)
+		if v.Asset != nil {
+			fmt.Fprintf(&sb, "Ledger hash: %s\nAnchored: %s\n", v.Asset.Hash, v.Asset.Datetime)
+		}
+		sb.WriteString("\n")
+	}
+	return strings.TrimSuffix(sb.String(), "\n\n")
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Octavian-Anghel/Capstone-Project/adminapi"
+	"github.com/Octavian-Anghel/Capstone-Project/ledger"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func press(t *testing.T, m model, keys ...string) (model, tea.Cmd) {
+	t.Helper()
+	var cmd tea.Cmd
+	for _, key := range keys {
+		var msg tea.KeyMsg
+		switch key {
+		case "enter":
+			msg = tea.KeyMsg{Type: tea.KeyEnter}
+		case "down":
+			msg = tea.KeyMsg{Type: tea.KeyDown}
+		case "esc":
+			msg = tea.KeyMsg{Type: tea.KeyEsc}
+		default:
+			msg = tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)}
+		}
+		var next tea.Model
+		next, cmd = m.Update(msg)
+		m = next.(model)
+	}
+	return m, cmd
+}
+
+func update(m model, msg tea.Msg) model {
+	next, _ := m.Update(msg)
+	return next.(model)
+}
+
+// Test the error state and retry after a failed connection
+func TestModel_ConnectError(t *testing.T) {
+	m := update(newModel(ledger.DefaultConfig(), "", time.Second), connectedMsg{err: errors.New("no peers reachable")})
+	if m.busy != "" || !strings.Contains(m.View(), "Error: no peers reachable") {
+		t.Fatalf("Expected the connection error to be shown:\n%s", m.View())
+	}
+
+	m, cmd := press(t, m, "enter")
+	if cmd == nil || m.busy != "Connecting to the gateway" || m.err != nil {
+		t.Errorf("Expected enter to reconnect, busy %q err %v", m.busy, m.err)
+	}
+}
+
+// Test picking files to anchor, the progress display and cancelling the
+// hash job
+func TestModel_AddHash(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"notes.txt", "run1.mcap", "run2.mcap"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(name), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	m := newModel(ledger.DefaultConfig(), "", time.Second)
+	m.busy = ""
+	m.client = &ledger.Client{}
+	m.picker.CurrentDirectory = dir
+
+	m, cmd := press(t, m, "down", "enter")
+	if !m.picking || cmd == nil {
+		t.Fatal("Expected Add Hash to open the file picker")
+	}
+	m = update(m, cmd())
+
+	m, _ = press(t, m, "enter", "down", "enter", "down", "enter")
+	if len(m.picked) != 2 || filepath.Base(m.picked[0]) != "run1.mcap" {
+		t.Fatalf("Expected the two recordings to be marked, got %v", m.picked)
+	}
+	m, cmd = press(t, m, "s")
+	if cmd == nil || m.job == nil || m.picking || m.busy != "Hashing 2 files" {
+		t.Fatalf("Expected hashing to start, busy %q", m.busy)
+	}
+
+	m = update(m, progressMsg{index: 1, done: 512, total: 2048, elapsed: time.Second})
+	for _, want := range []string{"Hashing run2.mcap (2 of 2)", "512 B of 2.0 KiB", "512 B/s", "ETA 3s"} {
+		if !strings.Contains(m.View(), want) {
+			t.Errorf("Expected %q in the progress panel:\n%s", want, m.View())
+		}
+	}
+
+	// Cancel before the job runs so it never reaches the ledger
+	m, _ = press(t, m, "esc")
+	batch := cmd().(tea.BatchMsg)
+	go batch[0]()
+	for cmd = batch[1]; cmd != nil; {
+		msg := cmd()
+		if msg == nil {
+			break
+		}
+		var next tea.Model
+		next, cmd = m.Update(msg)
+		m = next.(model)
+	}
+	if m.job != nil || m.busy != "" || m.err == nil || len(m.anchored) != 0 {
+		t.Errorf("Expected the job to stop without anchoring, err %v", m.err)
+	}
+}
+
+// Test that the first page of assets is listed and more pages are loaded
+// on request
+func TestModel_ViewHash(t *testing.T) {
+	m := newModel(ledger.DefaultConfig(), "", time.Second)
+	m.busy = ""
+	m.client = &ledger.Client{}
+
+	m, cmd := press(t, m, "enter")
+	if cmd == nil || !m.browsing || m.busy != "Loading assets" {
+		t.Fatalf("Expected View Hash to load assets, busy %q", m.busy)
+	}
+	m = update(m, pageMsg{
+		page:  &ledger.AssetPage{Assets: []ledger.Asset{{McapID: "run1", Datetime: "2025-03-01T12:00:00Z", Project: "line-7"}}, Bookmark: "run1"},
+		reset: true,
+	})
+	if view := m.View(); !strings.Contains(view, "run1") || !strings.Contains(view, "line-7") {
+		t.Errorf("Expected the asset to be listed:\n%s", view)
+	}
+
+	m, cmd = press(t, m, "n")
+	if cmd == nil || m.busy != "Loading more assets" {
+		t.Fatalf("Expected n to load the next page, busy %q", m.busy)
+	}
+	m = update(m, pageMsg{page: &ledger.AssetPage{Assets: []ledger.Asset{{McapID: "run2", Datetime: "2025-03-02T12:00:00Z"}}}})
+	if len(m.browser.assets) != 2 || m.browser.more {
+		t.Errorf("Expected two assets and no more pages, got %d, more %v", len(m.browser.assets), m.browser.more)
+	}
+
+	m, _ = press(t, m, "esc", "down")
+	if m.browsing || m.selectedOption != addHash {
+		t.Errorf("Expected esc to return to the options")
+	}
+}
+
+// Test that alerts from the event feed show on every view until dismissed
+func TestModel_EventAlert(t *testing.T) {
+	m := newModel(ledger.DefaultConfig(), "", time.Second)
+	m.busy = ""
+	m = update(m, subscribedMsg{events: make(chan ledger.Event)})
+	m = update(m, eventMsg{event: ledger.Event{
+		Name:  ledger.EventAssetRevoked,
+		Asset: ledger.Asset{McapID: "run1", Revocation: &ledger.Revocation{Reason: "sensor fault"}},
+	}})
+	if !strings.Contains(m.View(), "Asset run1 was revoked") {
+		t.Fatalf("Expected a revocation alert:\n%s", m.View())
+	}
+
+	m, _ = press(t, m, "down", "down", "down")
+	if m.selectedOption != eventFeed || !strings.Contains(m.View(), "revoked") {
+		t.Errorf("Expected the revocation in the feed:\n%s", m.View())
+	}
+	m, _ = press(t, m, "x")
+	if strings.Contains(m.View(), "was revoked") {
+		t.Errorf("Expected x to dismiss the alert")
+	}
+}
+
+// Test the Daemon tab without a gateway connection
+func TestModel_Daemon(t *testing.T) {
+	m := newModel(ledger.DefaultConfig(), "/run/mcapdaemon/admin.sock", time.Second)
+	m.busy = ""
+
+	m, cmd := press(t, m, "down", "down", "down", "down", "enter")
+	if !m.focusDaemon || !m.daemon.polling || cmd == nil {
+		t.Fatal("Expected enter to open the daemon controls and start polling")
+	}
+
+	m = update(m, daemonStatusMsg{
+		status: &adminapi.Status{Workers: 2, Busy: 1, Queued: 3, QueueSize: 1024, WatchDir: "/shared"},
+		files: []adminapi.File{
+			{Path: "/shared/run2.mcap", Status: adminapi.FileAnchored, McapID: "abc"},
+			{Path: "/shared/run1.mcap", Status: adminapi.FileRejected, Error: "operation is required"},
+		},
+	})
+	view := m.View()
+	for _, want := range []string{"Daemon running, watching /shared", "Workers 1/2 busy  Queue 3/1024", "run2.mcap", "operation is required"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("Expected %q in the Daemon tab:\n%s", want, view)
+		}
+	}
+
+	m, _ = press(t, m, "down")
+	if f, _ := m.daemon.selected(); f.Path != "/shared/run1.mcap" {
+		t.Errorf("Expected down to select run1.mcap, got %s", f.Path)
+	}
+	if _, cmd := press(t, m, "a"); cmd == nil {
+		t.Error("Expected a to re-anchor the selected file")
+	}
+
+	m = update(m, daemonActionMsg{err: errors.New("daemon returned 400 Bad Request")})
+	if !strings.Contains(m.View(), "Error: daemon returned 400") {
+		t.Errorf("Expected the failed action to be shown:\n%s", m.View())
+	}
+
+	m, _ = press(t, m, "esc")
+	if m.focusDaemon {
+		t.Error("Expected esc to leave the daemon controls")
+	}
+	if m = update(m, daemonTickMsg{}); m.daemon.polling {
+		t.Error("Expected polling to stop once the tab lost focus")
+	}
+}
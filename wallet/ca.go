@@ -0,0 +1,285 @@
+package wallet
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/fabric-gateway/pkg/identity"
+)
+
+// CAClient talks to the REST API of a Fabric CA server.
+type CAClient struct {
+	url    string
+	caName string
+	http   *http.Client
+}
+
+// NewCAClient returns a client for the CA at url. tlsCertPath, if set,
+// is the CA certificate used to verify an https server.
+func NewCAClient(url, caName, tlsCertPath string) (*CAClient, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if tlsCertPath != "" {
+		certificatePEM, err := os.ReadFile(tlsCertPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA TLS certificate: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(certificatePEM) {
+			return nil, fmt.Errorf("no certificates in %s", tlsCertPath)
+		}
+		transport.TLSClientConfig = &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}
+	}
+	return &CAClient{
+		url:    strings.TrimSuffix(url, "/"),
+		caName: caName,
+		http:   &http.Client{Transport: transport, Timeout: 30 * time.Second},
+	}, nil
+}
+
+type enrollRequest struct {
+	CertificateRequest string `json:"certificate_request"`
+	CAName             string `json:"caname,omitempty"`
+}
+
+type caResponse struct {
+	Success bool            `json:"success"`
+	Result  json.RawMessage `json:"result"`
+	Errors  []caMessage     `json:"errors"`
+}
+
+type caMessage struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type enrollResult struct {
+	// Cert is the base64-encoded PEM certificate.
+	Cert string `json:"Cert"`
+}
+
+// Enroll exchanges an enrollment ID and secret for a certificate over a
+// newly generated key.
+func (c *CAClient) Enroll(ctx context.Context, enrollmentID, secret string) (*x509.Certificate, crypto.PrivateKey, error) {
+	return c.request(ctx, "/api/v1/enroll", enrollmentID, func(req *http.Request, _ []byte) error {
+		req.SetBasicAuth(enrollmentID, secret)
+		return nil
+	})
+}
+
+// Reenroll renews the certificate of creds under a newly generated key,
+// authenticating with the current certificate. It must be called before
+// that certificate expires.
+func (c *CAClient) Reenroll(ctx context.Context, creds *Credentials) (*x509.Certificate, crypto.PrivateKey, error) {
+	return c.request(ctx, "/api/v1/reenroll", creds.Certificate.Subject.CommonName, func(req *http.Request, body []byte) error {
+		token, err := authToken(creds, req.Method, req.URL.RequestURI(), body)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Authorization", token)
+		return nil
+	})
+}
+
+// request sends a certificate signing request for a new key to endpoint.
+func (c *CAClient) request(ctx context.Context, endpoint, commonName string, authorize func(*http.Request, []byte) error) (*x509.Certificate, crypto.PrivateKey, error) {
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	csr, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		Subject:            pkix.Name{CommonName: commonName},
+		SignatureAlgorithm: x509.ECDSAWithSHA256,
+	}, privateKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create certificate request: %w", err)
+	}
+
+	body, err := json.Marshal(enrollRequest{
+		CertificateRequest: string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: csr})),
+		CAName:             c.caName,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url+endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if err := authorize(req, body); err != nil {
+		return nil, nil, err
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to reach CA: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var result caResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, nil, fmt.Errorf("CA returned %s: %w", resp.Status, err)
+	}
+	if !result.Success {
+		var msgs []string
+		for _, m := range result.Errors {
+			msgs = append(msgs, fmt.Sprintf("%s (code %d)", m.Message, m.Code))
+		}
+		return nil, nil, fmt.Errorf("CA returned %s: %s", resp.Status, strings.Join(msgs, "; "))
+	}
+
+	var enrolled enrollResult
+	if err := json.Unmarshal(result.Result, &enrolled); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse CA response: %w", err)
+	}
+	certificatePEM, err := base64.StdEncoding.DecodeString(enrolled.Cert)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to decode enrolled certificate: %w", err)
+	}
+	certificate, err := identity.CertificateFromPEM(certificatePEM)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse enrolled certificate: %w", err)
+	}
+	return certificate, privateKey, nil
+}
+
+// authToken builds the Fabric CA token authorizing a request: the
+// certificate and a signature over the method, URI, body and certificate.
+func authToken(creds *Credentials, method, uri string, body []byte) (string, error) {
+	certificatePEM, err := identity.CertificateToPEM(creds.Certificate)
+	if err != nil {
+		return "", err
+	}
+	b64 := base64.StdEncoding.EncodeToString
+	b64cert := b64(certificatePEM)
+	payload := method + "." + b64([]byte(uri)) + "." + b64(body) + "." + b64cert
+
+	digest := sha256.Sum256([]byte(payload))
+	signature, err := creds.Sign(digest[:])
+	if err != nil {
+		return "", fmt.Errorf("failed to sign CA request: %w", err)
+	}
+	return b64cert + "." + b64(signature), nil
+}
+
+// caProvider keeps a CA-issued identity in a file wallet. It enrolls when
+// the wallet has no identity or its certificate has expired, and
+// re-enrolls under a fresh key once the certificate is within RenewBefore
+// of expiry.
+type caProvider struct {
+	mspID  string
+	cfg    CAConfig
+	client *CAClient
+	wallet *FileWallet
+	label  string
+	now    func() time.Time
+
+	mu sync.Mutex
+}
+
+func newCAProvider(cfg Config) (*caProvider, error) {
+	if cfg.CA.URL == "" || cfg.CA.EnrollmentID == "" {
+		return nil, fmt.Errorf("ca identity requires ca.url and ca.enrollmentId")
+	}
+	if cfg.Wallet.Path == "" {
+		return nil, fmt.Errorf("ca identity requires wallet.path to store enrolled credentials")
+	}
+	client, err := NewCAClient(cfg.CA.URL, cfg.CA.CAName, cfg.CA.TLSCertPath)
+	if err != nil {
+		return nil, err
+	}
+	label := cfg.Wallet.Label
+	if label == "" {
+		label = cfg.CA.EnrollmentID
+	}
+	return &caProvider{
+		mspID:  cfg.MSPID,
+		cfg:    cfg.CA,
+		client: client,
+		wallet: NewFileWallet(cfg.Wallet.Path),
+		label:  label,
+		now:    time.Now,
+	}, nil
+}
+
+// Credentials returns the stored identity, renewing it first if needed.
+// When renewal fails but the stored certificate is still valid, both the
+// credentials and the error are returned.
+func (p *caProvider) Credentials(ctx context.Context) (*Credentials, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	e, err := p.wallet.Get(p.label)
+	if errors.Is(err, ErrNotFound) {
+		return p.enroll(ctx)
+	}
+	if err != nil {
+		return nil, err
+	}
+	certificate, privateKey, err := e.Parse()
+	if err != nil {
+		return nil, fmt.Errorf("wallet identity %s: %w", p.label, err)
+	}
+	creds, err := newCredentials(p.mspID, certificate, privateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	now := p.now()
+	if now.Before(certificate.NotAfter.Add(-p.cfg.RenewBefore)) {
+		return creds, nil
+	}
+	if !now.Before(certificate.NotAfter) {
+		return p.enroll(ctx)
+	}
+
+	certificate, privateKey, err = p.client.Reenroll(ctx, creds)
+	if err != nil {
+		return creds, fmt.Errorf("failed to re-enroll %s, certificate expires %s: %w", p.label, creds.Certificate.NotAfter.Format(time.RFC3339), err)
+	}
+	return p.store(certificate, privateKey)
+}
+
+func (p *caProvider) enroll(ctx context.Context) (*Credentials, error) {
+	secret, err := p.cfg.secret()
+	if err != nil {
+		return nil, err
+	}
+	if secret == "" {
+		return nil, fmt.Errorf("%s is not enrolled or has expired, and no enrollment secret is configured", p.cfg.EnrollmentID)
+	}
+	certificate, privateKey, err := p.client.Enroll(ctx, p.cfg.EnrollmentID, secret)
+	if err != nil {
+		return nil, fmt.Errorf("failed to enroll %s: %w", p.cfg.EnrollmentID, err)
+	}
+	return p.store(certificate, privateKey)
+}
+
+func (p *caProvider) store(certificate *x509.Certificate, privateKey crypto.PrivateKey) (*Credentials, error) {
+	e, err := NewEntry(p.mspID, certificate, privateKey)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.wallet.Put(p.label, e); err != nil {
+		return nil, fmt.Errorf("failed to store enrolled identity: %w", err)
+	}
+	return newCredentials(p.mspID, certificate, privateKey)
+}
@@ -0,0 +1,189 @@
+package wallet
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-gateway/pkg/identity"
+)
+
+// fakeCA implements the enroll and reenroll endpoints of a Fabric CA.
+type fakeCA struct {
+	cert     *x509.Certificate
+	key      *ecdsa.PrivateKey
+	validFor time.Duration
+
+	enrolls, reenrolls int
+}
+
+func (ca *fakeCA) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+
+	switch r.URL.Path {
+	case "/api/v1/enroll":
+		if id, secret, ok := r.BasicAuth(); !ok || id != "recorder" || secret != "s3cret" {
+			ca.fail(w, "authentication failure")
+			return
+		}
+		ca.enrolls++
+	case "/api/v1/reenroll":
+		if !ca.verifyToken(r, body) {
+			ca.fail(w, "invalid token")
+			return
+		}
+		ca.reenrolls++
+	default:
+		http.NotFound(w, r)
+		return
+	}
+
+	var req enrollRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		ca.fail(w, err.Error())
+		return
+	}
+	block, _ := pem.Decode([]byte(req.CertificateRequest))
+	csr, err := x509.ParseCertificateRequest(block.Bytes)
+	if err != nil {
+		ca.fail(w, err.Error())
+		return
+	}
+	cert, err := issueCertificate(csr.Subject.CommonName, csr.PublicKey.(*ecdsa.PublicKey), time.Now().Add(ca.validFor), ca.cert, ca.key)
+	if err != nil {
+		ca.fail(w, err.Error())
+		return
+	}
+	certPEM, _ := identity.CertificateToPEM(cert)
+	json.NewEncoder(w).Encode(map[string]any{
+		"success": true,
+		"result":  map[string]any{"Cert": base64.StdEncoding.EncodeToString(certPEM)},
+	})
+}
+
+func (ca *fakeCA) verifyToken(r *http.Request, body []byte) bool {
+	b64cert, b64sig, ok := strings.Cut(r.Header.Get("Authorization"), ".")
+	if !ok {
+		return false
+	}
+	certPEM, _ := base64.StdEncoding.DecodeString(b64cert)
+	sig, _ := base64.StdEncoding.DecodeString(b64sig)
+	cert, err := identity.CertificateFromPEM(certPEM)
+	if err != nil || cert.CheckSignatureFrom(ca.cert) != nil {
+		return false
+	}
+	b64 := base64.StdEncoding.EncodeToString
+	payload := r.Method + "." + b64([]byte(r.URL.RequestURI())) + "." + b64(body) + "." + b64cert
+	digest := sha256.Sum256([]byte(payload))
+	return ecdsa.VerifyASN1(cert.PublicKey.(*ecdsa.PublicKey), digest[:], sig)
+}
+
+func (ca *fakeCA) fail(w http.ResponseWriter, msg string) {
+	w.WriteHeader(http.StatusUnauthorized)
+	json.NewEncoder(w).Encode(map[string]any{
+		"success": false,
+		"errors":  []map[string]any{{"code": 20, "message": msg}},
+	})
+}
+
+func newFakeCA(t *testing.T, validFor time.Duration) (*fakeCA, *httptest.Server) {
+	key := newTestKey(t)
+	ca := &fakeCA{cert: newTestCertificate(t, "ca-org1", key, time.Now().Add(24*time.Hour)), key: key, validFor: validFor}
+	srv := httptest.NewServer(ca)
+	t.Cleanup(srv.Close)
+	return ca, srv
+}
+
+// Test enrollment, reuse of the stored identity and re-enrollment before
+// expiry
+func TestCAProvider(t *testing.T) {
+	ca, srv := newFakeCA(t, time.Hour)
+	walletDir := filepath.Join(t.TempDir(), "wallet")
+	cfg := Config{
+		Type:   TypeCA,
+		MSPID:  "Org1MSP",
+		Wallet: WalletConfig{Path: walletDir},
+		CA:     CAConfig{URL: srv.URL, EnrollmentID: "recorder", Secret: "s3cret", RenewBefore: 10 * time.Minute},
+	}
+	p, err := New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	provider := p.(*caProvider)
+	ctx := context.Background()
+
+	enrolled, err := provider.Credentials(ctx)
+	if err != nil {
+		t.Fatalf("Failed to enroll: %v", err)
+	}
+	if ca.enrolls != 1 || enrolled.Certificate.Subject.CommonName != "recorder" {
+		t.Fatalf("Expected one enrollment for recorder, got %d", ca.enrolls)
+	}
+	if _, err := NewFileWallet(walletDir).Get("recorder"); err != nil {
+		t.Errorf("Expected enrolled identity in wallet: %v", err)
+	}
+
+	again, err := provider.Credentials(ctx)
+	if err != nil || !again.Certificate.Equal(enrolled.Certificate) || ca.enrolls != 1 {
+		t.Errorf("Expected stored identity to be reused")
+	}
+
+	provider.now = func() time.Time { return time.Now().Add(55 * time.Minute) }
+	renewed, err := provider.Credentials(ctx)
+	if err != nil {
+		t.Fatalf("Failed to re-enroll: %v", err)
+	}
+	if ca.reenrolls != 1 || renewed.Certificate.Equal(enrolled.Certificate) {
+		t.Fatalf("Expected a re-enrolled certificate")
+	}
+	if renewed.Certificate.PublicKey.(*ecdsa.PublicKey).Equal(enrolled.Certificate.PublicKey) {
+		t.Errorf("Expected re-enrollment to rotate the key")
+	}
+
+	digest := sha256.Sum256([]byte("proposal"))
+	sig, err := renewed.Sign(digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	verifySignature(t, renewed.Certificate, digest[:], sig)
+}
+
+// Test that a failed renewal keeps the still valid identity in use
+func TestCAProvider_RenewalFailure(t *testing.T) {
+	_, srv := newFakeCA(t, time.Hour)
+	cfg := Config{
+		Type:   TypeCA,
+		MSPID:  "Org1MSP",
+		Wallet: WalletConfig{Path: t.TempDir()},
+		CA:     CAConfig{URL: srv.URL, EnrollmentID: "recorder", Secret: "s3cret", RenewBefore: 10 * time.Minute},
+	}
+	p, err := New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, err := Load(context.Background(), p)
+	if err != nil {
+		t.Fatalf("Failed to load identity: %v", err)
+	}
+	cert := id.Certificate()
+
+	srv.Close()
+	p.(*caProvider).now = func() time.Time { return time.Now().Add(55 * time.Minute) }
+	if err := id.Refresh(context.Background()); err == nil {
+		t.Errorf("Expected renewal against a stopped CA to fail")
+	}
+	if !id.Certificate().Equal(cert) {
+		t.Errorf("Expected the current certificate to stay in use")
+	}
+}
@@ -0,0 +1,133 @@
+// Package wallet provides the X.509 identities that the daemon, the TUI and
+// mcapctl transact with. Identities come from an MSP directory, a JSON file
+// wallet, or are enrolled against a Fabric CA and re-enrolled before their
+// certificates expire.
+package wallet
+
+import (
+	"fmt"
+	"time"
+)
+
+// Identity provider types.
+const (
+	TypeMSP    = "msp"
+	TypeWallet = "wallet"
+	TypeCA     = "ca"
+)
+
+// Config selects and configures an identity provider.
+type Config struct {
+	// Type is msp, wallet or ca.
+	Type  string `yaml:"type"`
+	MSPID string `yaml:"mspId"`
+
+	MSP    MSPConfig    `yaml:"msp"`
+	Wallet WalletConfig `yaml:"wallet"`
+	CA     CAConfig     `yaml:"ca"`
+
+	// Signer, if set, signs in place of a private key read by the
+	// provider.
+	Signer SignerConfig `yaml:"signer"`
+
+	// RefreshInterval is how often a long-running client reloads its
+	// identity, picking up rotated keys and renewing CA certificates.
+	RefreshInterval time.Duration `yaml:"refreshInterval"`
+}
+
+// MSPConfig points at an MSP directory such as
+// users/User1@org1.example.com/msp, holding signcerts/ and keystore/.
+type MSPConfig struct {
+	Dir string `yaml:"dir"`
+}
+
+// WalletConfig names an identity in a directory of <label>.id files.
+type WalletConfig struct {
+	Path  string `yaml:"path"`
+	Label string `yaml:"label"`
+}
+
+// CAConfig describes how to enroll with a Fabric CA. Enrolled identities are
+// kept in the wallet given by Config.Wallet, under the enrollment ID unless
+// a label is set.
+type CAConfig struct {
+	URL    string `yaml:"url"`
+	CAName string `yaml:"caName"`
+
+	// TLSCertPath is the CA certificate used to verify an https URL. The
+	// system roots are used when it is empty.
+	TLSCertPath string `yaml:"tlsCertPath"`
+
+	EnrollmentID string `yaml:"enrollmentId"`
+	// Secret is the enrollment secret, or SecretFile a file holding it.
+	// Either is only needed for the first enrollment, or once the
+	// certificate has expired.
+	Secret     string `yaml:"secret"`
+	SecretFile string `yaml:"secretFile"`
+
+	// RenewBefore is how long before expiry the certificate is re-enrolled
+	// under a fresh key.
+	RenewBefore time.Duration `yaml:"renewBefore"`
+}
+
+// DefaultConfig uses User1 of the Fabric test network's Org1.
+func DefaultConfig() Config {
+	return Config{
+		Type:  TypeMSP,
+		MSPID: "Org1MSP",
+		MSP: MSPConfig{
+			Dir: "/home/oz/fabric-samples/test-network/organizations/peerOrganizations/org1.example.com/users/User1@org1.example.com/msp",
+		},
+		CA: CAConfig{
+			RenewBefore: 30 * 24 * time.Hour,
+		},
+		RefreshInterval: 1 * time.Hour,
+	}
+}
+
+// New returns the identity provider selected by cfg.
+func New(cfg Config) (Provider, error) {
+	if cfg.MSPID == "" {
+		return nil, fmt.Errorf("identity mspId is required")
+	}
+	open, err := newOpenSigner(cfg.Signer)
+	if err != nil {
+		return nil, err
+	}
+	certOnly := open != nil
+
+	var p Provider
+	switch cfg.Type {
+	case TypeMSP, "":
+		if cfg.MSP.Dir == "" {
+			return nil, fmt.Errorf("msp identity requires msp.dir")
+		}
+		p = &mspProvider{mspID: cfg.MSPID, dir: cfg.MSP.Dir, certOnly: certOnly}
+	case TypeWallet:
+		if cfg.Wallet.Path == "" || cfg.Wallet.Label == "" {
+			return nil, fmt.Errorf("wallet identity requires wallet.path and wallet.label")
+		}
+		p = &walletProvider{wallet: NewFileWallet(cfg.Wallet.Path), label: cfg.Wallet.Label, certOnly: certOnly}
+	case TypeCA:
+		if certOnly {
+			return nil, fmt.Errorf("ca identities generate their own keys and cannot use a %s signer", cfg.Signer.Type)
+		}
+		return newCAProvider(cfg)
+	default:
+		return nil, fmt.Errorf("unknown identity type %q", cfg.Type)
+	}
+
+	if certOnly {
+		return &signerProvider{Provider: p, open: open}, nil
+	}
+	return p, nil
+}
+
+// secret returns the enrollment secret, reading SecretFile if set.
+func (c CAConfig) secret() (string, error) {
+	secret, err := readSecret(c.Secret, c.SecretFile)
+	if err != nil {
+		return "", fmt.Errorf("failed to read enrollment secret: %w", err)
+	}
+	return secret, nil
+}
@@ -0,0 +1,187 @@
+package wallet
+
+import (
+	"context"
+	"crypto"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hyperledger/fabric-gateway/pkg/identity"
+)
+
+// ErrNotFound is returned for labels that are not in a wallet.
+var ErrNotFound = errors.New("identity not found in wallet")
+
+// Entry is an X.509 identity as stored by the Fabric SDK file wallets,
+// so wallets can be shared with other Fabric tooling.
+type Entry struct {
+	Credentials EntryCredentials `json:"credentials"`
+	MSPID       string           `json:"mspId"`
+	Type        string           `json:"type"`
+	Version     int              `json:"version"`
+}
+
+// EntryCredentials hold the PEM-encoded certificate and private key.
+type EntryCredentials struct {
+	Certificate string `json:"certificate"`
+	PrivateKey  string `json:"privateKey"`
+}
+
+// NewEntry encodes a certificate and private key as a wallet entry.
+func NewEntry(mspID string, certificate *x509.Certificate, privateKey crypto.PrivateKey) (*Entry, error) {
+	certificatePEM, err := identity.CertificateToPEM(certificate)
+	if err != nil {
+		return nil, err
+	}
+	privateKeyPEM, err := identity.PrivateKeyToPEM(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	return &Entry{
+		Credentials: EntryCredentials{Certificate: string(certificatePEM), PrivateKey: string(privateKeyPEM)},
+		MSPID:       mspID,
+		Type:        "X.509",
+		Version:     1,
+	}, nil
+}
+
+// Certificate decodes the certificate of e.
+func (e *Entry) Certificate() (*x509.Certificate, error) {
+	if e.Type != "X.509" {
+		return nil, fmt.Errorf("unsupported identity type %q", e.Type)
+	}
+	certificate, err := identity.CertificateFromPEM([]byte(e.Credentials.Certificate))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse certificate: %w", err)
+	}
+	return certificate, nil
+}
+
+// Parse decodes the certificate and private key of e.
+func (e *Entry) Parse() (*x509.Certificate, crypto.PrivateKey, error) {
+	certificate, err := e.Certificate()
+	if err != nil {
+		return nil, nil, err
+	}
+	privateKey, err := identity.PrivateKeyFromPEM([]byte(e.Credentials.PrivateKey))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse private key: %w", err)
+	}
+	return certificate, privateKey, nil
+}
+
+// FileWallet is a directory holding one <label>.id file per identity.
+type FileWallet struct {
+	dir string
+}
+
+// NewFileWallet opens the wallet in dir. The directory is created on the
+// first Put.
+func NewFileWallet(dir string) *FileWallet {
+	return &FileWallet{dir: dir}
+}
+
+func (w *FileWallet) path(label string) string {
+	return filepath.Join(w.dir, label+".id")
+}
+
+// Get reads the identity stored under label.
+func (w *FileWallet) Get(label string) (*Entry, error) {
+	b, err := os.ReadFile(w.path(label))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%s: %w", label, ErrNotFound)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var e Entry
+	if err := json.Unmarshal(b, &e); err != nil {
+		return nil, fmt.Errorf("failed to parse wallet identity %s: %w", label, err)
+	}
+	return &e, nil
+}
+
+// Put stores e under label, replacing any previous identity atomically.
+func (w *FileWallet) Put(label string, e *Entry) error {
+	if err := os.MkdirAll(w.dir, 0o700); err != nil {
+		return err
+	}
+	b, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(w.dir, label+".id.tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), w.path(label))
+}
+
+// List returns the labels in the wallet.
+func (w *FileWallet) List() ([]string, error) {
+	entries, err := os.ReadDir(w.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var labels []string
+	for _, e := range entries {
+		if label, ok := strings.CutSuffix(e.Name(), ".id"); ok && e.Type().IsRegular() {
+			labels = append(labels, label)
+		}
+	}
+	return labels, nil
+}
+
+// Remove deletes the identity stored under label.
+func (w *FileWallet) Remove(label string) error {
+	err := os.Remove(w.path(label))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// walletProvider reads credentials from a file wallet each time they are
+// requested, so identities replaced in the wallet are picked up. With
+// certOnly set the entry need not hold a private key.
+type walletProvider struct {
+	wallet   *FileWallet
+	label    string
+	certOnly bool
+}
+
+func (p *walletProvider) Credentials(context.Context) (*Credentials, error) {
+	e, err := p.wallet.Get(p.label)
+	if err != nil {
+		return nil, err
+	}
+	if p.certOnly {
+		certificate, err := e.Certificate()
+		if err != nil {
+			return nil, fmt.Errorf("wallet identity %s: %w", p.label, err)
+		}
+		return &Credentials{MSPID: e.MSPID, Certificate: certificate}, nil
+	}
+	certificate, privateKey, err := e.Parse()
+	if err != nil {
+		return nil, fmt.Errorf("wallet identity %s: %w", p.label, err)
+	}
+	return newCredentials(e.MSPID, certificate, privateKey)
+}
@@ -0,0 +1,109 @@
+package wallet
+
+import (
+	"context"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"sync/atomic"
+	"time"
+
+	"github.com/hyperledger/fabric-gateway/pkg/identity"
+)
+
+// Credentials are a certificate and the means to sign with its key.
+type Credentials struct {
+	MSPID       string
+	Certificate *x509.Certificate
+	Sign        identity.Sign
+}
+
+// Provider supplies the current credentials of a client. Providers may
+// return different credentials over time as keys are rotated, and may
+// return usable credentials together with an error, such as a failed
+// renewal of a certificate that has not yet expired.
+type Provider interface {
+	Credentials(ctx context.Context) (*Credentials, error)
+}
+
+// Identity is a client identity that can be refreshed while in use. It
+// implements identity.Identity and its Sign method can be passed to
+// client.WithSign, so a gateway connection keeps working across renewals.
+type Identity struct {
+	provider Provider
+	current  atomic.Pointer[Credentials]
+}
+
+// Load fetches the initial credentials from p. It only fails if p has no
+// usable credentials; other errors are left for Refresh to report.
+func Load(ctx context.Context, p Provider) (*Identity, error) {
+	id := &Identity{provider: p}
+	if err := id.Refresh(ctx); err != nil && id.current.Load() == nil {
+		return nil, err
+	}
+	return id, nil
+}
+
+// Refresh asks the provider for its current credentials. Any usable
+// credentials it returns are adopted even if it also reports an error.
+func (id *Identity) Refresh(ctx context.Context) error {
+	creds, err := id.provider.Credentials(ctx)
+	if creds != nil {
+		id.current.Store(creds)
+	}
+	return err
+}
+
+// Run refreshes the identity every interval until ctx is done, reporting
+// failures to onError. The previous credentials stay in use on failure.
+func (id *Identity) Run(ctx context.Context, interval time.Duration, onError func(error)) {
+	if interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := id.Refresh(ctx); err != nil && onError != nil {
+				onError(err)
+			}
+		}
+	}
+}
+
+// Close releases any signer held by the provider, such as a PKCS#11
+// session.
+func (id *Identity) Close() error {
+	if c, ok := id.provider.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// MspID returns the MSP ID of the current credentials.
+func (id *Identity) MspID() string {
+	return id.current.Load().MSPID
+}
+
+// Credentials returns the current certificate in PEM form.
+func (id *Identity) Credentials() []byte {
+	certificatePEM, _ := identity.CertificateToPEM(id.current.Load().Certificate)
+	return certificatePEM
+}
+
+// Certificate returns the current certificate.
+func (id *Identity) Certificate() *x509.Certificate {
+	return id.current.Load().Certificate
+}
+
+// Sign signs digest with the key of the current certificate.
+func (id *Identity) Sign(digest []byte) ([]byte, error) {
+	creds := id.current.Load()
+	if creds.Sign == nil {
+		return nil, fmt.Errorf("identity %s has no signing key", creds.Certificate.Subject.CommonName)
+	}
+	return creds.Sign(digest)
+}
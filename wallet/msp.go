@@ -0,0 +1,94 @@
+package wallet
+
+import (
+	"context"
+	"crypto"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/hyperledger/fabric-gateway/pkg/identity"
+)
+
+// mspProvider reads credentials from an MSP directory. The signing
+// certificate is the first file in signcerts/ and the key is whichever
+// file in keystore/ matches it, so stale keys left behind by a rotation
+// are ignored. With certOnly set the keystore is not read at all.
+type mspProvider struct {
+	mspID    string
+	dir      string
+	certOnly bool
+}
+
+func (p *mspProvider) Credentials(context.Context) (*Credentials, error) {
+	certFiles, err := readDir(filepath.Join(p.dir, "signcerts"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read certificate directory: %w", err)
+	}
+	if len(certFiles) == 0 {
+		return nil, fmt.Errorf("no certificate in %s", filepath.Join(p.dir, "signcerts"))
+	}
+	certificatePEM, err := os.ReadFile(certFiles[0])
+	if err != nil {
+		return nil, fmt.Errorf("failed to read certificate file: %w", err)
+	}
+	certificate, err := identity.CertificateFromPEM(certificatePEM)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse certificate %s: %w", certFiles[0], err)
+	}
+	if p.certOnly {
+		return &Credentials{MSPID: p.mspID, Certificate: certificate}, nil
+	}
+
+	keyFiles, err := readDir(filepath.Join(p.dir, "keystore"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read keystore: %w", err)
+	}
+	for _, keyFile := range keyFiles {
+		privateKeyPEM, err := os.ReadFile(keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read private key file: %w", err)
+		}
+		privateKey, err := identity.PrivateKeyFromPEM(privateKeyPEM)
+		if err != nil || !keyMatches(certificate, privateKey) {
+			continue
+		}
+		return newCredentials(p.mspID, certificate, privateKey)
+	}
+	return nil, fmt.Errorf("no private key in %s matches %s", filepath.Join(p.dir, "keystore"), certFiles[0])
+}
+
+// newCredentials pairs certificate with an in-memory private key.
+func newCredentials(mspID string, certificate *x509.Certificate, privateKey crypto.PrivateKey) (*Credentials, error) {
+	sign, err := identity.NewPrivateKeySign(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	return &Credentials{MSPID: mspID, Certificate: certificate, Sign: sign}, nil
+}
+
+// keyMatches reports whether privateKey belongs to certificate.
+func keyMatches(certificate *x509.Certificate, privateKey crypto.PrivateKey) bool {
+	signer, ok := privateKey.(crypto.Signer)
+	if !ok {
+		return false
+	}
+	public, ok := signer.Public().(interface{ Equal(crypto.PublicKey) bool })
+	return ok && public.Equal(certificate.PublicKey)
+}
+
+// readDir lists the regular files in dir by name.
+func readDir(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, e := range entries {
+		if e.Type().IsRegular() {
+			files = append(files, filepath.Join(dir, e.Name()))
+		}
+	}
+	return files, nil
+}
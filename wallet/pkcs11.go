@@ -0,0 +1,44 @@
+//go:build pkcs11
+
+package wallet
+
+import (
+	"crypto/x509"
+	"fmt"
+
+	"github.com/hyperledger/fabric-gateway/pkg/identity"
+)
+
+// newPKCS11Signer signs with a key held in a PKCS#11 token. The key never
+// leaves the token.
+func newPKCS11Signer(cfg PKCS11Config, certificate *x509.Certificate) (identity.Sign, func() error, error) {
+	pin, err := readSecret(cfg.Pin, cfg.PinFile)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read PKCS#11 PIN: %w", err)
+	}
+	identifier := cfg.Identifier
+	if identifier == "" {
+		if identifier, err = subjectKeyIdentifier(certificate); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	factory, err := identity.NewHSMSignerFactory(cfg.Library)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load PKCS#11 library: %w", err)
+	}
+	sign, closeSigner, err := factory.NewHSMSigner(identity.HSMSignerOptions{
+		Label:      cfg.Label,
+		Pin:        pin,
+		Identifier: identifier,
+	})
+	if err != nil {
+		factory.Dispose()
+		return nil, nil, fmt.Errorf("failed to open PKCS#11 key: %w", err)
+	}
+
+	return sign, func() error {
+		defer factory.Dispose()
+		return closeSigner()
+	}, nil
+}
@@ -0,0 +1,15 @@
+//go:build !pkcs11
+
+package wallet
+
+import (
+	"crypto/x509"
+	"errors"
+
+	"github.com/hyperledger/fabric-gateway/pkg/identity"
+)
+
+// newPKCS11Signer is unavailable without cgo and the pkcs11 build tag.
+func newPKCS11Signer(PKCS11Config, *x509.Certificate) (identity.Sign, func() error, error) {
+	return nil, nil, errors.New("PKCS#11 support is not compiled in, rebuild with -tags pkcs11")
+}
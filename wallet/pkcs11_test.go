@@ -0,0 +1,139 @@
+//go:build pkcs11
+
+package wallet
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/sha256"
+	"encoding/asn1"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/miekg/pkcs11"
+)
+
+// Test signing with a key generated inside a SoftHSM token. Initialise a
+// token first, for example:
+//
+//	softhsm2-util --init-token --free --label mcap --pin 98765432 --so-pin 1234
+//	PKCS11_LIB=/usr/lib/softhsm/libsofthsm2.so PKCS11_LABEL=mcap PKCS11_PIN=98765432 \
+//	    go test -tags pkcs11 ./wallet
+func TestPKCS11Signer(t *testing.T) {
+	lib, label, pin := os.Getenv("PKCS11_LIB"), os.Getenv("PKCS11_LABEL"), os.Getenv("PKCS11_PIN")
+	if lib == "" || label == "" || pin == "" {
+		t.Skip("set PKCS11_LIB, PKCS11_LABEL and PKCS11_PIN to test against SoftHSM")
+	}
+
+	ctx := pkcs11.New(lib)
+	if ctx == nil {
+		t.Fatalf("Failed to load %s", lib)
+	}
+	if err := ctx.Initialize(); err != nil {
+		t.Fatal(err)
+	}
+	defer ctx.Destroy()
+	defer ctx.Finalize()
+
+	session := openTestSession(t, ctx, label, pin)
+	defer ctx.CloseSession(session)
+
+	id := []byte(fmt.Sprintf("mcap-test-%d", time.Now().UnixNano()))
+	public, destroy := generateTestKey(t, ctx, session, id)
+	defer destroy()
+
+	caKey := newTestKey(t)
+	caCert := newTestCertificate(t, "ca-org1", caKey, time.Now().Add(time.Hour))
+	cert, err := issueCertificate("recorder", public, time.Now().Add(time.Hour), caCert, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sign, closeSigner, err := newPKCS11Signer(PKCS11Config{Library: lib, Label: label, Pin: pin, Identifier: string(id)}, cert)
+	if err != nil {
+		t.Fatalf("Failed to open PKCS#11 signer: %v", err)
+	}
+	defer closeSigner()
+
+	if err := checkSigner(cert, sign); err != nil {
+		t.Errorf("Signer check failed: %v", err)
+	}
+	digest := sha256.Sum256([]byte("proposal"))
+	signature, err := sign(digest[:])
+	if err != nil {
+		t.Fatalf("Failed to sign: %v", err)
+	}
+	verifySignature(t, cert, digest[:], signature)
+}
+
+func openTestSession(t *testing.T, ctx *pkcs11.Ctx, label, pin string) pkcs11.SessionHandle {
+	t.Helper()
+	slots, err := ctx.GetSlotList(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, slot := range slots {
+		info, err := ctx.GetTokenInfo(slot)
+		if err != nil || info.Label != label {
+			continue
+		}
+		session, err := ctx.OpenSession(slot, pkcs11.CKF_SERIAL_SESSION|pkcs11.CKF_RW_SESSION)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := ctx.Login(session, pkcs11.CKU_USER, pin); err != nil {
+			t.Fatal(err)
+		}
+		return session
+	}
+	t.Fatalf("No token labelled %s", label)
+	return 0
+}
+
+// generateTestKey creates a P-256 key pair on the token under id and
+// returns its public half and a function removing both objects.
+func generateTestKey(t *testing.T, ctx *pkcs11.Ctx, session pkcs11.SessionHandle, id []byte) (*ecdsa.PublicKey, func()) {
+	t.Helper()
+	p256, _ := asn1.Marshal(asn1.ObjectIdentifier{1, 2, 840, 10045, 3, 1, 7})
+	publicHandle, privateHandle, err := ctx.GenerateKeyPair(session,
+		[]*pkcs11.Mechanism{pkcs11.NewMechanism(pkcs11.CKM_EC_KEY_PAIR_GEN, nil)},
+		[]*pkcs11.Attribute{
+			pkcs11.NewAttribute(pkcs11.CKA_TOKEN, true),
+			pkcs11.NewAttribute(pkcs11.CKA_EC_PARAMS, p256),
+			pkcs11.NewAttribute(pkcs11.CKA_VERIFY, true),
+			pkcs11.NewAttribute(pkcs11.CKA_ID, id),
+		},
+		[]*pkcs11.Attribute{
+			pkcs11.NewAttribute(pkcs11.CKA_TOKEN, true),
+			pkcs11.NewAttribute(pkcs11.CKA_PRIVATE, true),
+			pkcs11.NewAttribute(pkcs11.CKA_SENSITIVE, true),
+			pkcs11.NewAttribute(pkcs11.CKA_SIGN, true),
+			pkcs11.NewAttribute(pkcs11.CKA_ID, id),
+		})
+	if err != nil {
+		t.Fatalf("Failed to generate key pair: %v", err)
+	}
+	destroy := func() {
+		ctx.DestroyObject(session, publicHandle)
+		ctx.DestroyObject(session, privateHandle)
+	}
+
+	attrs, err := ctx.GetAttributeValue(session, publicHandle, []*pkcs11.Attribute{pkcs11.NewAttribute(pkcs11.CKA_EC_POINT, nil)})
+	if err != nil {
+		destroy()
+		t.Fatal(err)
+	}
+	var point []byte
+	if _, err := asn1.Unmarshal(attrs[0].Value, &point); err != nil {
+		destroy()
+		t.Fatal(err)
+	}
+	x, y := elliptic.Unmarshal(elliptic.P256(), point)
+	if x == nil {
+		destroy()
+		t.Fatalf("Token returned an invalid EC point")
+	}
+	return &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}, destroy
+}
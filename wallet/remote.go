@@ -0,0 +1,121 @@
+package wallet
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-gateway/pkg/identity"
+)
+
+// SignRequest is the body POSTed to a remote signer's /sign endpoint.
+// Digest is the SHA-256 of the message to sign.
+type SignRequest struct {
+	Digest []byte `json:"digest"`
+	KeyID  string `json:"keyId,omitempty"`
+}
+
+// SignResponse is returned by a remote signer. Signature is an ASN.1 DER
+// ECDSA signature. Failures set Error and a non-200 status.
+type SignResponse struct {
+	Signature []byte `json:"signature,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// newRemoteSigner returns a Sign that delegates to a signing service
+// speaking SignRequest/SignResponse over HTTP or a Unix socket.
+func newRemoteSigner(cfg RemoteConfig, certificate *x509.Certificate) (identity.Sign, error) {
+	endpoint, client, err := remoteClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+	public, _ := certificate.PublicKey.(*ecdsa.PublicKey)
+
+	return func(digest []byte) ([]byte, error) {
+		body, err := json.Marshal(SignRequest{Digest: digest, KeyID: cfg.KeyID})
+		if err != nil {
+			return nil, err
+		}
+		req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, endpoint, bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to reach remote signer: %w", err)
+		}
+		defer resp.Body.Close()
+
+		var result SignResponse
+		if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+			return nil, fmt.Errorf("remote signer returned %s: %w", resp.Status, err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("remote signer returned %s: %s", resp.Status, result.Error)
+		}
+		if public == nil {
+			return result.Signature, nil
+		}
+		return lowS(public, result.Signature)
+	}, nil
+}
+
+// remoteClient resolves the sign endpoint and an HTTP client for cfg,
+// dialling the socket for unix:// URLs.
+func remoteClient(cfg RemoteConfig) (string, *http.Client, error) {
+	timeout := cfg.Timeout
+	if timeout == 0 {
+		timeout = 10 * time.Second
+	}
+	u, err := url.Parse(cfg.URL)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid remote signer URL: %w", err)
+	}
+
+	switch u.Scheme {
+	case "http", "https":
+		return strings.TrimSuffix(cfg.URL, "/") + "/sign", &http.Client{Timeout: timeout}, nil
+	case "unix":
+		socket := u.Path
+		transport := &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, "unix", socket)
+			},
+		}
+		return "http://signer/sign", &http.Client{Transport: transport, Timeout: timeout}, nil
+	}
+	return "", nil, fmt.Errorf("unsupported remote signer URL scheme %q", u.Scheme)
+}
+
+type ecdsaSignature struct {
+	R, S *big.Int
+}
+
+// lowS rewrites an ECDSA signature into the canonical low-S form that
+// Fabric peers require.
+func lowS(public *ecdsa.PublicKey, signature []byte) ([]byte, error) {
+	var sig ecdsaSignature
+	if rest, err := asn1.Unmarshal(signature, &sig); err != nil || len(rest) != 0 {
+		return nil, fmt.Errorf("remote signer returned a malformed signature")
+	}
+	n := public.Curve.Params().N
+	halfOrder := new(big.Int).Rsh(n, 1)
+	if sig.S.Cmp(halfOrder) <= 0 {
+		return signature, nil
+	}
+	sig.S = new(big.Int).Sub(n, sig.S)
+	return asn1.Marshal(sig)
+}
@@ -0,0 +1,124 @@
+package wallet
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/asn1"
+	"encoding/json"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-gateway/pkg/identity"
+)
+
+// newSignerServer serves SignRequests on a Unix socket, signing with key
+// and always answering with the high-S form of the signature.
+func newSignerServer(t *testing.T, key *ecdsa.PrivateKey) string {
+	t.Helper()
+	socket := filepath.Join(t.TempDir(), "signer.sock")
+	l, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Skipf("Unix sockets unavailable: %v", err)
+	}
+
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req SignRequest
+		if r.URL.Path != "/sign" || json.NewDecoder(r.Body).Decode(&req) != nil || req.KeyID != "recorder" {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(SignResponse{Error: "bad request"})
+			return
+		}
+		r1, s1, err := ecdsa.Sign(rand.Reader, key, req.Digest)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		n := key.Curve.Params().N
+		if s1.Cmp(new(big.Int).Rsh(n, 1)) <= 0 {
+			s1 = new(big.Int).Sub(n, s1)
+		}
+		sig, _ := asn1.Marshal(ecdsaSignature{R: r1, S: s1})
+		json.NewEncoder(w).Encode(SignResponse{Signature: sig})
+	}))
+	srv.Listener = l
+	srv.Start()
+	t.Cleanup(srv.Close)
+	return "unix://" + socket
+}
+
+// Test signing through a remote signer with the certificate from an MSP
+// directory that holds no key
+func TestRemoteSigner(t *testing.T) {
+	key := newTestKey(t)
+	cert := newTestCertificate(t, "recorder", key, time.Now().Add(time.Hour))
+	certPEM, _ := identity.CertificateToPEM(cert)
+	dir := t.TempDir()
+	writePEM(t, filepath.Join(dir, "signcerts", "cert.pem"), certPEM)
+
+	cfg := Config{
+		Type:  TypeMSP,
+		MSPID: "Org1MSP",
+		MSP:   MSPConfig{Dir: dir},
+		Signer: SignerConfig{
+			Type:   SignerRemote,
+			Remote: RemoteConfig{URL: newSignerServer(t, key), KeyID: "recorder"},
+		},
+	}
+	p, err := New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, err := Load(context.Background(), p)
+	if err != nil {
+		t.Fatalf("Failed to load identity: %v", err)
+	}
+	defer id.Close()
+
+	digest := sha256.Sum256([]byte("proposal"))
+	signature, err := id.Sign(digest[:])
+	if err != nil {
+		t.Fatalf("Failed to sign: %v", err)
+	}
+	verifySignature(t, cert, digest[:], signature)
+
+	var sig ecdsaSignature
+	if _, err := asn1.Unmarshal(signature, &sig); err != nil {
+		t.Fatal(err)
+	}
+	if sig.S.Cmp(new(big.Int).Rsh(key.Curve.Params().N, 1)) > 0 {
+		t.Errorf("Expected signature to be normalised to low-S")
+	}
+}
+
+// Test that a signer holding a different key is rejected at load time
+func TestRemoteSigner_WrongKey(t *testing.T) {
+	cert := newTestCertificate(t, "recorder", newTestKey(t), time.Now().Add(time.Hour))
+	certPEM, _ := identity.CertificateToPEM(cert)
+	dir := t.TempDir()
+	writePEM(t, filepath.Join(dir, "signcerts", "cert.pem"), certPEM)
+
+	cfg := Config{
+		Type:  TypeMSP,
+		MSPID: "Org1MSP",
+		MSP:   MSPConfig{Dir: dir},
+		Signer: SignerConfig{
+			Type:   SignerRemote,
+			Remote: RemoteConfig{URL: newSignerServer(t, newTestKey(t)), KeyID: "recorder"},
+		},
+	}
+	p, err := New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(context.Background(), p); err == nil || !strings.Contains(err.Error(), "does not match") {
+		t.Errorf("Expected key mismatch error, got %v", err)
+	}
+}
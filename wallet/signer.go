@@ -0,0 +1,175 @@
+package wallet
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/fabric-gateway/pkg/identity"
+)
+
+// Signer types. An empty type signs with the private key held by the
+// identity provider.
+const (
+	SignerPKCS11 = "pkcs11"
+	SignerRemote = "remote"
+)
+
+// SignerConfig moves signing off the client host. With a signer set, MSP
+// directories and wallets only need to hold the certificate.
+type SignerConfig struct {
+	// Type is pkcs11, remote or empty.
+	Type string `yaml:"type"`
+
+	PKCS11 PKCS11Config `yaml:"pkcs11"`
+	Remote RemoteConfig `yaml:"remote"`
+}
+
+// PKCS11Config selects a key in a PKCS#11 token such as SoftHSM or a
+// hardware security module.
+type PKCS11Config struct {
+	// Library is the path of the PKCS#11 module, for example
+	// /usr/lib/softhsm/libsofthsm2.so.
+	Library string `yaml:"library"`
+	Label   string `yaml:"label"`
+	// Pin is the user PIN, or PinFile a file holding it.
+	Pin     string `yaml:"pin"`
+	PinFile string `yaml:"pinFile"`
+	// Identifier is the CKA_ID of the private key. It defaults to the
+	// subject key identifier Fabric assigns, the SHA-256 of the
+	// certificate's uncompressed public key.
+	Identifier string `yaml:"identifier"`
+}
+
+// RemoteConfig points at a signing service on the local machine.
+type RemoteConfig struct {
+	// URL is http://host:port/path or unix:///path/to/socket.
+	URL string `yaml:"url"`
+	// KeyID is passed to the signer to select a key, if it holds several.
+	KeyID   string        `yaml:"keyId"`
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+// openSigner starts a signer for the key of certificate. The returned
+// close function releases it.
+type openSigner func(certificate *x509.Certificate) (identity.Sign, func() error, error)
+
+// newOpenSigner returns the opener for cfg, or nil if signing stays with
+// the identity provider.
+func newOpenSigner(cfg SignerConfig) (openSigner, error) {
+	switch cfg.Type {
+	case "":
+		return nil, nil
+	case SignerPKCS11:
+		if cfg.PKCS11.Library == "" || cfg.PKCS11.Label == "" {
+			return nil, fmt.Errorf("pkcs11 signer requires pkcs11.library and pkcs11.label")
+		}
+		return func(certificate *x509.Certificate) (identity.Sign, func() error, error) {
+			return newPKCS11Signer(cfg.PKCS11, certificate)
+		}, nil
+	case SignerRemote:
+		if cfg.Remote.URL == "" {
+			return nil, fmt.Errorf("remote signer requires remote.url")
+		}
+		return func(certificate *x509.Certificate) (identity.Sign, func() error, error) {
+			sign, err := newRemoteSigner(cfg.Remote, certificate)
+			return sign, func() error { return nil }, err
+		}, nil
+	}
+	return nil, fmt.Errorf("unknown signer type %q", cfg.Type)
+}
+
+// signerProvider takes certificates from another provider and signs with
+// an external signer, opened for the first certificate it sees.
+type signerProvider struct {
+	Provider
+	open openSigner
+
+	mu    sync.Mutex
+	sign  identity.Sign
+	close func() error
+}
+
+func (p *signerProvider) Credentials(ctx context.Context) (*Credentials, error) {
+	creds, err := p.Provider.Credentials(ctx)
+	if creds == nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.sign == nil {
+		sign, closeSigner, err := p.open(creds.Certificate)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open signer: %w", err)
+		}
+		p.sign, p.close = sign, closeSigner
+	}
+	if err := checkSigner(creds.Certificate, p.sign); err != nil {
+		return nil, err
+	}
+	return &Credentials{MSPID: creds.MSPID, Certificate: creds.Certificate, Sign: p.sign}, err
+}
+
+// Close releases the signer.
+func (p *signerProvider) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.close == nil {
+		return nil
+	}
+	err := p.close()
+	p.sign, p.close = nil, nil
+	return err
+}
+
+// checkSigner signs a probe digest to make sure the signer holds the key
+// of certificate, rather than failing every transaction later.
+func checkSigner(certificate *x509.Certificate, sign identity.Sign) error {
+	public, ok := certificate.PublicKey.(*ecdsa.PublicKey)
+	if !ok {
+		return nil
+	}
+	digest := sha256.Sum256([]byte("mcap signer check " + certificate.SerialNumber.String()))
+	signature, err := sign(digest[:])
+	if err != nil {
+		return fmt.Errorf("signer failed: %w", err)
+	}
+	if !ecdsa.VerifyASN1(public, digest[:], signature) {
+		return fmt.Errorf("signer key does not match certificate %s", certificate.Subject.CommonName)
+	}
+	return nil
+}
+
+// subjectKeyIdentifier is the key identifier Fabric tooling uses as the
+// CKA_ID of keys it stores in an HSM.
+func subjectKeyIdentifier(certificate *x509.Certificate) (string, error) {
+	public, ok := certificate.PublicKey.(*ecdsa.PublicKey)
+	if !ok {
+		return "", fmt.Errorf("unsupported public key type %T", certificate.PublicKey)
+	}
+	point, err := public.ECDH()
+	if err != nil {
+		return "", err
+	}
+	ski := sha256.Sum256(point.Bytes())
+	return string(ski[:]), nil
+}
+
+// readSecret returns value, or the trimmed contents of file if set.
+func readSecret(value, file string) (string, error) {
+	if file == "" {
+		return value, nil
+	}
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(b)), nil
+}
@@ -0,0 +1,153 @@
+package wallet
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-gateway/pkg/identity"
+)
+
+// issueCertificate signs a certificate for pub with parentKey, or makes a
+// self-signed CA certificate when parent is nil.
+func issueCertificate(cn string, pub *ecdsa.PublicKey, notAfter time.Time, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, error) {
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     notAfter,
+		IsCA:         parent == nil,
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+	}
+	if parent == nil {
+		parent = tmpl
+		tmpl.BasicConstraintsValid = true
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, parent, pub, parentKey)
+	if err != nil {
+		return nil, err
+	}
+	return x509.ParseCertificate(der)
+}
+
+// newTestCertificate returns a self-signed certificate for key.
+func newTestCertificate(t *testing.T, cn string, key *ecdsa.PrivateKey, notAfter time.Time) *x509.Certificate {
+	t.Helper()
+	cert, err := issueCertificate(cn, &key.PublicKey, notAfter, nil, key)
+	if err != nil {
+		t.Fatalf("Failed to create certificate: %v", err)
+	}
+	return cert
+}
+
+func newTestKey(t *testing.T) *ecdsa.PrivateKey {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	return key
+}
+
+func writePEM(t *testing.T, path string, pemBytes []byte) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, pemBytes, 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// verifySignature checks that sig over digest was made by cert's key.
+func verifySignature(t *testing.T, cert *x509.Certificate, digest, sig []byte) {
+	t.Helper()
+	if !ecdsa.VerifyASN1(cert.PublicKey.(*ecdsa.PublicKey), digest, sig) {
+		t.Errorf("Signature does not verify against %s", cert.Subject.CommonName)
+	}
+}
+
+// Test that the MSP provider picks the key matching the certificate
+func TestMSPProvider(t *testing.T) {
+	dir := t.TempDir()
+	key, stale := newTestKey(t), newTestKey(t)
+	cert := newTestCertificate(t, "User1", key, time.Now().Add(time.Hour))
+
+	certPEM, _ := identity.CertificateToPEM(cert)
+	keyPEM, _ := identity.PrivateKeyToPEM(key)
+	stalePEM, _ := identity.PrivateKeyToPEM(stale)
+	writePEM(t, filepath.Join(dir, "signcerts", "cert.pem"), certPEM)
+	writePEM(t, filepath.Join(dir, "keystore", "a_sk"), stalePEM)
+	writePEM(t, filepath.Join(dir, "keystore", "b_sk"), keyPEM)
+
+	p, err := New(Config{Type: TypeMSP, MSPID: "Org1MSP", MSP: MSPConfig{Dir: dir}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, err := Load(context.Background(), p)
+	if err != nil {
+		t.Fatalf("Failed to load identity: %v", err)
+	}
+	if id.MspID() != "Org1MSP" || string(id.Credentials()) != string(certPEM) {
+		t.Errorf("Unexpected identity %s", id.MspID())
+	}
+
+	digest := sha256.Sum256([]byte("proposal"))
+	sig, err := id.Sign(digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	verifySignature(t, cert, digest[:], sig)
+}
+
+// Test storing, listing and loading wallet identities
+func TestFileWallet(t *testing.T) {
+	w := NewFileWallet(filepath.Join(t.TempDir(), "wallet"))
+	if _, err := w.Get("recorder"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+
+	key := newTestKey(t)
+	cert := newTestCertificate(t, "recorder", key, time.Now().Add(time.Hour))
+	e, err := NewEntry("Org1MSP", cert, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Put("recorder", e); err != nil {
+		t.Fatalf("Failed to store identity: %v", err)
+	}
+
+	labels, err := w.List()
+	if err != nil || len(labels) != 1 || labels[0] != "recorder" {
+		t.Errorf("Expected [recorder], got %v (%v)", labels, err)
+	}
+
+	p, err := New(Config{Type: TypeWallet, MSPID: "Org1MSP", Wallet: WalletConfig{Path: w.dir, Label: "recorder"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	creds, err := p.Credentials(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to load wallet identity: %v", err)
+	}
+	if !creds.Certificate.Equal(cert) || creds.MSPID != "Org1MSP" {
+		t.Errorf("Loaded identity does not match stored one")
+	}
+
+	if err := w.Remove("recorder"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Get("recorder"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected identity to be removed, got %v", err)
+	}
+}